@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/big"
 	"net/http"
 	"net/url"
@@ -59,6 +60,10 @@ var (
 	ErrMissingClientSecret  = errors.New("client secret is required")
 	ErrMissingRedirectURI   = errors.New("redirect URI is required")
 	ErrMissingCode          = errors.New("authorization code is required")
+	ErrNoTokenStore         = errors.New("no token store configured")
+	// ErrTokenNotPersisted is returned, along with the token, when a token was
+	// obtained and set on the client but saving it to the TokenStore failed.
+	ErrTokenNotPersisted = errors.New("token not persisted")
 )
 
 // Token represents an OAuth token from Twitch.
@@ -133,6 +138,14 @@ type AuthClient struct {
 	token      *Token
 	mu         sync.RWMutex
 
+	// Optional persistent storage for the token
+	store    TokenStore
+	storeKey string
+
+	// In-flight refresh shared by concurrent callers of refreshExpiredToken
+	refreshing *refreshCall
+	refreshMu  sync.Mutex
+
 	// Configurable endpoints (for testing). These default to the Twitch constants.
 	tokenEndpoint    string
 	validateEndpoint string
@@ -141,6 +154,9 @@ type AuthClient struct {
 	openIDConfigURL  string
 	userInfoEndpoint string
 	jwksEndpoint     string
+
+	instr  Instrumentation
+	logger *slog.Logger
 }
 
 // NewAuthClient creates a new OAuth client with the given configuration.
@@ -188,6 +204,40 @@ func (c *AuthClient) SetHTTPClient(client *http.Client) {
 	c.httpClient = client
 }
 
+// SetInstrumentation sets the instrumentation that receives a span and a counter
+// for each request to the OAuth and OIDC endpoints.
+func (c *AuthClient) SetInstrumentation(instr Instrumentation) {
+	c.instr = instr
+}
+
+// SetLogger sets the logger for token refreshes and token persistence failures.
+// Tokens and client secrets are redacted from its output.
+func (c *AuthClient) SetLogger(logger *slog.Logger) {
+	c.logger = newLogger(logger)
+}
+
+// do sends an HTTP request, recording it with the client's instrumentation
+// under the given operation name.
+func (c *AuthClient) do(req *http.Request, operation string) (*http.Response, error) {
+	instr := instrumentationOrNop(c.instr)
+	ctx, span := instr.StartSpan(req.Context(), SpanAuthRequest, StringAttr(AttrAuthOperation, operation))
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	span.SetAttributes(IntAttr(AttrStatus, status))
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+	instr.AddCounter(ctx, CounterAuthRequests, 1, StringAttr(AttrAuthOperation, operation), IntAttr(AttrStatus, status))
+
+	return resp, err
+}
+
 // SetToken sets the current token.
 func (c *AuthClient) SetToken(token *Token) {
 	c.mu.Lock()
@@ -202,6 +252,72 @@ func (c *AuthClient) GetToken() *Token {
 	return c.token
 }
 
+// SetTokenStore configures persistent storage for the token. Every token obtained
+// from Twitch (including refreshes) is saved under key, and revoking the current
+// token deletes it. If key is empty, the client ID is used.
+func (c *AuthClient) SetTokenStore(store TokenStore, key string) {
+	if key == "" {
+		key = c.config.ClientID
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store = store
+	c.storeKey = key
+}
+
+// LoadToken loads the token from the configured TokenStore and makes it the current token.
+// Returns ErrTokenNotFound if nothing has been stored yet.
+func (c *AuthClient) LoadToken(ctx context.Context) (*Token, error) {
+	c.mu.RLock()
+	store, key := c.store, c.storeKey
+	c.mu.RUnlock()
+
+	if store == nil {
+		return nil, ErrNoTokenStore
+	}
+
+	token, err := store.Load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetToken(token)
+	return token, nil
+}
+
+// SaveToken persists the current token to the configured TokenStore.
+// Tokens obtained through the AuthClient are saved automatically; use this after SetToken.
+func (c *AuthClient) SaveToken(ctx context.Context) error {
+	c.mu.RLock()
+	store, key, token := c.store, c.storeKey, c.token
+	c.mu.RUnlock()
+
+	if store == nil {
+		return ErrNoTokenStore
+	}
+	if token == nil {
+		return ErrInvalidToken
+	}
+	return store.Save(ctx, key, token)
+}
+
+// persistToken saves token to the configured TokenStore, if any. A failure is
+// wrapped in ErrTokenNotPersisted.
+func (c *AuthClient) persistToken(ctx context.Context, token *Token) error {
+	c.mu.RLock()
+	store, key := c.store, c.storeKey
+	c.mu.RUnlock()
+
+	if store == nil {
+		return nil
+	}
+	if err := store.Save(ctx, key, token); err != nil {
+		loggerOrDiscard(c.logger).WarnContext(ctx, "persisting token failed", "key", key, "error", err)
+		return fmt.Errorf("%w: %w", ErrTokenNotPersisted, err)
+	}
+	return nil
+}
+
 // GetAuthorizationURL returns the URL to redirect users to for authorization.
 func (c *AuthClient) GetAuthorizationURL(responseType string) (string, error) {
 	if c.config.ClientID == "" {
@@ -296,7 +412,7 @@ func (c *AuthClient) GetDeviceCode(ctx context.Context) (*DeviceCodeResponse, er
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "device_code")
 	if err != nil {
 		return nil, fmt.Errorf("executing device code request: %w", err)
 	}
@@ -341,7 +457,7 @@ func (c *AuthClient) PollDeviceToken(ctx context.Context, deviceCode string) (*T
 	}
 
 	token, err := c.requestToken(ctx, data)
-	if err != nil {
+	if err != nil && !errors.Is(err, ErrTokenNotPersisted) {
 		if strings.Contains(err.Error(), "authorization_pending") {
 			return nil, ErrAuthorizationPending
 		}
@@ -351,7 +467,7 @@ func (c *AuthClient) PollDeviceToken(ctx context.Context, deviceCode string) (*T
 		return nil, err
 	}
 
-	return token, nil
+	return token, err
 }
 
 // WaitForDeviceToken polls for the device token until it's available or the context is cancelled.
@@ -382,16 +498,29 @@ func (c *AuthClient) WaitForDeviceToken(ctx context.Context, deviceCode *DeviceC
 			if err == ErrAuthorizationPending {
 				continue
 			}
-			if err != nil {
-				return nil, err
-			}
-			return token, nil
+			return token, err
 		}
 	}
 }
 
 // RefreshToken refreshes an access token using a refresh token.
+// The new token becomes the current token and is persisted if a TokenStore is configured.
+// If persisting fails, the new token is still returned, with an error wrapping
+// ErrTokenNotPersisted, as Twitch may have rotated the refresh token.
 func (c *AuthClient) RefreshToken(ctx context.Context, refreshToken string) (*Token, error) {
+	logger := loggerOrDiscard(c.logger)
+	token, err := c.exchangeRefreshToken(ctx, refreshToken)
+	if err != nil {
+		logger.WarnContext(ctx, "token refresh failed", "error", err)
+		return nil, err
+	}
+	logger.InfoContext(ctx, "refreshed token", "expires_at", token.ExpiresAt)
+	return token, c.storeToken(ctx, token)
+}
+
+// exchangeRefreshToken exchanges a refresh token for a new token without
+// changing the current token. Used by TokenManager to refresh other users' tokens.
+func (c *AuthClient) exchangeRefreshToken(ctx context.Context, refreshToken string) (*Token, error) {
 	if c.config.ClientID == "" {
 		return nil, ErrMissingClientID
 	}
@@ -409,7 +538,7 @@ func (c *AuthClient) RefreshToken(ctx context.Context, refreshToken string) (*To
 		"refresh_token": {refreshToken},
 	}
 
-	token, err := c.requestToken(ctx, data)
+	token, err := c.fetchToken(ctx, data)
 	if err != nil {
 		if strings.Contains(err.Error(), "Invalid refresh token") {
 			return nil, ErrInvalidRefreshToken
@@ -421,6 +550,8 @@ func (c *AuthClient) RefreshToken(ctx context.Context, refreshToken string) (*To
 }
 
 // RefreshCurrentToken refreshes the current token if it has a refresh token.
+// Like RefreshToken, it returns the new token with an ErrTokenNotPersisted error
+// if saving it fails.
 func (c *AuthClient) RefreshCurrentToken(ctx context.Context) (*Token, error) {
 	c.mu.RLock()
 	if c.token == nil || c.token.RefreshToken == "" {
@@ -431,12 +562,54 @@ func (c *AuthClient) RefreshCurrentToken(ctx context.Context) (*Token, error) {
 	c.mu.RUnlock()
 
 	token, err := c.RefreshToken(ctx, refreshToken)
-	if err != nil {
+	if err != nil && !errors.Is(err, ErrTokenNotPersisted) {
 		return nil, err
 	}
 
 	c.SetToken(token)
-	return token, nil
+	return token, err
+}
+
+// refreshCall tracks an in-flight token refresh shared by concurrent callers.
+type refreshCall struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+// refreshExpiredToken refreshes the current token after a request made with
+// staleAccessToken was rejected. If the current token has already been replaced
+// it is returned without refreshing, and concurrent callers share a single refresh.
+func (c *AuthClient) refreshExpiredToken(ctx context.Context, staleAccessToken string) (*Token, error) {
+	c.refreshMu.Lock()
+	if token := c.GetToken(); token != nil && token.AccessToken != staleAccessToken {
+		c.refreshMu.Unlock()
+		return token, nil
+	}
+
+	call := c.refreshing
+	if call == nil {
+		call = &refreshCall{done: make(chan struct{})}
+		c.refreshing = call
+		c.refreshMu.Unlock()
+
+		// Detach from the caller's cancellation so other waiters are not failed by it
+		call.token, call.err = c.RefreshCurrentToken(context.WithoutCancel(ctx))
+
+		c.refreshMu.Lock()
+		c.refreshing = nil
+		c.refreshMu.Unlock()
+		close(call.done)
+	} else {
+		c.refreshMu.Unlock()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-call.done:
+		return call.token, call.err
+	}
 }
 
 // ValidateToken validates an access token.
@@ -447,7 +620,7 @@ func (c *AuthClient) ValidateToken(ctx context.Context, accessToken string) (*Va
 	}
 	req.Header.Set("Authorization", "OAuth "+accessToken)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "validate")
 	if err != nil {
 		return nil, fmt.Errorf("executing validate request: %w", err)
 	}
@@ -508,7 +681,7 @@ func (c *AuthClient) RevokeToken(ctx context.Context, accessToken string) error
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "revoke")
 	if err != nil {
 		return fmt.Errorf("executing revoke request: %w", err)
 	}
@@ -546,20 +719,44 @@ func (c *AuthClient) RevokeCurrentToken(ctx context.Context) error {
 
 	c.mu.Lock()
 	c.token = nil
+	store, key := c.store, c.storeKey
 	c.mu.Unlock()
 
+	if store != nil {
+		if err := store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("deleting stored token: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// requestToken makes a token request to the Twitch token endpoint.
+// requestToken makes a token request to the Twitch token endpoint and stores the result
+// as the current token. If persisting it fails, the token is returned with the error.
 func (c *AuthClient) requestToken(ctx context.Context, data url.Values) (*Token, error) {
+	token, err := c.fetchToken(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return token, c.storeToken(ctx, token)
+}
+
+// storeToken makes token the current token and persists it if a TokenStore is configured.
+// The token stays set on the client even if persisting it fails.
+func (c *AuthClient) storeToken(ctx context.Context, token *Token) error {
+	c.SetToken(token)
+	return c.persistToken(ctx, token)
+}
+
+// fetchToken makes a token request to the Twitch token endpoint.
+func (c *AuthClient) fetchToken(ctx context.Context, data url.Values) (*Token, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenEndpoint, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("creating token request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "token")
 	if err != nil {
 		return nil, fmt.Errorf("executing token request: %w", err)
 	}
@@ -584,7 +781,6 @@ func (c *AuthClient) requestToken(ctx context.Context, data url.Values) (*Token,
 	}
 
 	token.setExpiry()
-	c.SetToken(&token)
 
 	return &token, nil
 }
@@ -631,7 +827,7 @@ func (c *AuthClient) AutoRefresh(ctx context.Context) (cancel func()) {
 
 			if waitDuration <= 0 {
 				// Token is expired or about to expire - refresh now
-				if _, err := c.RefreshCurrentToken(ctx); err != nil {
+				if _, err := c.RefreshCurrentToken(ctx); err != nil && !errors.Is(err, ErrTokenNotPersisted) {
 					select {
 					case <-ctx.Done():
 						return
@@ -646,7 +842,7 @@ func (c *AuthClient) AutoRefresh(ctx context.Context) (cancel func()) {
 			case <-ctx.Done():
 				return
 			case <-time.After(waitDuration):
-				if _, err := c.RefreshCurrentToken(ctx); err != nil {
+				if _, err := c.RefreshCurrentToken(ctx); err != nil && !errors.Is(err, ErrTokenNotPersisted) {
 					select {
 					case <-ctx.Done():
 						return
@@ -744,7 +940,7 @@ func (c *AuthClient) GetOpenIDConfiguration(ctx context.Context) (*OpenIDConfigu
 		return nil, fmt.Errorf("creating OIDC config request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "openid_configuration")
 	if err != nil {
 		return nil, fmt.Errorf("executing OIDC config request: %w", err)
 	}
@@ -841,7 +1037,7 @@ func (c *AuthClient) ExchangeCodeForOIDCToken(ctx context.Context, code string)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "oidc_token")
 	if err != nil {
 		return nil, fmt.Errorf("executing token request: %w", err)
 	}
@@ -866,9 +1062,7 @@ func (c *AuthClient) ExchangeCodeForOIDCToken(ctx context.Context, code string)
 	}
 
 	token.setExpiry()
-	c.SetToken(&token.Token)
-
-	return &token, nil
+	return &token, c.storeToken(ctx, &token.Token)
 }
 
 // GetOIDCUserInfo fetches user information from the OIDC UserInfo endpoint.
@@ -880,7 +1074,7 @@ func (c *AuthClient) GetOIDCUserInfo(ctx context.Context, accessToken string) (*
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "userinfo")
 	if err != nil {
 		return nil, fmt.Errorf("executing userinfo request: %w", err)
 	}
@@ -931,7 +1125,7 @@ func (c *AuthClient) GetJWKS(ctx context.Context) (*JWKS, error) {
 		return nil, fmt.Errorf("creating JWKS request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req, "jwks")
 	if err != nil {
 		return nil, fmt.Errorf("executing JWKS request: %w", err)
 	}
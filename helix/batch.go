@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/url"
 	"sync"
+	"time"
 )
 
 // BatchRequest represents a single request in a batch.
@@ -24,6 +25,11 @@ type BatchOptions struct {
 	MaxConcurrent int
 	// StopOnError stops processing remaining requests on first error
 	StopOnError bool
+	// ItemTimeout limits how long each request may take (0 = no limit)
+	ItemTimeout time.Duration
+	// PaceRateLimit spreads requests over the time until the rate limit resets
+	// when fewer points remain than requests left to start
+	PaceRateLimit bool
 }
 
 // DefaultBatchOptions returns default batch options.
@@ -37,15 +43,32 @@ func DefaultBatchOptions() BatchOptions {
 // Batch executes multiple requests concurrently with configurable parallelism.
 // Results are returned in the same order as the input requests.
 func (c *Client) Batch(ctx context.Context, requests []BatchRequest, opts *BatchOptions) []BatchResult {
+	results := make([]BatchResult, len(requests))
+	var resultsMu sync.Mutex
+
+	c.runBatch(ctx, len(requests), opts, func(ctx context.Context, idx int) error {
+		return c.Do(ctx, requests[idx].Request, requests[idx].Result)
+	}, func(idx int, err error) {
+		resultsMu.Lock()
+		results[idx] = BatchResult{Index: idx, Error: err}
+		resultsMu.Unlock()
+	})
+
+	return results
+}
+
+// runBatch calls run for each index from 0 to n-1 with the concurrency, timeout and
+// pacing from opts, then done with its error. Indexes that are skipped because the
+// context ended or an earlier call failed with StopOnError are passed to done
+// without being run.
+func (c *Client) runBatch(ctx context.Context, n int, opts *BatchOptions, run func(ctx context.Context, idx int) error, done func(idx int, err error)) {
 	if opts == nil {
 		defaultOpts := DefaultBatchOptions()
 		opts = &defaultOpts
 	}
 
-	results := make([]BatchResult, len(requests))
-
-	if len(requests) == 0 {
-		return results
+	if n == 0 {
+		return
 	}
 
 	// Create semaphore for concurrency control
@@ -56,28 +79,23 @@ func (c *Client) Batch(ctx context.Context, requests []BatchRequest, opts *Batch
 
 	var wg sync.WaitGroup
 	var stopMu sync.Mutex
-	var resultsMu sync.Mutex
 	stopped := false
+	isStopped := func() bool {
+		stopMu.Lock()
+		defer stopMu.Unlock()
+		return stopped
+	}
 
-	for i, req := range requests {
+	for i := 0; i < n; i++ {
 		// Check if we should stop
-		if opts.StopOnError {
-			stopMu.Lock()
-			if stopped {
-				stopMu.Unlock()
-				resultsMu.Lock()
-				results[i] = BatchResult{Index: i, Error: context.Canceled}
-				resultsMu.Unlock()
-				continue
-			}
-			stopMu.Unlock()
+		if opts.StopOnError && isStopped() {
+			done(i, context.Canceled)
+			continue
 		}
 
 		// Check context
 		if ctx.Err() != nil {
-			resultsMu.Lock()
-			results[i] = BatchResult{Index: i, Error: ctx.Err()}
-			resultsMu.Unlock()
+			done(i, ctx.Err())
 			continue
 		}
 
@@ -86,36 +104,76 @@ func (c *Client) Batch(ctx context.Context, requests []BatchRequest, opts *Batch
 			select {
 			case sem <- struct{}{}:
 			case <-ctx.Done():
-				resultsMu.Lock()
-				results[i] = BatchResult{Index: i, Error: ctx.Err()}
-				resultsMu.Unlock()
+				done(i, ctx.Err())
+				continue
+			}
+			// An earlier request may have failed while we waited
+			if opts.StopOnError && isStopped() {
+				<-sem
+				done(i, context.Canceled)
+				continue
+			}
+		}
+
+		if opts.PaceRateLimit {
+			if err := c.paceBatch(ctx, n-i); err != nil {
+				if sem != nil {
+					<-sem
+				}
+				done(i, err)
 				continue
 			}
 		}
 
 		wg.Add(1)
-		go func(idx int, batchReq BatchRequest) {
+		go func(idx int) {
 			defer wg.Done()
 			if sem != nil {
 				defer func() { <-sem }()
 			}
 
-			err := c.Do(ctx, batchReq.Request, batchReq.Result)
+			itemCtx := ctx
+			if opts.ItemTimeout > 0 {
+				var cancel context.CancelFunc
+				itemCtx, cancel = context.WithTimeout(ctx, opts.ItemTimeout)
+				defer cancel()
+			}
 
-			resultsMu.Lock()
-			results[idx] = BatchResult{Index: idx, Error: err}
-			resultsMu.Unlock()
+			err := run(itemCtx, idx)
+			done(idx, err)
 
 			if err != nil && opts.StopOnError {
 				stopMu.Lock()
 				stopped = true
 				stopMu.Unlock()
 			}
-		}(i, req)
+		}(i)
 	}
 
 	wg.Wait()
-	return results
+}
+
+// paceBatch waits before starting the next of pending requests when fewer rate
+// limit points remain than requests, spreading the remaining points evenly over the
+// time until the bucket resets.
+func (c *Client) paceBatch(ctx context.Context, pending int) error {
+	info := c.GetRateLimitInfo()
+	wait := time.Until(info.ResetAt)
+	if wait <= 0 || info.Remaining >= pending {
+		return nil
+	}
+	if info.Remaining > 0 {
+		wait /= time.Duration(info.Remaining)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 // BatchGet executes multiple GET requests concurrently.
@@ -164,79 +222,64 @@ func (c *Client) BatchSequential(ctx context.Context, requests []BatchRequest) [
 // Note: The callback is serialized with a mutex to prevent concurrent calls.
 // If the callback panics, the panic is recovered and the goroutine continues.
 func (c *Client) BatchWithCallback(ctx context.Context, requests []BatchRequest, opts *BatchOptions, callback func(BatchResult)) {
-	if opts == nil {
-		defaultOpts := DefaultBatchOptions()
-		opts = &defaultOpts
-	}
-
-	if len(requests) == 0 {
-		return
-	}
-
-	var sem chan struct{}
-	if opts.MaxConcurrent > 0 {
-		sem = make(chan struct{}, opts.MaxConcurrent)
-	}
-
-	var wg sync.WaitGroup
-	var stopMu sync.Mutex
-	stopped := false
 	var callbackMu sync.Mutex
 
-	// safeCallback calls the callback with panic recovery to prevent deadlock
-	safeCallback := func(result BatchResult) {
+	c.runBatch(ctx, len(requests), opts, func(ctx context.Context, idx int) error {
+		return c.Do(ctx, requests[idx].Request, requests[idx].Result)
+	}, func(idx int, err error) {
+		// Recover callback panics to prevent deadlock
 		callbackMu.Lock()
 		defer callbackMu.Unlock()
 		defer func() { _ = recover() }()
-		callback(result)
-	}
-
-	for i, req := range requests {
-		if opts.StopOnError {
-			stopMu.Lock()
-			shouldStop := stopped
-			stopMu.Unlock()
-			if shouldStop {
-				safeCallback(BatchResult{Index: i, Error: context.Canceled})
-				continue
-			}
-		}
-
-		if ctx.Err() != nil {
-			safeCallback(BatchResult{Index: i, Error: ctx.Err()})
-			continue
-		}
+		callback(BatchResult{Index: idx, Error: err})
+	})
+}
 
-		if sem != nil {
-			select {
-			case sem <- struct{}{}:
-			case <-ctx.Done():
-				safeCallback(BatchResult{Index: i, Error: ctx.Err()})
-				continue
-			}
-		}
+// BatchCallResult contains the result of one call in a BatchCall.
+type BatchCallResult[T any] struct {
+	Index int
+	Value T
+	Error error
+}
 
-		wg.Add(1)
-		go func(idx int, batchReq BatchRequest) {
-			defer wg.Done()
-			if sem != nil {
-				defer func() { <-sem }()
-			}
+// BatchCall runs calls concurrently with the options used by Batch and returns their
+// typed results in the same order as the calls. Each call receives a context that
+// carries opts.ItemTimeout, and opts.PaceRateLimit paces calls using client's rate
+// limit state.
+//
+//	results := helix.BatchCall(ctx, client, []func(context.Context) (*helix.Response[helix.User], error){
+//	    func(ctx context.Context) (*helix.Response[helix.User], error) {
+//	        return client.GetUsers(ctx, &helix.GetUsersParams{Logins: []string{"twitchdev"}})
+//	    },
+//	    ...
+//	}, nil)
+func BatchCall[T any](ctx context.Context, client *Client, calls []func(context.Context) (T, error), opts *BatchOptions) []BatchCallResult[T] {
+	results := make([]BatchCallResult[T], len(calls))
+	for i := range results {
+		results[i].Index = i
+	}
 
-			err := c.Do(ctx, batchReq.Request, batchReq.Result)
-			result := BatchResult{Index: idx, Error: err}
+	// Each index is written by exactly one goroutine, so no lock is needed
+	client.runBatch(ctx, len(calls), opts, func(ctx context.Context, idx int) error {
+		value, err := calls[idx](ctx)
+		results[idx].Value = value
+		return err
+	}, func(idx int, err error) {
+		results[idx].Error = err
+	})
 
-			safeCallback(result)
+	return results
+}
 
-			if err != nil && opts.StopOnError {
-				stopMu.Lock()
-				stopped = true
-				stopMu.Unlock()
-			}
-		}(i, req)
+// BatchCallErrors returns all errors from BatchCall results.
+func BatchCallErrors[T any](results []BatchCallResult[T]) []error {
+	var errs []error
+	for _, r := range results {
+		if r.Error != nil {
+			errs = append(errs, r.Error)
+		}
 	}
-
-	wg.Wait()
+	return errs
 }
 
 // HasErrors returns true if any batch result contains an error.
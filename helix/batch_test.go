@@ -662,3 +662,137 @@ func TestBatchWithCallback_SemaphoreContextCancel(t *testing.T) {
 		t.Error("expected at least one callback")
 	}
 }
+
+func TestBatchCall_TypedResultsInOrder(t *testing.T) {
+	calls := make([]func(context.Context) (int, error), 5)
+	for i := range calls {
+		n := i
+		calls[i] = func(ctx context.Context) (int, error) {
+			time.Sleep(time.Duration(5-n) * time.Millisecond)
+			if n == 3 {
+				return 0, errors.New("call failed")
+			}
+			return n * n, nil
+		}
+	}
+
+	results := BatchCall(context.Background(), &Client{}, calls, &BatchOptions{MaxConcurrent: 2})
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(results))
+	}
+	for i, r := range results {
+		if r.Index != i {
+			t.Errorf("expected index %d, got %d", i, r.Index)
+		}
+		if i == 3 {
+			if r.Error == nil {
+				t.Error("expected error for call 3")
+			}
+			continue
+		}
+		if r.Error != nil || r.Value != i*i {
+			t.Errorf("call %d: expected %d, got %d (%v)", i, i*i, r.Value, r.Error)
+		}
+	}
+	if errs := BatchCallErrors(results); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+}
+
+func TestBatchCall_WithClientMethods(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Response[User]{Data: []User{{Login: r.URL.Query().Get("login")}}})
+	})
+	defer server.Close()
+
+	var calls []func(context.Context) (*Response[User], error)
+	for _, login := range []string{"a", "b", "c"} {
+		login := login
+		calls = append(calls, func(ctx context.Context) (*Response[User], error) {
+			return client.GetUsers(ctx, &GetUsersParams{Logins: []string{login}})
+		})
+	}
+
+	results := BatchCall(context.Background(), client, calls, nil)
+	for i, want := range []string{"a", "b", "c"} {
+		if results[i].Error != nil || results[i].Value.Data[0].Login != want {
+			t.Errorf("result %d: expected %s, got %+v (%v)", i, want, results[i].Value, results[i].Error)
+		}
+	}
+}
+
+func TestBatchCall_ItemTimeout(t *testing.T) {
+	calls := []func(context.Context) (string, error){
+		func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+		func(ctx context.Context) (string, error) {
+			return "fast", nil
+		},
+	}
+
+	results := BatchCall(context.Background(), &Client{}, calls, &BatchOptions{ItemTimeout: 20 * time.Millisecond})
+	if !errors.Is(results[0].Error, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", results[0].Error)
+	}
+	if results[1].Error != nil || results[1].Value != "fast" {
+		t.Errorf("expected fast call to succeed, got %q (%v)", results[1].Value, results[1].Error)
+	}
+}
+
+func TestBatchCall_StopOnError(t *testing.T) {
+	var ran int32
+	calls := make([]func(context.Context) (struct{}, error), 5)
+	for i := range calls {
+		calls[i] = func(ctx context.Context) (struct{}, error) {
+			atomic.AddInt32(&ran, 1)
+			return struct{}{}, errors.New("fail")
+		}
+	}
+
+	results := BatchCall(context.Background(), &Client{}, calls, &BatchOptions{MaxConcurrent: 1, StopOnError: true})
+	if atomic.LoadInt32(&ran) != 1 {
+		t.Errorf("expected 1 call to run, got %d", ran)
+	}
+	for _, r := range results[1:] {
+		if r.Error != context.Canceled {
+			t.Errorf("expected skipped call to be canceled, got %v", r.Error)
+		}
+	}
+}
+
+func TestBatchCall_PaceRateLimit(t *testing.T) {
+	client := &Client{rateLimitRemaining: 1, rateLimitReset: time.Now().Add(100 * time.Millisecond)}
+	calls := make([]func(context.Context) (int, error), 3)
+	for i := range calls {
+		calls[i] = func(ctx context.Context) (int, error) { return 0, nil }
+	}
+
+	start := time.Now()
+	BatchCall(context.Background(), client, calls, &BatchOptions{PaceRateLimit: true})
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("expected calls to wait for the rate limit reset, took %v", elapsed)
+	}
+
+	client.rateLimitRemaining = 100
+	client.rateLimitReset = time.Now().Add(time.Minute)
+	start = time.Now()
+	BatchCall(context.Background(), client, calls, &BatchOptions{PaceRateLimit: true})
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected no pacing with points to spare, took %v", elapsed)
+	}
+}
+
+func TestBatchCall_PaceRateLimitContextCanceled(t *testing.T) {
+	client := &Client{rateLimitRemaining: 0, rateLimitReset: time.Now().Add(time.Minute)}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	results := BatchCall(ctx, client, []func(context.Context) (int, error){
+		func(ctx context.Context) (int, error) { return 1, nil },
+	}, &BatchOptions{PaceRateLimit: true})
+	if !errors.Is(results[0].Error, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded while pacing, got %v", results[0].Error)
+	}
+}
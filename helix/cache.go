@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"net/url"
 	"sync"
 	"time"
 )
@@ -20,6 +22,19 @@ type Cache interface {
 	Clear(ctx context.Context)
 }
 
+// StaleCache is a Cache that can retain entries past their TTL, allowing the Client
+// to serve stale responses while revalidating or when the API fails.
+// MemoryCache implements StaleCache.
+type StaleCache interface {
+	Cache
+	// SetWithStale stores a response that expires after ttl and is retained
+	// for stale reads for a further stale duration.
+	SetWithStale(ctx context.Context, key string, value []byte, ttl, stale time.Duration)
+	// GetStale retrieves a cached response and the time it expires or expired,
+	// including entries that have expired but are still retained. Returns nil if not found.
+	GetStale(ctx context.Context, key string) ([]byte, time.Time)
+}
+
 // MemoryCache is an in-memory cache implementation.
 type MemoryCache struct {
 	mu      sync.RWMutex
@@ -28,8 +43,9 @@ type MemoryCache struct {
 }
 
 type cacheEntry struct {
-	value     []byte
-	expiresAt time.Time
+	value      []byte
+	expiresAt  time.Time
+	staleUntil time.Time // Retained for stale reads until this time
 }
 
 // NewMemoryCache creates a new in-memory cache.
@@ -51,8 +67,10 @@ func (c *MemoryCache) Get(ctx context.Context, key string) []byte {
 		return nil
 	}
 
-	if time.Now().After(entry.expiresAt) {
-		c.Delete(ctx, key)
+	if now := time.Now(); now.After(entry.expiresAt) {
+		if now.After(entry.staleUntil) {
+			c.Delete(ctx, key)
+		}
 		return nil
 	}
 
@@ -65,6 +83,38 @@ func (c *MemoryCache) Get(ctx context.Context, key string) []byte {
 // Set stores a response in the cache.
 // The value is copied to prevent external mutations from affecting cached data.
 func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	c.set(key, value, ttl, 0)
+}
+
+// SetWithStale stores a response that expires after ttl and is kept for GetStale
+// for a further stale duration.
+func (c *MemoryCache) SetWithStale(ctx context.Context, key string, value []byte, ttl, stale time.Duration) {
+	c.set(key, value, ttl, stale)
+}
+
+// GetStale retrieves a cached response and its expiry time, including an expired
+// entry that is still within its stale window.
+// The returned byte slice is a copy to prevent callers from mutating cached data.
+func (c *MemoryCache) GetStale(ctx context.Context, key string) ([]byte, time.Time) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, time.Time{}
+	}
+
+	if time.Now().After(entry.staleUntil) {
+		c.Delete(ctx, key)
+		return nil, time.Time{}
+	}
+
+	result := make([]byte, len(entry.value))
+	copy(result, entry.value)
+	return result, entry.expiresAt
+}
+
+func (c *MemoryCache) set(key string, value []byte, ttl, stale time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -81,9 +131,11 @@ func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl tim
 	valueCopy := make([]byte, len(value))
 	copy(valueCopy, value)
 
+	expiresAt := time.Now().Add(ttl)
 	c.entries[key] = &cacheEntry{
-		value:     valueCopy,
-		expiresAt: time.Now().Add(ttl),
+		value:      valueCopy,
+		expiresAt:  expiresAt,
+		staleUntil: expiresAt.Add(stale),
 	}
 }
 
@@ -101,12 +153,12 @@ func (c *MemoryCache) Clear(ctx context.Context) {
 	c.mu.Unlock()
 }
 
-// evictExpired removes all expired entries (must be called with lock held).
+// evictExpired removes all expired entries past their stale window (must be called with lock held).
 // Note: Deleting from a map during iteration is safe in Go when done in the same goroutine.
 func (c *MemoryCache) evictExpired() {
 	now := time.Now()
 	for key, entry := range c.entries {
-		if now.After(entry.expiresAt) {
+		if now.After(entry.staleUntil) {
 			delete(c.entries, key)
 		}
 	}
@@ -177,6 +229,39 @@ func WithCache(cache Cache, ttl time.Duration) Option {
 	}
 }
 
+// WithEndpointCacheTTL sets the cache TTL for a single endpoint, overriding the TTL
+// passed to WithCache. For example, game metadata can be cached for hours while
+// streams are cached for seconds:
+//
+//	helix.WithEndpointCacheTTL("/games", 6*time.Hour)
+//	helix.WithEndpointCacheTTL("/streams", 15*time.Second)
+//
+// A TTL of zero or less disables caching for the endpoint.
+func WithEndpointCacheTTL(endpoint string, ttl time.Duration) Option {
+	return func(c *Client) {
+		if c.cacheTTLs == nil {
+			c.cacheTTLs = make(map[string]time.Duration)
+		}
+		c.cacheTTLs[endpoint] = ttl
+	}
+}
+
+// WithStaleWhileRevalidate makes Do serve a cached response for up to window after it
+// expires, refreshing it in the background. Requires a StaleCache such as MemoryCache.
+func WithStaleWhileRevalidate(window time.Duration) Option {
+	return func(c *Client) {
+		c.staleWhileRevalidate = window
+	}
+}
+
+// WithStaleIfError makes Do serve a cached response for up to window after it expires
+// when the API responds with a 5xx error. Requires a StaleCache such as MemoryCache.
+func WithStaleIfError(window time.Duration) Option {
+	return func(c *Client) {
+		c.staleIfError = window
+	}
+}
+
 // WithCacheEnabled enables or disables caching.
 func WithCacheEnabled(enabled bool) Option {
 	return func(c *Client) {
@@ -189,6 +274,9 @@ func (c *Client) ClearCache(ctx context.Context) {
 	if c.cache != nil {
 		c.cache.Clear(ctx)
 	}
+	c.cacheIndexMu.Lock()
+	c.cacheIndex = nil
+	c.cacheIndexMu.Unlock()
 }
 
 // InvalidateCache removes a specific cached response.
@@ -210,6 +298,90 @@ func (c *Client) InvalidateCacheWithContext(ctx context.Context, endpoint, query
 	}
 }
 
+// cacheTTLFor returns the cache TTL for an endpoint.
+func (c *Client) cacheTTLFor(endpoint string) time.Duration {
+	if ttl, ok := c.cacheTTLs[endpoint]; ok {
+		return ttl
+	}
+	return c.cacheTTL
+}
+
+// staleCache returns the client's cache as a StaleCache if stale serving is enabled.
+func (c *Client) staleCache() (StaleCache, bool) {
+	if c.staleWhileRevalidate <= 0 && c.staleIfError <= 0 {
+		return nil, false
+	}
+	sc, ok := c.cache.(StaleCache)
+	return sc, ok
+}
+
+// getCached returns the cached response for key and how long ago it expired.
+// A zero or negative age means the response is fresh.
+func (c *Client) getCached(ctx context.Context, key string) ([]byte, time.Duration) {
+	if sc, ok := c.staleCache(); ok {
+		value, expiresAt := sc.GetStale(ctx, key)
+		if value == nil {
+			return nil, 0
+		}
+		return value, time.Since(expiresAt)
+	}
+	return c.cache.Get(ctx, key), 0
+}
+
+// setCached stores a successful response for req, retaining it for stale reads if enabled.
+func (c *Client) setCached(ctx context.Context, req *Request, body []byte) {
+	ttl := c.cacheTTLFor(req.Endpoint)
+	if ttl <= 0 {
+		return
+	}
+	key := c.cacheKey(ctx, req.Endpoint, req.Query.Encode())
+	if sc, ok := c.staleCache(); ok {
+		stale := max(c.staleWhileRevalidate, c.staleIfError)
+		c.indexCached(ctx, req, key, time.Now().Add(ttl+stale))
+		sc.SetWithStale(ctx, key, body, ttl, stale)
+		return
+	}
+	c.indexCached(ctx, req, key, time.Now().Add(ttl))
+	c.cache.Set(ctx, key, body, ttl)
+}
+
+// revalidate refreshes the cached response for req in the background.
+// Only one refresh per key runs at a time.
+func (c *Client) revalidate(ctx context.Context, key string, req *Request) {
+	c.revalidateMu.Lock()
+	if c.revalidating[key] {
+		c.revalidateMu.Unlock()
+		return
+	}
+	if c.revalidating == nil {
+		c.revalidating = make(map[string]bool)
+	}
+	c.revalidating[key] = true
+	c.revalidateMu.Unlock()
+
+	refresh := *req
+	refresh.Query = make(url.Values, len(req.Query))
+	for k, v := range req.Query {
+		refresh.Query[k] = append([]string(nil), v...)
+	}
+
+	go func() {
+		defer func() {
+			c.revalidateMu.Lock()
+			delete(c.revalidating, key)
+			c.revalidateMu.Unlock()
+		}()
+		// The response is stored in the cache by doOnceWithResponse
+		_, _ = c.execute(context.WithoutCancel(ctx), &refresh, nil)
+	}()
+}
+
+// isServerError reports whether err is an API error with a 5xx status.
+func isServerError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
+
 // NoCacheContext returns a context that bypasses the cache for a single request.
 func NoCacheContext(ctx context.Context) context.Context {
 	return context.WithValue(ctx, noCacheKey{}, true)
@@ -0,0 +1,193 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// cacheInvalidations maps endpoints whose POST, PATCH, PUT or DELETE requests change
+// a broadcaster's data to the cached GET endpoints that data is read from.
+var cacheInvalidations = map[string][]string{
+	// Channels
+	"/channels":                     {"/channels"},
+	"/channels/vips":                {"/channels/vips"},
+	"/channels/guest_star_settings": {"/channels/guest_star_settings"},
+
+	// Chat
+	"/chat/settings": {"/chat/settings"},
+
+	// Moderation (adding a moderator removes their VIP status)
+	"/moderation/moderators":       {"/moderation/moderators", "/channels/vips"},
+	"/moderation/bans":             {"/moderation/banned"},
+	"/moderation/blocked_terms":    {"/moderation/blocked_terms"},
+	"/moderation/shield_mode":      {"/moderation/shield_mode"},
+	"/moderation/automod/settings": {"/moderation/automod/settings"},
+	"/moderation/unban_requests":   {"/moderation/unban_requests"},
+
+	// Channel points
+	"/channel_points/custom_rewards":             {"/channel_points/custom_rewards"},
+	"/channel_points/custom_rewards/redemptions": {"/channel_points/custom_rewards/redemptions"},
+
+	// Polls and predictions
+	"/polls":       {"/polls"},
+	"/predictions": {"/predictions"},
+
+	// Schedule
+	"/schedule/settings": {"/schedule"},
+	"/schedule/segment":  {"/schedule"},
+}
+
+// invalidatedEndpoints is the set of GET endpoints that appear in cacheInvalidations.
+var invalidatedEndpoints = make(map[string]bool)
+
+func init() {
+	for _, targets := range cacheInvalidations {
+		for _, endpoint := range targets {
+			invalidatedEndpoints[endpoint] = true
+		}
+	}
+}
+
+// maxCacheIndexSize bounds the number of endpoint and broadcaster pairs tracked for invalidation.
+const maxCacheIndexSize = 10000
+
+// WithCacheInvalidation configures whether successful POST, PATCH, PUT and DELETE
+// requests remove related cached GET responses for the same broadcaster (default: true).
+// For example, ModifyChannelInformation invalidates cached GetChannelInformation
+// responses and AddChannelVIP invalidates cached GetVIPs responses.
+//
+// Invalidation only covers responses this client cached, as the index of cached
+// responses is kept in memory. Entries written by other processes sharing a
+// RedisCache, or left in a FileCache from before a restart, are not invalidated.
+func WithCacheInvalidation(enabled bool) Option {
+	return func(c *Client) {
+		c.cacheInvalidation = enabled
+	}
+}
+
+// InvalidateBroadcasterCache removes cached GET responses for endpoint that were
+// requested with the given broadcaster ID, for every token.
+// Only endpoints that mutating requests can invalidate are tracked.
+func (c *Client) InvalidateBroadcasterCache(ctx context.Context, endpoint, broadcasterID string) {
+	if c.cache == nil {
+		return
+	}
+
+	indexKey := endpoint + "|" + broadcasterID
+	c.cacheIndexMu.Lock()
+	keys := c.cacheIndex[indexKey]
+	delete(c.cacheIndex, indexKey)
+	c.cacheIndexMu.Unlock()
+
+	for key := range keys {
+		c.cache.Delete(ctx, key)
+	}
+}
+
+// indexCached records that key holds a response for req's broadcaster IDs until
+// the given time. If the index is full, the responses of the evicted pair are
+// removed from the cache so they can't outlive a mutation unnoticed.
+func (c *Client) indexCached(ctx context.Context, req *Request, key string, until time.Time) {
+	if !c.cacheInvalidation || !invalidatedEndpoints[req.Endpoint] {
+		return
+	}
+
+	var evicted []string
+	c.cacheIndexMu.Lock()
+	if c.cacheIndex == nil {
+		c.cacheIndex = make(map[string]map[string]time.Time)
+	}
+	for _, id := range req.Query["broadcaster_id"] {
+		indexKey := req.Endpoint + "|" + id
+		if c.cacheIndex[indexKey] == nil {
+			if len(c.cacheIndex) >= maxCacheIndexSize {
+				evicted = append(evicted, c.evictCacheIndex(time.Now())...)
+			}
+			c.cacheIndex[indexKey] = make(map[string]time.Time)
+		}
+		c.cacheIndex[indexKey][key] = until
+	}
+	c.cacheIndexMu.Unlock()
+
+	for _, key := range evicted {
+		c.cache.Delete(ctx, key)
+	}
+}
+
+// evictCacheIndex removes cache keys that have left the cache and, if the index
+// is still full, the pair whose responses leave the cache soonest, returning
+// that pair's cache keys (must be called with lock held).
+func (c *Client) evictCacheIndex(now time.Time) []string {
+	var oldestKey string
+	var oldestTime time.Time
+	for indexKey, keys := range c.cacheIndex {
+		var latest time.Time
+		for key, until := range keys {
+			if now.After(until) {
+				delete(keys, key)
+			} else if until.After(latest) {
+				latest = until
+			}
+		}
+		if len(keys) == 0 {
+			delete(c.cacheIndex, indexKey)
+			continue
+		}
+		if oldestKey == "" || latest.Before(oldestTime) {
+			oldestKey = indexKey
+			oldestTime = latest
+		}
+	}
+
+	if len(c.cacheIndex) < maxCacheIndexSize || oldestKey == "" {
+		return nil
+	}
+	evicted := make([]string, 0, len(c.cacheIndex[oldestKey]))
+	for key := range c.cacheIndex[oldestKey] {
+		evicted = append(evicted, key)
+	}
+	delete(c.cacheIndex, oldestKey)
+	return evicted
+}
+
+// invalidateRelated removes cached responses made stale by a successful mutating request.
+func (c *Client) invalidateRelated(ctx context.Context, req *Request) {
+	if !c.cacheInvalidation || c.cache == nil || req.Method == http.MethodGet {
+		return
+	}
+	targets, ok := cacheInvalidations[req.Endpoint]
+	if !ok {
+		return
+	}
+
+	for _, id := range requestBroadcasterIDs(req) {
+		for _, endpoint := range targets {
+			c.InvalidateBroadcasterCache(ctx, endpoint, id)
+		}
+	}
+}
+
+// requestBroadcasterIDs returns the broadcaster IDs a request applies to, taken from
+// the broadcaster_id query parameter or, failing that, the request body.
+func requestBroadcasterIDs(req *Request) []string {
+	if ids := req.Query["broadcaster_id"]; len(ids) > 0 {
+		return ids
+	}
+	if req.Body == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(req.Body)
+	if err != nil {
+		return nil
+	}
+	var body struct {
+		BroadcasterID string `json:"broadcaster_id"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || body.BroadcasterID == "" {
+		return nil
+	}
+	return []string{body.BroadcasterID}
+}
@@ -0,0 +1,200 @@
+package helix
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_CacheInvalidation_ByBroadcaster(t *testing.T) {
+	var gets int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+		}
+		_, _ = w.Write([]byte(`{"data":[{}]}`))
+	}, WithCache(NewMemoryCache(100), time.Minute))
+	defer server.Close()
+	ctx := context.Background()
+
+	_, _ = client.GetChannelInformation(ctx, &GetChannelInformationParams{BroadcasterIDs: []string{"1"}})
+	_, _ = client.GetChannelInformation(ctx, &GetChannelInformationParams{BroadcasterIDs: []string{"2"}})
+	_, _ = client.GetChatSettings(ctx, "1", "")
+	if gets != 3 {
+		t.Fatalf("expected 3 GETs, got %d", gets)
+	}
+
+	if err := client.ModifyChannelInformation(ctx, &ModifyChannelInformationParams{BroadcasterID: "1", Title: "new"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, _ = client.GetChannelInformation(ctx, &GetChannelInformationParams{BroadcasterIDs: []string{"1"}})
+	if gets != 4 {
+		t.Errorf("expected broadcaster 1 channel info to be refetched, got %d GETs", gets)
+	}
+	_, _ = client.GetChannelInformation(ctx, &GetChannelInformationParams{BroadcasterIDs: []string{"2"}})
+	_, _ = client.GetChatSettings(ctx, "1", "")
+	if gets != 4 {
+		t.Errorf("expected other entries to stay cached, got %d GETs", gets)
+	}
+}
+
+func TestClient_CacheInvalidation_RelatedEndpoints(t *testing.T) {
+	var gets int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+		}
+		_, _ = w.Write([]byte(`{"data":[{}]}`))
+	}, WithCache(NewMemoryCache(100), time.Minute))
+	defer server.Close()
+	ctx := context.Background()
+
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "1"})
+	_ = client.AddChannelModerator(ctx, "1", "2")
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "1"})
+	if gets != 2 {
+		t.Errorf("expected adding a moderator to invalidate VIPs, got %d GETs", gets)
+	}
+
+	// Broadcaster ID taken from the request body
+	_, _ = client.GetPolls(ctx, &GetPollsParams{BroadcasterID: "1"})
+	_, _ = client.CreatePoll(ctx, &CreatePollParams{BroadcasterID: "1", Title: "poll"})
+	_, _ = client.GetPolls(ctx, &GetPollsParams{BroadcasterID: "1"})
+	if gets != 4 {
+		t.Errorf("expected CreatePoll to invalidate polls, got %d GETs", gets)
+	}
+}
+
+func TestClient_CacheInvalidation_FailedMutation(t *testing.T) {
+	var gets int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Bad Request","status":400,"message":"bad"}`))
+			return
+		}
+		atomic.AddInt32(&gets, 1)
+		_, _ = w.Write([]byte(`{"data":[{}]}`))
+	}, WithCache(NewMemoryCache(100), time.Minute))
+	defer server.Close()
+	ctx := context.Background()
+
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "1"})
+	if err := client.AddChannelVIP(ctx, "1", "2"); err == nil {
+		t.Fatal("expected error")
+	}
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "1"})
+	if gets != 1 {
+		t.Errorf("expected failed mutation not to invalidate, got %d GETs", gets)
+	}
+}
+
+func TestClient_CacheInvalidation_Disabled(t *testing.T) {
+	var gets int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+		}
+		_, _ = w.Write([]byte(`{"data":[{}]}`))
+	}, WithCache(NewMemoryCache(100), time.Minute), WithCacheInvalidation(false))
+	defer server.Close()
+	ctx := context.Background()
+
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "1"})
+	_ = client.AddChannelVIP(ctx, "1", "2")
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "1"})
+	if gets != 1 {
+		t.Errorf("expected no invalidation, got %d GETs", gets)
+	}
+}
+
+func TestClient_CacheInvalidation_IndexBound(t *testing.T) {
+	var gets int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+		}
+		_, _ = w.Write([]byte(`{"data":[{}]}`))
+	}, WithCache(NewMemoryCache(100), time.Minute))
+	defer server.Close()
+	ctx := context.Background()
+
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "1"})
+
+	// Fill the index with pairs that stay cached longer than broadcaster 1's
+	until := time.Now().Add(time.Hour)
+	for i := 0; i < maxCacheIndexSize; i++ {
+		id := "filler-" + strconv.Itoa(i)
+		client.indexCached(ctx, &Request{Endpoint: "/channels/vips", Query: url.Values{"broadcaster_id": {id}}}, id, until)
+	}
+	if n := len(client.cacheIndex); n != maxCacheIndexSize {
+		t.Errorf("expected the index to stay at %d pairs, got %d", maxCacheIndexSize, n)
+	}
+	if _, ok := client.cacheIndex["/channels/vips|filler-1"]; !ok {
+		t.Error("expected later pairs to be kept")
+	}
+
+	// Broadcaster 1's pair was evicted along with its cached response
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "1"})
+	if gets != 2 {
+		t.Errorf("expected the evicted response to be refetched, got %d GETs", gets)
+	}
+
+	// Pairs indexed after the bound was reached are still invalidated
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "2"})
+	_ = client.AddChannelVIP(ctx, "2", "3")
+	_, _ = client.GetVIPs(ctx, &GetVIPsParams{BroadcasterID: "2"})
+	if gets != 4 {
+		t.Errorf("expected AddChannelVIP to invalidate VIPs, got %d GETs", gets)
+	}
+}
+
+func TestClient_InvalidateBroadcasterCache(t *testing.T) {
+	var gets int32
+	ctx := context.Background()
+
+	// Entries cached for different users are all removed
+	m := NewTokenManager(nil)
+	_ = m.SetUserToken(ctx, "a", &Token{AccessToken: "token-a"})
+	_ = m.SetUserToken(ctx, "b", &Token{AccessToken: "token-b"})
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+		}
+		_, _ = w.Write([]byte(`{"data":[{}]}`))
+	}, WithCache(NewMemoryCache(100), time.Minute), WithTokenProvider(m))
+	defer server.Close()
+	for _, user := range []string{"a", "b"} {
+		_, _ = client.GetChatSettings(WithUserContext(ctx, user), "1", "")
+	}
+
+	client.InvalidateBroadcasterCache(ctx, "/chat/settings", "1")
+	for _, user := range []string{"a", "b"} {
+		_, _ = client.GetChatSettings(WithUserContext(ctx, user), "1", "")
+	}
+	if gets != 4 {
+		t.Errorf("expected both users' entries to be invalidated, got %d GETs", gets)
+	}
+}
+
+func TestRequestBroadcasterIDs(t *testing.T) {
+	req := &Request{Query: map[string][]string{"broadcaster_id": {"1", "2"}}}
+	if ids := requestBroadcasterIDs(req); len(ids) != 2 {
+		t.Errorf("expected query IDs, got %v", ids)
+	}
+
+	req = &Request{Body: &CreatePollParams{BroadcasterID: "3"}}
+	if ids := requestBroadcasterIDs(req); len(ids) != 1 || ids[0] != "3" {
+		t.Errorf("expected body ID, got %v", ids)
+	}
+
+	req = &Request{Body: []string{"x"}}
+	if ids := requestBroadcasterIDs(req); ids != nil {
+		t.Errorf("expected no IDs, got %v", ids)
+	}
+}
@@ -2,6 +2,9 @@ package helix
 
 import (
 	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -378,3 +381,144 @@ func TestMemoryCache_MutationProtection(t *testing.T) {
 		}
 	})
 }
+
+func TestMemoryCache_Stale(t *testing.T) {
+	cache := NewMemoryCache(0)
+	ctx := context.Background()
+
+	cache.SetWithStale(ctx, "key", []byte("value"), time.Millisecond, time.Minute)
+	time.Sleep(5 * time.Millisecond)
+
+	if cache.Get(ctx, "key") != nil {
+		t.Error("expected Get to miss an expired entry")
+	}
+	value, expiresAt := cache.GetStale(ctx, "key")
+	if string(value) != "value" {
+		t.Errorf("expected stale value, got %q", value)
+	}
+	if !time.Now().After(expiresAt) {
+		t.Error("expected expiry time in the past")
+	}
+
+	// Entries set without a stale window are gone once expired
+	cache.Set(ctx, "plain", []byte("value"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if value, _ := cache.GetStale(ctx, "plain"); value != nil {
+		t.Errorf("expected no stale value, got %q", value)
+	}
+	if cache.Size() != 1 {
+		t.Errorf("expected expired entry to be removed, got size %d", cache.Size())
+	}
+}
+
+func TestClient_EndpointCacheTTL(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithCache(NewMemoryCache(100), time.Minute), WithEndpointCacheTTL("/streams", 0))
+	defer server.Close()
+	ctx := context.Background()
+
+	_, _ = client.GetUsers(ctx, nil)
+	_, _ = client.GetUsers(ctx, nil)
+	if requests != 1 {
+		t.Errorf("expected users to be cached, got %d requests", requests)
+	}
+
+	_, _ = client.GetStreams(ctx, nil)
+	_, _ = client.GetStreams(ctx, nil)
+	if requests != 3 {
+		t.Errorf("expected streams not to be cached, got %d requests", requests)
+	}
+
+	if client.cacheTTLFor("/games") != time.Minute || client.cacheTTLFor("/streams") != 0 {
+		t.Error("unexpected TTL lookup")
+	}
+}
+
+func TestClient_StaleWhileRevalidate(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"data":[{"id":"` + strconv.Itoa(int(n)) + `"}]}`))
+	},
+		WithCache(NewMemoryCache(100), time.Minute),
+		WithEndpointCacheTTL("/users", 20*time.Millisecond),
+		WithStaleWhileRevalidate(time.Minute),
+	)
+	defer server.Close()
+	ctx := context.Background()
+
+	_, _ = client.GetUsers(ctx, nil)
+	time.Sleep(30 * time.Millisecond)
+
+	resp, err := client.GetUsers(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Data[0].ID != "1" {
+		t.Errorf("expected stale response, got %s", resp.Data[0].ID)
+	}
+
+	// Wait for the background refresh
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		client.revalidateMu.Lock()
+		pending := len(client.revalidating)
+		client.revalidateMu.Unlock()
+		if pending == 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	resp, _ = client.GetUsers(ctx, nil)
+	if resp.Data[0].ID != "2" {
+		t.Errorf("expected refreshed response, got %s", resp.Data[0].ID)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestClient_StaleIfError(t *testing.T) {
+	var requests int32
+	status := int32(http.StatusOK)
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if code := int(atomic.LoadInt32(&status)); code != http.StatusOK {
+			w.WriteHeader(code)
+			_, _ = w.Write([]byte(`{"error":"Internal Server Error","status":500,"message":"boom"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"` + strconv.Itoa(int(n)) + `"}]}`))
+	},
+		WithCache(NewMemoryCache(100), time.Minute),
+		WithEndpointCacheTTL("/users", time.Millisecond),
+		WithStaleIfError(time.Minute),
+	)
+	defer server.Close()
+	ctx := context.Background()
+
+	_, _ = client.GetUsers(ctx, nil)
+	time.Sleep(5 * time.Millisecond)
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+
+	resp, err := client.GetUsers(ctx, nil)
+	if err != nil {
+		t.Fatalf("expected stale response on 5xx, got %v", err)
+	}
+	if resp.Data[0].ID != "1" {
+		t.Errorf("expected stale response, got %s", resp.Data[0].ID)
+	}
+	if requests != 2 {
+		t.Errorf("expected the API to be tried first, got %d requests", requests)
+	}
+
+	// Client errors are not masked
+	atomic.StoreInt32(&status, http.StatusBadRequest)
+	if _, err := client.GetUsers(ctx, nil); err == nil {
+		t.Error("expected 4xx error to be returned")
+	}
+}
@@ -231,17 +231,9 @@ type UserChatColor struct {
 }
 
 // GetUserChatColor gets the chat color for one or more users.
+// More than 100 user IDs are split across concurrent requests.
 func (c *Client) GetUserChatColor(ctx context.Context, userIDs []string) (*Response[UserChatColor], error) {
-	q := url.Values{}
-	for _, id := range userIDs {
-		q.Add("user_id", id)
-	}
-
-	var resp Response[UserChatColor]
-	if err := c.get(ctx, "/chat/color", q, &resp); err != nil {
-		return nil, err
-	}
-	return &resp, nil
+	return getChunked[UserChatColor](ctx, c, "/chat/color", url.Values{}, queryValues("user_id", userIDs), maxIDsPerRequest)
 }
 
 // UpdateUserChatColor updates the authenticated user's chat color.
@@ -9,12 +9,20 @@ import (
 // ChatBotClient provides a high-level interface for Twitch chat bots.
 // It wraps IRCClient with convenience methods and automatic token handling.
 type ChatBotClient struct {
+	chatHandlers
+
 	irc        *IRCClient
 	authClient *AuthClient
 	nick       string
 	ircURL     string // custom IRC URL for testing
+	ircOpts    []IRCOption
+}
+
+// chatHandlers holds the event handlers shared by ChatBotClient and IRCPool, and
+// dispatches IRC events to them.
+type chatHandlers struct {
+	sender ChatSender // Used to reply to commands
 
-	// Event handlers
 	onMessage    func(*ChatMessage)
 	onSub        func(*UserNotice)
 	onResub      func(*UserNotice)
@@ -30,6 +38,7 @@ type ChatBotClient struct {
 	onConnect    func()
 	onDisconnect func()
 	onError      func(error)
+	router       *CommandRouter
 
 	mu sync.RWMutex
 }
@@ -44,6 +53,7 @@ func NewChatBotClient(nick string, authClient *AuthClient, opts ...ChatBotOption
 		authClient: authClient,
 		nick:       nick,
 	}
+	c.sender = c
 
 	for _, opt := range opts {
 		opt(c)
@@ -59,109 +69,126 @@ func WithChatBotURL(url string) ChatBotOption {
 	}
 }
 
+// WithChatBotIRCOptions passes options such as WithIRCRateLimit or
+// WithIRCMessageSplitting to the underlying IRCClient. Handler options are
+// ignored, use the ChatBotClient's On* methods instead.
+func WithChatBotIRCOptions(opts ...IRCOption) ChatBotOption {
+	return func(c *ChatBotClient) {
+		c.ircOpts = append(c.ircOpts, opts...)
+	}
+}
+
 // OnMessage sets the handler for all chat messages.
-func (c *ChatBotClient) OnMessage(fn func(*ChatMessage)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onMessage = fn
+func (h *chatHandlers) OnMessage(fn func(*ChatMessage)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onMessage = fn
 }
 
 // OnSub sets the handler for new subscription events.
-func (c *ChatBotClient) OnSub(fn func(*UserNotice)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onSub = fn
+func (h *chatHandlers) OnSub(fn func(*UserNotice)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onSub = fn
 }
 
 // OnResub sets the handler for resubscription events.
-func (c *ChatBotClient) OnResub(fn func(*UserNotice)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onResub = fn
+func (h *chatHandlers) OnResub(fn func(*UserNotice)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onResub = fn
 }
 
 // OnSubGift sets the handler for gift subscription events.
-func (c *ChatBotClient) OnSubGift(fn func(*UserNotice)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onSubGift = fn
+func (h *chatHandlers) OnSubGift(fn func(*UserNotice)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onSubGift = fn
 }
 
 // OnRaid sets the handler for raid events.
-func (c *ChatBotClient) OnRaid(fn func(*UserNotice)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onRaid = fn
+func (h *chatHandlers) OnRaid(fn func(*UserNotice)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onRaid = fn
 }
 
 // OnCheer sets the handler for cheer (bits) messages.
-func (c *ChatBotClient) OnCheer(fn func(*ChatMessage)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onCheer = fn
+func (h *chatHandlers) OnCheer(fn func(*ChatMessage)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onCheer = fn
 }
 
 // OnJoin sets the handler for user join events.
-func (c *ChatBotClient) OnJoin(fn func(channel, user string)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onJoin = fn
+func (h *chatHandlers) OnJoin(fn func(channel, user string)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onJoin = fn
 }
 
 // OnPart sets the handler for user part events.
-func (c *ChatBotClient) OnPart(fn func(channel, user string)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onPart = fn
+func (h *chatHandlers) OnPart(fn func(channel, user string)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onPart = fn
 }
 
 // OnRoomState sets the handler for room state changes.
-func (c *ChatBotClient) OnRoomState(fn func(*RoomState)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onRoomState = fn
+func (h *chatHandlers) OnRoomState(fn func(*RoomState)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onRoomState = fn
 }
 
 // OnNotice sets the handler for server notices.
-func (c *ChatBotClient) OnNotice(fn func(*Notice)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onNotice = fn
+func (h *chatHandlers) OnNotice(fn func(*Notice)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onNotice = fn
 }
 
 // OnClearChat sets the handler for chat clear/timeout/ban events.
-func (c *ChatBotClient) OnClearChat(fn func(*ClearChat)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onClearChat = fn
+func (h *chatHandlers) OnClearChat(fn func(*ClearChat)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onClearChat = fn
 }
 
 // OnWhisper sets the handler for whisper messages.
-func (c *ChatBotClient) OnWhisper(fn func(*Whisper)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onWhisper = fn
+func (h *chatHandlers) OnWhisper(fn func(*Whisper)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onWhisper = fn
 }
 
 // OnConnect sets the handler for successful connections.
-func (c *ChatBotClient) OnConnect(fn func()) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onConnect = fn
+func (h *chatHandlers) OnConnect(fn func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onConnect = fn
 }
 
 // OnDisconnect sets the handler for disconnections.
-func (c *ChatBotClient) OnDisconnect(fn func()) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onDisconnect = fn
+func (h *chatHandlers) OnDisconnect(fn func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onDisconnect = fn
 }
 
 // OnError sets the handler for errors.
-func (c *ChatBotClient) OnError(fn func(error)) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.onError = fn
+func (h *chatHandlers) OnError(fn func(error)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onError = fn
+}
+
+// SetCommandRouter sets the router that runs commands in chat messages. Messages
+// are passed to the router after the OnMessage handler.
+func (h *chatHandlers) SetCommandRouter(router *CommandRouter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.router = router
 }
 
 // Connect establishes a connection to Twitch chat.
@@ -177,19 +204,7 @@ func (c *ChatBotClient) Connect(ctx context.Context) error {
 		return errors.New("chatbot: no authentication token available")
 	}
 
-	ircOpts := []IRCOption{
-		WithMessageHandler(c.handleMessage),
-		WithUserNoticeHandler(c.handleUserNotice),
-		WithJoinHandler(c.handleJoin),
-		WithPartHandler(c.handlePart),
-		WithRoomStateHandler(c.handleRoomState),
-		WithNoticeHandler(c.handleNotice),
-		WithClearChatHandler(c.handleClearChat),
-		WithWhisperHandler(c.handleWhisper),
-		WithConnectHandler(c.handleConnect),
-		WithDisconnectHandler(c.handleDisconnect),
-		WithIRCErrorHandler(c.handleError),
-	}
+	ircOpts := append(append([]IRCOption{}, c.ircOpts...), c.ircHandlerOptions()...)
 	if c.ircURL != "" {
 		ircOpts = append(ircOpts, WithIRCURL(c.ircURL))
 	}
@@ -273,11 +288,29 @@ func (c *ChatBotClient) IRC() *IRCClient {
 
 // Internal handlers
 
-func (c *ChatBotClient) handleMessage(msg *ChatMessage) {
-	c.mu.RLock()
-	onMessage := c.onMessage
-	onCheer := c.onCheer
-	c.mu.RUnlock()
+// ircHandlerOptions returns options that pass an IRCClient's events to the handlers.
+func (h *chatHandlers) ircHandlerOptions() []IRCOption {
+	return []IRCOption{
+		WithMessageHandler(h.handleMessage),
+		WithUserNoticeHandler(h.handleUserNotice),
+		WithJoinHandler(h.handleJoin),
+		WithPartHandler(h.handlePart),
+		WithRoomStateHandler(h.handleRoomState),
+		WithNoticeHandler(h.handleNotice),
+		WithClearChatHandler(h.handleClearChat),
+		WithWhisperHandler(h.handleWhisper),
+		WithConnectHandler(h.handleConnect),
+		WithDisconnectHandler(h.handleDisconnect),
+		WithIRCErrorHandler(h.handleError),
+	}
+}
+
+func (h *chatHandlers) handleMessage(msg *ChatMessage) {
+	h.mu.RLock()
+	onMessage := h.onMessage
+	onCheer := h.onCheer
+	router := h.router
+	h.mu.RUnlock()
 
 	// Check for cheers
 	if msg.Bits > 0 && onCheer != nil {
@@ -287,15 +320,19 @@ func (c *ChatBotClient) handleMessage(msg *ChatMessage) {
 	if onMessage != nil {
 		onMessage(msg)
 	}
+
+	if router != nil {
+		router.Handle(h.sender, msg)
+	}
 }
 
-func (c *ChatBotClient) handleUserNotice(notice *UserNotice) {
-	c.mu.RLock()
-	onSub := c.onSub
-	onResub := c.onResub
-	onSubGift := c.onSubGift
-	onRaid := c.onRaid
-	c.mu.RUnlock()
+func (h *chatHandlers) handleUserNotice(notice *UserNotice) {
+	h.mu.RLock()
+	onSub := h.onSub
+	onResub := h.onResub
+	onSubGift := h.onSubGift
+	onRaid := h.onRaid
+	h.mu.RUnlock()
 
 	switch notice.Type {
 	case UserNoticeTypeSub:
@@ -317,90 +354,90 @@ func (c *ChatBotClient) handleUserNotice(notice *UserNotice) {
 	}
 }
 
-func (c *ChatBotClient) handleJoin(channel, user string) {
-	c.mu.RLock()
-	fn := c.onJoin
-	c.mu.RUnlock()
+func (h *chatHandlers) handleJoin(channel, user string) {
+	h.mu.RLock()
+	fn := h.onJoin
+	h.mu.RUnlock()
 
 	if fn != nil {
 		fn(channel, user)
 	}
 }
 
-func (c *ChatBotClient) handlePart(channel, user string) {
-	c.mu.RLock()
-	fn := c.onPart
-	c.mu.RUnlock()
+func (h *chatHandlers) handlePart(channel, user string) {
+	h.mu.RLock()
+	fn := h.onPart
+	h.mu.RUnlock()
 
 	if fn != nil {
 		fn(channel, user)
 	}
 }
 
-func (c *ChatBotClient) handleRoomState(state *RoomState) {
-	c.mu.RLock()
-	fn := c.onRoomState
-	c.mu.RUnlock()
+func (h *chatHandlers) handleRoomState(state *RoomState) {
+	h.mu.RLock()
+	fn := h.onRoomState
+	h.mu.RUnlock()
 
 	if fn != nil {
 		fn(state)
 	}
 }
 
-func (c *ChatBotClient) handleNotice(notice *Notice) {
-	c.mu.RLock()
-	fn := c.onNotice
-	c.mu.RUnlock()
+func (h *chatHandlers) handleNotice(notice *Notice) {
+	h.mu.RLock()
+	fn := h.onNotice
+	h.mu.RUnlock()
 
 	if fn != nil {
 		fn(notice)
 	}
 }
 
-func (c *ChatBotClient) handleClearChat(clear *ClearChat) {
-	c.mu.RLock()
-	fn := c.onClearChat
-	c.mu.RUnlock()
+func (h *chatHandlers) handleClearChat(clear *ClearChat) {
+	h.mu.RLock()
+	fn := h.onClearChat
+	h.mu.RUnlock()
 
 	if fn != nil {
 		fn(clear)
 	}
 }
 
-func (c *ChatBotClient) handleWhisper(whisper *Whisper) {
-	c.mu.RLock()
-	fn := c.onWhisper
-	c.mu.RUnlock()
+func (h *chatHandlers) handleWhisper(whisper *Whisper) {
+	h.mu.RLock()
+	fn := h.onWhisper
+	h.mu.RUnlock()
 
 	if fn != nil {
 		fn(whisper)
 	}
 }
 
-func (c *ChatBotClient) handleConnect() {
-	c.mu.RLock()
-	fn := c.onConnect
-	c.mu.RUnlock()
+func (h *chatHandlers) handleConnect() {
+	h.mu.RLock()
+	fn := h.onConnect
+	h.mu.RUnlock()
 
 	if fn != nil {
 		fn()
 	}
 }
 
-func (c *ChatBotClient) handleDisconnect() {
-	c.mu.RLock()
-	fn := c.onDisconnect
-	c.mu.RUnlock()
+func (h *chatHandlers) handleDisconnect() {
+	h.mu.RLock()
+	fn := h.onDisconnect
+	h.mu.RUnlock()
 
 	if fn != nil {
 		fn()
 	}
 }
 
-func (c *ChatBotClient) handleError(err error) {
-	c.mu.RLock()
-	fn := c.onError
-	c.mu.RUnlock()
+func (h *chatHandlers) handleError(err error) {
+	h.mu.RLock()
+	fn := h.onError
+	h.mu.RUnlock()
 
 	if fn != nil {
 		fn(err)
@@ -889,6 +889,35 @@ func TestWithChatBotURL(t *testing.T) {
 	}
 }
 
+func TestWithChatBotIRCOptions(t *testing.T) {
+	mock := newMockIRCServer(func(conn *websocket.Conn) {
+		defer func() { _ = conn.Close() }()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(twitchWelcome))
+		time.Sleep(50 * time.Millisecond)
+	})
+	defer mock.Close()
+
+	authClient := &AuthClient{}
+	authClient.token = &Token{AccessToken: "oauth:abcdefghijklmnop123456789"}
+	client := NewChatBotClient("justinfan12345", authClient,
+		WithChatBotURL(mock.URL()),
+		WithChatBotIRCOptions(WithAutoReconnect(false), WithIRCMessageSplitting(100)),
+	)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	irc := client.IRC()
+	if irc.autoReconnect || !irc.splitMessages || irc.maxMessageLength != 100 {
+		t.Errorf("expected IRC options to be applied, got autoReconnect=%v splitMessages=%v maxMessageLength=%d",
+			irc.autoReconnect, irc.splitMessages, irc.maxMessageLength)
+	}
+	if irc.onMessage == nil {
+		t.Error("expected the chat bot's message handler to be set")
+	}
+}
+
 func TestChatBotClient_OperationsWithMockConnection(t *testing.T) {
 	// Create mock IRC server that accepts messages (simulating Twitch TMI)
 	mock := newMockIRCServer(func(conn *websocket.Conn) {
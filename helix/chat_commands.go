@@ -0,0 +1,601 @@
+package helix
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command router errors
+var (
+	ErrCommandExists    = errors.New("command: name or alias already registered")
+	ErrCommandInvalid   = errors.New("command: name and handler are required")
+	ErrCommandForbidden = errors.New("command: permission denied")
+)
+
+// CommandPermission is the role a chatter needs to run a command. Higher levels
+// include lower ones, so moderators can run VIP commands.
+type CommandPermission int
+
+const (
+	PermissionEveryone CommandPermission = iota
+	PermissionSubscriber
+	PermissionVIP
+	PermissionModerator
+	PermissionBroadcaster
+)
+
+// String returns the permission's name.
+func (p CommandPermission) String() string {
+	switch p {
+	case PermissionSubscriber:
+		return "subscriber"
+	case PermissionVIP:
+		return "VIP"
+	case PermissionModerator:
+		return "moderator"
+	case PermissionBroadcaster:
+		return "broadcaster"
+	default:
+		return "everyone"
+	}
+}
+
+// PermissionOf returns the highest permission level of a message's sender,
+// derived from its badges.
+func PermissionOf(msg *ChatMessage) CommandPermission {
+	has := func(badge string) bool {
+		_, ok := msg.Badges[badge]
+		return ok
+	}
+	switch {
+	case msg.IsBroadcaster || has("broadcaster"):
+		return PermissionBroadcaster
+	case msg.IsMod || has("moderator"):
+		return PermissionModerator
+	case msg.IsVIP || has("vip"):
+		return PermissionVIP
+	case msg.IsSubscriber || has("subscriber") || has("founder"):
+		return PermissionSubscriber
+	default:
+		return PermissionEveryone
+	}
+}
+
+// ArgType is the type of a command argument.
+type ArgType int
+
+const (
+	// ArgString is a single word, or a quoted string.
+	ArgString ArgType = iota
+	// ArgInt is an integer.
+	ArgInt
+	// ArgUser is a user login, with or without a leading @. It is lowercased.
+	ArgUser
+	// ArgDuration is a Go duration such as 90s or 1h30m, a number of days or
+	// weeks such as 2d or 1w, or a plain number of seconds.
+	ArgDuration
+	// ArgRest is the rest of the message as typed, with quotes and spacing kept.
+	// It must be the last argument.
+	ArgRest
+)
+
+// CommandArg describes a command argument.
+type CommandArg struct {
+	Name     string
+	Type     ArgType
+	Optional bool
+}
+
+// Command is a chat command registered with a CommandRouter.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Args        []CommandArg
+	Permission  CommandPermission
+	// Cooldown is the time between uses of the command in a channel, and
+	// UserCooldown the time between uses by the same chatter. Moderators and the
+	// broadcaster are not subject to cooldowns.
+	Cooldown     time.Duration
+	UserCooldown time.Duration
+	// Hidden commands are left out of the help command's list.
+	Hidden  bool
+	Handler func(*CommandContext) error
+}
+
+// Usage returns the command's usage, e.g. "!timeout <user> [duration] [reason...]".
+func (cmd *Command) Usage(prefix string) string {
+	var b strings.Builder
+	b.WriteString(prefix + cmd.Name)
+	for _, arg := range cmd.Args {
+		name := arg.Name
+		if arg.Type == ArgRest {
+			name += "..."
+		}
+		if arg.Optional {
+			b.WriteString(" [" + name + "]")
+		} else {
+			b.WriteString(" <" + name + ">")
+		}
+	}
+	return b.String()
+}
+
+// CommandArgError is returned when a command's arguments are missing or invalid.
+type CommandArgError struct {
+	Arg    string
+	Value  string // Empty if the argument is missing
+	Reason string
+}
+
+func (e *CommandArgError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("command: missing %s", e.Arg)
+	}
+	return fmt.Sprintf("command: invalid %s %q: %s", e.Arg, e.Value, e.Reason)
+}
+
+// CommandCooldownError is returned when a command is used during its cooldown.
+type CommandCooldownError struct {
+	Remaining time.Duration
+	User      bool // Whether the per-user cooldown applies
+}
+
+func (e *CommandCooldownError) Error() string {
+	return fmt.Sprintf("command: on cooldown for %s", e.Remaining.Round(time.Second))
+}
+
+// ChatSender sends chat messages. ChatBotClient and IRCClient implement it.
+type ChatSender interface {
+	Say(channel, message string) error
+	Reply(channel, parentMsgID, message string) error
+}
+
+// CommandContext is passed to a command's handler.
+type CommandContext struct {
+	Sender  ChatSender
+	Message *ChatMessage
+	Command *Command
+	Name    string   // Name or alias the command was invoked with
+	Args    []string // All arguments as typed, with quotes removed
+	Router  *CommandRouter
+
+	values map[string]any
+}
+
+// Has reports whether an argument was given.
+func (ctx *CommandContext) Has(name string) bool {
+	_, ok := ctx.values[name]
+	return ok
+}
+
+// String returns a string, user or rest argument, or "" if it wasn't given.
+func (ctx *CommandContext) String(name string) string {
+	s, _ := ctx.values[name].(string)
+	return s
+}
+
+// Int returns an int argument, or 0 if it wasn't given.
+func (ctx *CommandContext) Int(name string) int {
+	n, _ := ctx.values[name].(int)
+	return n
+}
+
+// Duration returns a duration argument, or 0 if it wasn't given.
+func (ctx *CommandContext) Duration(name string) time.Duration {
+	d, _ := ctx.values[name].(time.Duration)
+	return d
+}
+
+// Permission returns the sender's permission level.
+func (ctx *CommandContext) Permission() CommandPermission {
+	return PermissionOf(ctx.Message)
+}
+
+// Say sends a message to the channel the command was used in.
+func (ctx *CommandContext) Say(message string) error {
+	return ctx.Sender.Say(ctx.Message.Channel, message)
+}
+
+// Reply replies to the message that invoked the command.
+func (ctx *CommandContext) Reply(message string) error {
+	return ctx.Sender.Reply(ctx.Message.Channel, ctx.Message.ID, message)
+}
+
+// CommandRouter parses chat messages into commands and runs their handlers.
+type CommandRouter struct {
+	prefix  string
+	onError func(*CommandContext, error)
+
+	mu        sync.Mutex
+	commands  map[string]*Command  // By lowercased name and alias
+	cooldowns map[string]time.Time // Cooldown key to when the cooldown ends
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// cooldownSweepInterval is how often ended cooldowns are removed.
+const cooldownSweepInterval = time.Minute
+
+// CommandRouterOption configures a CommandRouter.
+type CommandRouterOption func(*CommandRouter)
+
+// NewCommandRouter creates a command router. The default prefix is "!".
+func NewCommandRouter(opts ...CommandRouterOption) *CommandRouter {
+	r := &CommandRouter{
+		prefix:    "!",
+		commands:  make(map[string]*Command),
+		cooldowns: make(map[string]time.Time),
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithCommandPrefix sets the prefix commands start with.
+func WithCommandPrefix(prefix string) CommandRouterOption {
+	return func(r *CommandRouter) {
+		r.prefix = prefix
+	}
+}
+
+// WithCommandErrorHandler sets the handler for command errors: a
+// CommandArgError, CommandCooldownError or ErrCommandForbidden, or an error
+// returned by a command's handler. Without it, argument errors are answered with
+// the command's usage, handler errors are passed to the ChatBotClient's or
+// IRCPool's error handler, and permission and cooldown errors are ignored.
+func WithCommandErrorHandler(fn func(*CommandContext, error)) CommandRouterOption {
+	return func(r *CommandRouter) {
+		r.onError = fn
+	}
+}
+
+// WithHelpCommand registers a help command under name and aliases. Used alone,
+// it lists the commands the chatter may run; followed by a command name, it
+// replies with that command's usage and description.
+func WithHelpCommand(name string, aliases ...string) CommandRouterOption {
+	return func(r *CommandRouter) {
+		_ = r.Register(&Command{
+			Name:        name,
+			Aliases:     aliases,
+			Description: "Lists commands, or describes one.",
+			Args:        []CommandArg{{Name: "command", Optional: true}},
+			Handler:     r.help,
+		})
+	}
+}
+
+// Prefix returns the router's command prefix.
+func (r *CommandRouter) Prefix() string {
+	return r.prefix
+}
+
+// Register adds a command. It returns ErrCommandExists if its name or one of its
+// aliases is already registered.
+func (r *CommandRouter) Register(cmd *Command) error {
+	if cmd == nil || cmd.Name == "" || cmd.Handler == nil {
+		return ErrCommandInvalid
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := append([]string{cmd.Name}, cmd.Aliases...)
+	for _, name := range names {
+		if _, ok := r.commands[strings.ToLower(name)]; ok {
+			return fmt.Errorf("%w: %s", ErrCommandExists, name)
+		}
+	}
+	for _, name := range names {
+		r.commands[strings.ToLower(name)] = cmd
+	}
+	return nil
+}
+
+// Command returns the command registered under a name or alias, or nil.
+func (r *CommandRouter) Command(name string) *Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.commands[strings.ToLower(strings.TrimPrefix(name, r.prefix))]
+}
+
+// Commands returns the registered commands sorted by name.
+func (r *CommandRouter) Commands() []*Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var cmds []*Command
+	for name, cmd := range r.commands {
+		if strings.EqualFold(name, cmd.Name) {
+			cmds = append(cmds, cmd)
+		}
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds
+}
+
+// Help returns the list of visible commands a chatter with the given permission
+// may run, e.g. "Commands: !help, !uptime".
+func (r *CommandRouter) Help(permission CommandPermission) string {
+	var names []string
+	for _, cmd := range r.Commands() {
+		if !cmd.Hidden && cmd.Permission <= permission {
+			names = append(names, r.prefix+cmd.Name)
+		}
+	}
+	if len(names) == 0 {
+		return "No commands available."
+	}
+	return "Commands: " + strings.Join(names, ", ")
+}
+
+// Describe returns a command's usage, description, aliases and permission, or ""
+// if no such command is registered.
+func (r *CommandRouter) Describe(name string) string {
+	cmd := r.Command(name)
+	if cmd == nil {
+		return ""
+	}
+	s := cmd.Usage(r.prefix)
+	if cmd.Description != "" {
+		s += " - " + cmd.Description
+	}
+	if len(cmd.Aliases) > 0 {
+		s += " Aliases: " + r.prefix + strings.Join(cmd.Aliases, ", "+r.prefix) + "."
+	}
+	if cmd.Permission > PermissionEveryone {
+		s += " Requires " + cmd.Permission.String() + "."
+	}
+	return s
+}
+
+func (r *CommandRouter) help(ctx *CommandContext) error {
+	if ctx.Has("command") {
+		cmd := r.Command(ctx.String("command"))
+		if cmd == nil || cmd.Hidden {
+			return ctx.Reply("Unknown command " + ctx.String("command") + ".")
+		}
+		return ctx.Reply(r.Describe(cmd.Name))
+	}
+	return ctx.Reply(r.Help(ctx.Permission()))
+}
+
+// Handle runs the command in msg, if any, replying through sender. It returns
+// whether msg invoked a registered command.
+func (r *CommandRouter) Handle(sender ChatSender, msg *ChatMessage) bool {
+	text := msg.Message
+	// Replies start with a mention of the parent message's author
+	if msg.ReplyParentUserLogin != "" {
+		text = strings.TrimPrefix(text, "@"+msg.ReplyParentUserLogin+" ")
+	}
+	// Other bots' duplicate bypass character isn't part of the command
+	text = strings.ReplaceAll(text, "\U000E0000", "")
+
+	rest, ok := strings.CutPrefix(text, r.prefix)
+	if !ok || rest == "" || rest[0] == ' ' {
+		return false
+	}
+	tokens, starts := tokenizeCommand(rest)
+	if len(tokens) == 0 {
+		return false
+	}
+	cmd := r.Command(tokens[0])
+	if cmd == nil {
+		return false
+	}
+
+	ctx := &CommandContext{
+		Sender:  sender,
+		Message: msg,
+		Command: cmd,
+		Name:    strings.ToLower(tokens[0]),
+		Args:    tokens[1:],
+		Router:  r,
+	}
+	permission := PermissionOf(msg)
+	if permission < cmd.Permission {
+		r.fail(ctx, ErrCommandForbidden)
+		return true
+	}
+	remainders := make([]string, len(starts)-1)
+	for i, start := range starts[1:] {
+		remainders[i] = strings.TrimRight(rest[start:], " ")
+	}
+	values, err := parseCommandArgs(cmd.Args, ctx.Args, remainders)
+	if err != nil {
+		r.fail(ctx, err)
+		return true
+	}
+	ctx.values = values
+	if permission < PermissionModerator {
+		if err := r.useCooldown(cmd, msg); err != nil {
+			r.fail(ctx, err)
+			return true
+		}
+	}
+
+	if err := cmd.Handler(ctx); err != nil {
+		r.fail(ctx, err)
+	}
+	return true
+}
+
+// useCooldown records a use of cmd, or returns a CommandCooldownError if the
+// command or the chatter is on cooldown.
+func (r *CommandRouter) useCooldown(cmd *Command, msg *ChatMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if now.Sub(r.lastSweep) >= cooldownSweepInterval {
+		r.sweepCooldowns(now)
+	}
+	channelKey := msg.Channel + "\x00" + cmd.Name
+	userKey := channelKey + "\x00" + msg.UserID
+
+	if cmd.UserCooldown > 0 {
+		if remaining := r.cooldowns[userKey].Sub(now); remaining > 0 {
+			return &CommandCooldownError{Remaining: remaining, User: true}
+		}
+	}
+	if cmd.Cooldown > 0 {
+		if remaining := r.cooldowns[channelKey].Sub(now); remaining > 0 {
+			return &CommandCooldownError{Remaining: remaining}
+		}
+	}
+	if cmd.Cooldown > 0 {
+		r.cooldowns[channelKey] = now.Add(cmd.Cooldown)
+	}
+	if cmd.UserCooldown > 0 {
+		r.cooldowns[userKey] = now.Add(cmd.UserCooldown)
+	}
+	return nil
+}
+
+// sweepCooldowns removes cooldowns that have ended (must be called with lock held).
+func (r *CommandRouter) sweepCooldowns(now time.Time) {
+	for key, end := range r.cooldowns {
+		if !end.After(now) {
+			delete(r.cooldowns, key)
+		}
+	}
+	r.lastSweep = now
+}
+
+// fail reports a command error.
+func (r *CommandRouter) fail(ctx *CommandContext, err error) {
+	if r.onError != nil {
+		r.onError(ctx, err)
+		return
+	}
+
+	var argErr *CommandArgError
+	var cooldownErr *CommandCooldownError
+	switch {
+	case errors.As(err, &argErr):
+		_ = ctx.Reply("Usage: " + ctx.Command.Usage(r.prefix))
+	case errors.As(err, &cooldownErr), errors.Is(err, ErrCommandForbidden):
+	default:
+		if h, ok := ctx.Sender.(interface{ handleError(error) }); ok {
+			h.handleError(fmt.Errorf("command %s: %w", ctx.Command.Name, err))
+		}
+	}
+}
+
+// tokenizeCommand splits s on spaces, keeping double-quoted strings together,
+// and returns the tokens with the offset in s each starts at. A backslash escapes
+// a quote inside a quoted string.
+func tokenizeCommand(s string) (tokens []string, starts []int) {
+	var b strings.Builder
+	inToken, quoted := false, false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if !inToken && ch != ' ' {
+			starts = append(starts, i)
+		}
+		switch {
+		case quoted && ch == '\\' && i+1 < len(s) && s[i+1] == '"':
+			b.WriteByte('"')
+			i++
+		case ch == '"' && (quoted || !inToken):
+			quoted = !quoted
+			inToken = true
+		case ch == ' ' && !quoted:
+			if inToken {
+				tokens = append(tokens, b.String())
+				b.Reset()
+				inToken = false
+			}
+		default:
+			b.WriteByte(ch)
+			inToken = true
+		}
+	}
+	if inToken {
+		tokens = append(tokens, b.String())
+	}
+	return tokens, starts
+}
+
+// parseCommandArgs converts tokens to the types of args, keyed by name.
+// remainders[i] is the message as typed from token i on, for ArgRest.
+func parseCommandArgs(args []CommandArg, tokens, remainders []string) (map[string]any, error) {
+	values := make(map[string]any, len(args))
+	for i, arg := range args {
+		if i >= len(tokens) {
+			if !arg.Optional {
+				return nil, &CommandArgError{Arg: arg.Name}
+			}
+			continue
+		}
+
+		token := tokens[i]
+		switch arg.Type {
+		case ArgInt:
+			n, err := strconv.Atoi(token)
+			if err != nil {
+				return nil, &CommandArgError{Arg: arg.Name, Value: token, Reason: "not a number"}
+			}
+			values[arg.Name] = n
+		case ArgUser:
+			login := strings.ToLower(strings.TrimPrefix(token, "@"))
+			if !isValidLogin(login) {
+				return nil, &CommandArgError{Arg: arg.Name, Value: token, Reason: "not a username"}
+			}
+			values[arg.Name] = login
+		case ArgDuration:
+			d, err := parseCommandDuration(token)
+			if err != nil {
+				return nil, &CommandArgError{Arg: arg.Name, Value: token, Reason: "not a duration"}
+			}
+			values[arg.Name] = d
+		case ArgRest:
+			values[arg.Name] = remainders[i]
+			return values, nil
+		default:
+			values[arg.Name] = token
+		}
+	}
+	return values, nil
+}
+
+// parseCommandDuration parses a Go duration, a number of days or weeks such as
+// "2d", or a plain number of seconds. The duration must be positive.
+func parseCommandDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("empty duration")
+	}
+	var d time.Duration
+	if n, err := strconv.Atoi(s); err == nil {
+		d = time.Duration(n) * time.Second
+	} else if unit, ok := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]; ok {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * unit
+	} else if d, err = time.ParseDuration(s); err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+	return d, nil
+}
+
+// isValidLogin reports whether s is a valid Twitch login.
+func isValidLogin(s string) bool {
+	if len(s) == 0 || len(s) > 25 {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
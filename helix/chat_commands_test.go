@@ -0,0 +1,312 @@
+package helix
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingSender records messages sent through it.
+type recordingSender struct {
+	sent []string
+}
+
+func (s *recordingSender) Say(channel, message string) error {
+	s.sent = append(s.sent, "#"+channel+" "+message)
+	return nil
+}
+
+func (s *recordingSender) Reply(channel, parentMsgID, message string) error {
+	s.sent = append(s.sent, "#"+channel+" @"+parentMsgID+" "+message)
+	return nil
+}
+
+// chat returns a message from a viewer in #test.
+func chat(text string) *ChatMessage {
+	return &ChatMessage{ID: "m1", Channel: "test", User: "viewer", UserID: "2", Message: text}
+}
+
+func TestPermissionOf(t *testing.T) {
+	tests := []struct {
+		msg  *ChatMessage
+		want CommandPermission
+	}{
+		{&ChatMessage{}, PermissionEveryone},
+		{&ChatMessage{Badges: map[string]string{"founder": "0"}}, PermissionSubscriber},
+		{&ChatMessage{IsSubscriber: true}, PermissionSubscriber},
+		{&ChatMessage{IsVIP: true, IsSubscriber: true}, PermissionVIP},
+		{&ChatMessage{Badges: map[string]string{"moderator": "1"}}, PermissionModerator},
+		{&ChatMessage{IsBroadcaster: true}, PermissionBroadcaster},
+	}
+	for _, tt := range tests {
+		if got := PermissionOf(tt.msg); got != tt.want {
+			t.Errorf("PermissionOf(%+v) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeCommand(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"so  streamer", []string{"so", "streamer"}},
+		{`title "Just Chatting" now`, []string{"title", "Just Chatting", "now"}},
+		{`say "a \"quoted\" word"`, []string{"say", `a "quoted" word`}},
+		{`say "unterminated quote`, []string{"say", "unterminated quote"}},
+		{`say it"s`, []string{"say", `it"s`}},
+		{`x ""`, []string{"x", ""}},
+	}
+	for _, tt := range tests {
+		if got, _ := tokenizeCommand(tt.in); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("tokenizeCommand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	in := `say  "hi  there" x`
+	_, starts := tokenizeCommand(in)
+	if len(starts) != 3 || in[starts[1]:] != `"hi  there" x` || in[starts[2]:] != "x" {
+		t.Errorf("unexpected token starts %v", starts)
+	}
+}
+
+func TestParseCommandDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"600":   10 * time.Minute,
+		"90s":   90 * time.Second,
+		"1h30m": 90 * time.Minute,
+		"2d":    48 * time.Hour,
+		"1w":    7 * 24 * time.Hour,
+	}
+	for in, want := range tests {
+		if got, err := parseCommandDuration(in); err != nil || got != want {
+			t.Errorf("parseCommandDuration(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0", "-5m", "xd", "soon"} {
+		if _, err := parseCommandDuration(in); err == nil {
+			t.Errorf("expected an error for %q", in)
+		}
+	}
+}
+
+func TestCommandRouter_Handle(t *testing.T) {
+	r := NewCommandRouter()
+	var got *CommandContext
+	err := r.Register(&Command{
+		Name:    "timeout",
+		Aliases: []string{"to"},
+		Args: []CommandArg{
+			{Name: "user", Type: ArgUser},
+			{Name: "duration", Type: ArgDuration, Optional: true},
+			{Name: "reason", Type: ArgRest, Optional: true},
+		},
+		Handler: func(ctx *CommandContext) error {
+			got = ctx
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sender := &recordingSender{}
+	if !r.Handle(sender, chat(`!TO @SomeUser 10m being "very"  rude `)) {
+		t.Fatal("expected the command to be handled")
+	}
+	if got.Name != "to" || got.String("user") != "someuser" || got.Duration("duration") != 10*time.Minute ||
+		got.String("reason") != `being "very"  rude` {
+		t.Errorf("unexpected context: name=%s user=%s duration=%v reason=%q",
+			got.Name, got.String("user"), got.Duration("duration"), got.String("reason"))
+	}
+
+	got = nil
+	r.Handle(sender, chat("!timeout someuser"))
+	if got == nil || got.Has("duration") || got.Has("reason") {
+		t.Errorf("expected optional args to be unset, got %+v", got)
+	}
+
+	for _, text := range []string{"hello", "! timeout x", "!unknown", "!"} {
+		if r.Handle(sender, chat(text)) {
+			t.Errorf("expected %q not to be handled", text)
+		}
+	}
+}
+
+func TestCommandRouter_ReplyAndBypass(t *testing.T) {
+	r := NewCommandRouter(WithCommandPrefix("?"))
+	calls := 0
+	_ = r.Register(&Command{Name: "ping", Handler: func(ctx *CommandContext) error {
+		calls++
+		return ctx.Reply("pong")
+	}})
+
+	sender := &recordingSender{}
+	msg := chat("@someone ?ping \U000E0000")
+	msg.ReplyParentUserLogin = "someone"
+	if !r.Handle(sender, msg) || calls != 1 {
+		t.Fatal("expected the command in a reply to be handled")
+	}
+	if len(sender.sent) != 1 || sender.sent[0] != "#test @m1 pong" {
+		t.Errorf("unexpected messages: %v", sender.sent)
+	}
+}
+
+func TestCommandRouter_Register(t *testing.T) {
+	r := NewCommandRouter()
+	noop := func(*CommandContext) error { return nil }
+	if err := r.Register(&Command{Name: "a", Aliases: []string{"b"}, Handler: noop}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Register(&Command{Name: "B", Handler: noop}); !errors.Is(err, ErrCommandExists) {
+		t.Errorf("expected ErrCommandExists, got %v", err)
+	}
+	if err := r.Register(&Command{Name: "c"}); !errors.Is(err, ErrCommandInvalid) {
+		t.Errorf("expected ErrCommandInvalid, got %v", err)
+	}
+	if cmds := r.Commands(); len(cmds) != 1 || cmds[0].Name != "a" {
+		t.Errorf("unexpected commands: %v", cmds)
+	}
+}
+
+func TestCommandRouter_Errors(t *testing.T) {
+	var errs []error
+	r := NewCommandRouter(WithCommandErrorHandler(func(ctx *CommandContext, err error) {
+		errs = append(errs, err)
+	}))
+	handlerErr := errors.New("boom")
+	_ = r.Register(&Command{Name: "mod", Permission: PermissionModerator, Handler: func(*CommandContext) error { return nil }})
+	_ = r.Register(&Command{Name: "count", Args: []CommandArg{{Name: "n", Type: ArgInt}}, Handler: func(*CommandContext) error { return nil }})
+	_ = r.Register(&Command{Name: "fail", Handler: func(*CommandContext) error { return handlerErr }})
+
+	sender := &recordingSender{}
+	r.Handle(sender, chat("!mod"))
+	r.Handle(sender, chat("!count"))
+	r.Handle(sender, chat("!count many"))
+	r.Handle(sender, chat("!fail"))
+
+	var argErr *CommandArgError
+	if len(errs) != 4 || !errors.Is(errs[0], ErrCommandForbidden) || !errors.As(errs[1], &argErr) ||
+		!errors.As(errs[2], &argErr) || argErr.Value != "many" || !errors.Is(errs[3], handlerErr) {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if len(sender.sent) != 0 {
+		t.Errorf("expected no messages with an error handler, got %v", sender.sent)
+	}
+}
+
+func TestCommandRouter_DefaultErrors(t *testing.T) {
+	r := NewCommandRouter()
+	_ = r.Register(&Command{Name: "count", Args: []CommandArg{{Name: "n", Type: ArgInt}}, Handler: func(*CommandContext) error { return nil }})
+	_ = r.Register(&Command{Name: "mod", Permission: PermissionModerator, Handler: func(*CommandContext) error { return nil }})
+
+	sender := &recordingSender{}
+	r.Handle(sender, chat("!count x"))
+	r.Handle(sender, chat("!mod"))
+	if len(sender.sent) != 1 || sender.sent[0] != "#test @m1 Usage: !count <n>" {
+		t.Errorf("expected only a usage reply, got %v", sender.sent)
+	}
+}
+
+func TestCommandRouter_Cooldowns(t *testing.T) {
+	var errs []error
+	r := NewCommandRouter(WithCommandErrorHandler(func(ctx *CommandContext, err error) {
+		errs = append(errs, err)
+	}))
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	calls := 0
+	_ = r.Register(&Command{Name: "hug", Cooldown: 5 * time.Second, UserCooldown: time.Minute, Handler: func(*CommandContext) error {
+		calls++
+		return nil
+	}})
+
+	other := chat("!hug")
+	other.UserID = "3"
+	mod := chat("!hug")
+	mod.IsMod = true
+
+	sender := &recordingSender{}
+	r.Handle(sender, chat("!hug"))
+	r.Handle(sender, other) // Command cooldown
+	r.Handle(sender, mod)   // Moderators skip cooldowns
+	now = now.Add(5 * time.Second)
+	r.Handle(sender, other)
+	r.Handle(sender, chat("!hug")) // User cooldown
+
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+	var cooldownErr *CommandCooldownError
+	if len(errs) != 2 || !errors.As(errs[0], &cooldownErr) || cooldownErr.User || cooldownErr.Remaining != 5*time.Second {
+		t.Errorf("expected a command cooldown error first, got %v", errs)
+	}
+	if len(errs) == 2 && (!errors.As(errs[1], &cooldownErr) || !cooldownErr.User) {
+		t.Errorf("expected a user cooldown error, got %v", errs[1])
+	}
+
+	// Ended cooldowns are swept on a later command
+	if n := len(r.cooldowns); n != 3 {
+		t.Errorf("expected 3 cooldowns, got %d", n)
+	}
+	now = now.Add(time.Minute)
+	r.Handle(sender, chat("!hug"))
+	if n := len(r.cooldowns); n != 2 {
+		t.Errorf("expected ended cooldowns to be removed, got %d", n)
+	}
+}
+
+func TestCommandRouter_Help(t *testing.T) {
+	r := NewCommandRouter(WithHelpCommand("help", "commands"))
+	noop := func(*CommandContext) error { return nil }
+	_ = r.Register(&Command{Name: "uptime", Description: "Shows how long the stream has been live.", Handler: noop})
+	_ = r.Register(&Command{Name: "ban", Aliases: []string{"b"}, Description: "Bans a user.", Permission: PermissionModerator,
+		Args: []CommandArg{{Name: "user", Type: ArgUser}, {Name: "reason", Type: ArgRest, Optional: true}}, Handler: noop})
+	_ = r.Register(&Command{Name: "secret", Hidden: true, Handler: noop})
+
+	sender := &recordingSender{}
+	r.Handle(sender, chat("!help"))
+	mod := chat("!commands")
+	mod.IsMod = true
+	r.Handle(sender, mod)
+	r.Handle(sender, chat("!help !ban"))
+	r.Handle(sender, chat("!help secret"))
+
+	want := []string{
+		"#test @m1 Commands: !help, !uptime",
+		"#test @m1 Commands: !ban, !help, !uptime",
+		"#test @m1 !ban <user> [reason...] - Bans a user. Aliases: !b. Requires moderator.",
+		"#test @m1 Unknown command secret.",
+	}
+	if strings.Join(sender.sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected help:\n%s", strings.Join(sender.sent, "\n"))
+	}
+}
+
+func TestChatBotClient_SetCommandRouter(t *testing.T) {
+	r := NewCommandRouter()
+	handled := make(chan *CommandContext, 1)
+	_ = r.Register(&Command{Name: "hi", Handler: func(ctx *CommandContext) error {
+		handled <- ctx
+		return errors.New("boom")
+	}})
+
+	bot := NewChatBotClient("bot", nil)
+	bot.SetCommandRouter(r)
+	var botErr error
+	bot.OnError(func(err error) { botErr = err })
+	bot.handleMessage(chat("!hi"))
+
+	select {
+	case ctx := <-handled:
+		if ctx.Sender != bot {
+			t.Error("expected the chat bot as the sender")
+		}
+	default:
+		t.Fatal("expected the command to run")
+	}
+	if botErr == nil || !strings.Contains(botErr.Error(), "command hi: boom") {
+		t.Errorf("expected the handler error on the bot, got %v", botErr)
+	}
+}
@@ -0,0 +1,202 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// ErrChunkedCursor is returned when a pagination cursor is given for a request
+// whose IDs are split across several requests, which each have their own cursor.
+var ErrChunkedCursor = errors.New("after and before cursors can't be used with more IDs than one request accepts")
+
+// Maximum number of IDs Twitch accepts in a single request.
+const (
+	maxIDsPerRequest   = 100 // GetUsers, GetStreams, GetGames, GetUserChatColor
+	maxClipDownloadIDs = 10  // GetClipsDownload
+	maxDeleteVideoIDs  = 5   // DeleteVideos
+)
+
+// defaultChunkConcurrency is the number of chunked requests run at once by default.
+const defaultChunkConcurrency = 4
+
+// WithChunkConcurrency sets how many requests run at once when a method splits an
+// ID list that exceeds Twitch's per-request limit (default: 4).
+//
+// GetUsers, GetStreams, GetGames, GetClipsDownload, GetUserChatColor and
+// DeleteVideos split oversized ID lists into as few requests as possible, run them
+// with Batch and merge the results in the order of the IDs.
+func WithChunkConcurrency(n int) Option {
+	return func(c *Client) {
+		c.chunkConcurrency = n
+	}
+}
+
+// queryValue is a single query parameter that counts toward a per-request ID limit.
+type queryValue struct {
+	key   string
+	value string
+}
+
+// queryValues returns a queryValue for each value, all with the given key.
+func queryValues(key string, values []string) []queryValue {
+	qv := make([]queryValue, len(values))
+	for i, v := range values {
+		qv[i] = queryValue{key: key, value: v}
+	}
+	return qv
+}
+
+// chunkQueries splits values into queries holding at most size values each. Every
+// query starts with a copy of base.
+func chunkQueries(base url.Values, values []queryValue, size int) []url.Values {
+	var queries []url.Values
+	for start := 0; start < len(values); start += size {
+		q := url.Values{}
+		for k, v := range base {
+			q[k] = append([]string(nil), v...)
+		}
+		for _, v := range values[start:min(start+size, len(values))] {
+			q.Add(v.key, v.value)
+		}
+		queries = append(queries, q)
+	}
+	return queries
+}
+
+// doChunked performs a request for base plus values, splitting values across
+// requests of at most size run with Batch, and merges their data in order. It stops
+// on the first error. Pagination is only returned when a single request was needed.
+func doChunked[T any](ctx context.Context, c *Client, method, endpoint string, base url.Values, values []queryValue, size int) (*Response[T], error) {
+	queries := chunkQueries(base, values, size)
+	if len(queries) <= 1 {
+		req := &Request{Method: method, Endpoint: endpoint, Query: base}
+		if len(queries) == 1 {
+			req.Query = queries[0]
+		}
+		var resp Response[T]
+		if err := c.Do(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		return &resp, nil
+	}
+
+	responses, err := runChunks[T](ctx, c, method, endpoint, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &Response[T]{}
+	for _, resp := range responses {
+		merged.Data = append(merged.Data, resp.Data...)
+	}
+	return merged, nil
+}
+
+// runChunks performs a request for each query with Batch, stopping on the first error.
+func runChunks[T any](ctx context.Context, c *Client, method, endpoint string, queries []url.Values) ([]Response[T], error) {
+	responses := make([]Response[T], len(queries))
+	requests := make([]BatchRequest, len(queries))
+	for i, q := range queries {
+		requests[i] = BatchRequest{
+			Request: &Request{Method: method, Endpoint: endpoint, Query: q},
+			Result:  &responses[i],
+		}
+	}
+
+	concurrency := c.chunkConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultChunkConcurrency
+	}
+	results := c.Batch(ctx, requests, &BatchOptions{MaxConcurrent: concurrency, StopOnError: true})
+	if err := firstChunkError(results); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// getChunked is doChunked for GET requests.
+func getChunked[T any](ctx context.Context, c *Client, endpoint string, base url.Values, values []queryValue, size int) (*Response[T], error) {
+	return doChunked[T](ctx, c, http.MethodGet, endpoint, base, values, size)
+}
+
+// getChunkedPages is getChunked for paginated endpoints. When values are split,
+// each request's pages are followed until the merged response holds limit items
+// or the results run out; base must not carry a cursor then. Items matched by
+// more than one request are merged once, by key.
+func getChunkedPages[T any](ctx context.Context, c *Client, endpoint string, base url.Values, values []queryValue, size, limit int, key func(T) string) (*Response[T], error) {
+	queries := chunkQueries(base, values, size)
+	if len(queries) <= 1 {
+		return getChunked[T](ctx, c, endpoint, base, values, size)
+	}
+	if base.Has("after") || base.Has("before") {
+		return nil, ErrChunkedCursor
+	}
+
+	data := make([][]T, len(queries))
+	pending := make([]int, len(queries))
+	for i := range pending {
+		pending[i] = i
+	}
+	merged := &Response[T]{}
+	for len(pending) > 0 {
+		pageQueries := make([]url.Values, len(pending))
+		for j, i := range pending {
+			pageQueries[j] = queries[i]
+		}
+		responses, err := runChunks[T](ctx, c, http.MethodGet, endpoint, pageQueries)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []int
+		for j, i := range pending {
+			resp := responses[j]
+			data[i] = append(data[i], resp.Data...)
+			if resp.Pagination != nil && resp.Pagination.Cursor != "" && len(resp.Data) > 0 {
+				queries[i].Set("after", resp.Pagination.Cursor)
+				next = append(next, i)
+			}
+		}
+		pending = next
+
+		merged.Data = mergeChunkData(data, key)
+		if len(merged.Data) >= limit {
+			merged.Data = merged.Data[:limit]
+			break
+		}
+	}
+	return merged, nil
+}
+
+// mergeChunkData concatenates each request's items in order, keeping the first
+// item for each key.
+func mergeChunkData[T any](data [][]T, key func(T) string) []T {
+	var merged []T
+	seen := make(map[string]bool)
+	for _, d := range data {
+		for _, item := range d {
+			if k := key(item); !seen[k] {
+				seen[k] = true
+				merged = append(merged, item)
+			}
+		}
+	}
+	return merged
+}
+
+// firstChunkError returns the error that stopped a chunked batch, preferring it over
+// the context.Canceled recorded for the chunks skipped after it.
+func firstChunkError(results []BatchResult) error {
+	var first error
+	for _, r := range results {
+		if r.Error == nil {
+			continue
+		}
+		if first == nil || first == context.Canceled {
+			first = r.Error
+		}
+	}
+	return first
+}
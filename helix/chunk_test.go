@@ -0,0 +1,270 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChunkQueries(t *testing.T) {
+	base := url.Values{"type": {"live"}}
+	values := append(queryValues("user_id", []string{"1", "2", "3"}), queryValues("user_login", []string{"a", "b"})...)
+
+	queries := chunkQueries(base, values, 2)
+	if len(queries) != 3 {
+		t.Fatalf("expected 3 queries, got %d", len(queries))
+	}
+	want := []string{"type=live&user_id=1&user_id=2", "type=live&user_id=3&user_login=a", "type=live&user_login=b"}
+	for i, q := range queries {
+		if q.Encode() != want[i] {
+			t.Errorf("query %d: expected %q, got %q", i, want[i], q.Encode())
+		}
+	}
+	if len(base["type"]) != 1 || len(base) != 1 {
+		t.Errorf("expected base to be left unchanged, got %v", base)
+	}
+
+	if queries := chunkQueries(base, nil, 2); len(queries) != 0 {
+		t.Errorf("expected no queries without values, got %v", queries)
+	}
+}
+
+func TestGetUsers_ChunksOversizedIDLists(t *testing.T) {
+	var mu sync.Mutex
+	var requests []int
+	var inflight, maxInflight int
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inflight++
+		maxInflight = max(maxInflight, inflight)
+		requests = append(requests, len(r.URL.Query()["id"])+len(r.URL.Query()["login"]))
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		var users []User
+		for _, id := range r.URL.Query()["id"] {
+			users = append(users, User{ID: id})
+		}
+		for _, login := range r.URL.Query()["login"] {
+			users = append(users, User{Login: login})
+		}
+		_ = json.NewEncoder(w).Encode(Response[User]{Data: users})
+
+		mu.Lock()
+		inflight--
+		mu.Unlock()
+	}, WithChunkConcurrency(2))
+	defer server.Close()
+
+	var ids []string
+	for i := 0; i < 230; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+	resp, err := client.GetUsers(context.Background(), &GetUsersParams{IDs: ids, Logins: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 requests, got %v", requests)
+	}
+	for _, n := range requests {
+		if n > maxIDsPerRequest {
+			t.Errorf("expected at most %d IDs per request, got %d", maxIDsPerRequest, n)
+		}
+	}
+	if maxInflight > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxInflight)
+	}
+	if len(resp.Data) != 232 {
+		t.Fatalf("expected 232 users, got %d", len(resp.Data))
+	}
+	for i, id := range ids {
+		if resp.Data[i].ID != id {
+			t.Fatalf("expected user %d to be %s, got %s", i, id, resp.Data[i].ID)
+		}
+	}
+	if resp.Data[230].Login != "a" || resp.Data[231].Login != "b" {
+		t.Errorf("expected logins last, got %+v", resp.Data[230:])
+	}
+}
+
+func TestGetUsers_SingleRequestKeepsPagination(t *testing.T) {
+	var requests int
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(Response[User]{Data: []User{{ID: "1"}}, Pagination: &Pagination{Cursor: "next"}})
+	})
+	defer server.Close()
+
+	resp, err := client.GetUsers(context.Background(), &GetUsersParams{IDs: []string{"1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 1 || resp.Pagination == nil || resp.Pagination.Cursor != "next" {
+		t.Errorf("expected one request with pagination, got %d requests and %+v", requests, resp.Pagination)
+	}
+}
+
+func TestGetStreams_ChunksFollowPages(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		// Each chunk's streams come back in two pages
+		ids := r.URL.Query()["user_id"]
+		half := len(ids) / 2
+		resp := Response[Stream]{}
+		if r.URL.Query().Get("after") == "" {
+			ids = ids[:half]
+			resp.Pagination = &Pagination{Cursor: "page-2"}
+		} else {
+			ids = ids[half:]
+		}
+		for _, id := range ids {
+			resp.Data = append(resp.Data, Stream{ID: "stream-" + id, UserID: id})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	defer server.Close()
+
+	var ids []string
+	for i := 0; i < 150; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+	resp, err := client.GetStreams(context.Background(), &GetStreamsParams{UserIDs: ids})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("expected 2 pages for each of 2 chunks, got %d requests", requests)
+	}
+	if len(resp.Data) != 150 {
+		t.Fatalf("expected 150 streams, got %d", len(resp.Data))
+	}
+	for i, id := range ids {
+		if resp.Data[i].UserID != id {
+			t.Fatalf("expected stream %d to be %s, got %s", i, id, resp.Data[i].UserID)
+		}
+	}
+	if resp.Pagination != nil {
+		t.Errorf("expected no cursor, got %+v", resp.Pagination)
+	}
+
+	_, err = client.GetStreams(context.Background(), &GetStreamsParams{UserIDs: ids, PaginationParams: &PaginationParams{After: "cursor"}})
+	if !errors.Is(err, ErrChunkedCursor) {
+		t.Errorf("expected ErrChunkedCursor, got %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("expected no requests with a cursor, got %d", requests)
+	}
+}
+
+func TestGetStreams_ChunksStopAtFirst(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		// Every request has another page, and the first page of each holds the
+		// same stream
+		game := r.URL.Query()["game_id"][0]
+		page := r.URL.Query().Get("after")
+		resp := Response[Stream]{Pagination: &Pagination{Cursor: page + "x"}}
+		if page == "" {
+			resp.Data = append(resp.Data, Stream{ID: "shared"})
+		}
+		for i := 0; i < 10; i++ {
+			resp.Data = append(resp.Data, Stream{ID: fmt.Sprintf("%s-%s-%d", game, page, i)})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	defer server.Close()
+
+	var games []string
+	for i := 0; i < 101; i++ {
+		games = append(games, fmt.Sprint(i))
+	}
+	resp, err := client.GetStreams(context.Background(), &GetStreamsParams{GameIDs: games, PaginationParams: &PaginationParams{First: 50}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 50 {
+		t.Errorf("expected First to cap the result at 50 streams, got %d", len(resp.Data))
+	}
+	if requests != 6 {
+		t.Errorf("expected paging to stop after 3 pages of 2 chunks, got %d requests", requests)
+	}
+	seen := make(map[string]bool)
+	for _, s := range resp.Data {
+		if seen[s.ID] {
+			t.Errorf("expected stream %s once", s.ID)
+		}
+		seen[s.ID] = true
+	}
+	if !seen["shared"] {
+		t.Error("expected the shared stream to be kept")
+	}
+}
+
+func TestDeleteVideos_Chunks(t *testing.T) {
+	var mu sync.Mutex
+	var batches []string
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("expected DELETE, got %s", r.Method)
+		}
+		ids := r.URL.Query()["id"]
+		mu.Lock()
+		batches = append(batches, strings.Join(ids, ","))
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(Response[string]{Data: ids})
+	})
+	defer server.Close()
+
+	ids := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"}
+	deleted, err := client.DeleteVideos(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batches) != 3 {
+		t.Errorf("expected 3 requests, got %v", batches)
+	}
+	if strings.Join(deleted, ",") != strings.Join(ids, ",") {
+		t.Errorf("expected deleted IDs in order, got %v", deleted)
+	}
+}
+
+func TestGetClipsDownload_ChunkError(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") == "c10" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"Not Found","status":404,"message":"clip not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response[ClipDownload]{})
+	}, WithChunkConcurrency(1))
+	defer server.Close()
+
+	var ids []string
+	for i := 0; i < 30; i++ {
+		ids = append(ids, fmt.Sprintf("c%d", i))
+	}
+	_, err := client.GetClipsDownload(context.Background(), ids)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker for an endpoint group.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through and counts failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests until the cool-down has passed.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial requests through.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitOpenError is returned without sending the request when the circuit
+// for the request's endpoint group is open.
+type CircuitOpenError struct {
+	Group      string        // Endpoint group, e.g. "/moderation"
+	RetryAfter time.Duration // Time until a trial request is allowed
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for %s: retry in %v", e.Group, e.RetryAfter.Round(time.Second))
+}
+
+// IsCircuitOpenError returns true if the error is a circuit open error.
+func IsCircuitOpenError(err error) bool {
+	var circuitErr *CircuitOpenError
+	return errors.As(err, &circuitErr)
+}
+
+// CircuitBreakerOptions configures a CircuitBreaker.
+type CircuitBreakerOptions struct {
+	FailureRatio     float64       // Failure ratio within a window that opens the circuit (default: 0.5)
+	MinRequests      int           // Requests needed in a window before the ratio applies (default: 10)
+	Window           time.Duration // Length of the window failures are counted over (default: 30s)
+	CoolDown         time.Duration // Time the circuit stays open before trial requests (default: 30s)
+	HalfOpenRequests int           // Trial requests that must all succeed to close the circuit (default: 1)
+
+	// Group maps a request to its endpoint group (default: the first path segment, e.g. "/moderation").
+	Group func(req *Request) string
+	// IsFailure reports whether a result counts as a failure (default: 5xx responses and transport errors).
+	IsFailure func(resp *MiddlewareResponse, err error) bool
+	// OnStateChange is called when a group's circuit changes state. It is called
+	// synchronously with the breaker locked and must not call the breaker's methods.
+	OnStateChange func(group string, from, to CircuitState)
+}
+
+// CircuitBreaker stops sending requests to an endpoint group while it is failing,
+// failing fast with a *CircuitOpenError instead. Each group has its own circuit.
+// Install it with CircuitBreakerMiddleware.
+type CircuitBreaker struct {
+	opts     CircuitBreakerOptions
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of one endpoint group.
+type circuit struct {
+	state       CircuitState
+	generation  uint64 // Incremented on each state change; stale results are ignored
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	trials      int // Trial requests started while half-open
+	successes   int // Trial requests succeeded while half-open
+}
+
+// NewCircuitBreaker creates a circuit breaker. Zero options take their defaults.
+func NewCircuitBreaker(opts *CircuitBreakerOptions) *CircuitBreaker {
+	b := &CircuitBreaker{circuits: make(map[string]*circuit)}
+	if opts != nil {
+		b.opts = *opts
+	}
+	if b.opts.FailureRatio <= 0 {
+		b.opts.FailureRatio = 0.5
+	}
+	if b.opts.MinRequests <= 0 {
+		b.opts.MinRequests = 10
+	}
+	if b.opts.Window <= 0 {
+		b.opts.Window = 30 * time.Second
+	}
+	if b.opts.CoolDown <= 0 {
+		b.opts.CoolDown = 30 * time.Second
+	}
+	if b.opts.HalfOpenRequests <= 0 {
+		b.opts.HalfOpenRequests = 1
+	}
+	if b.opts.Group == nil {
+		b.opts.Group = endpointGroup
+	}
+	if b.opts.IsFailure == nil {
+		b.opts.IsFailure = isCircuitFailure
+	}
+	return b
+}
+
+// CircuitBreakerMiddleware creates middleware that applies breaker to requests.
+func CircuitBreakerMiddleware(breaker *CircuitBreaker) Middleware {
+	return func(ctx context.Context, req *Request, next MiddlewareNext) (*MiddlewareResponse, error) {
+		group := breaker.opts.Group(req)
+		generation, err := breaker.allow(group)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next(ctx, req)
+
+		// A request abandoned by its caller says nothing about the endpoint's health
+		abandoned := err != nil && ctx.Err() != nil
+		breaker.record(group, generation, abandoned, !abandoned && breaker.opts.IsFailure(resp, err))
+		return resp, err
+	}
+}
+
+// State returns the circuit state for an endpoint group.
+func (b *CircuitBreaker) State(group string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[group]; ok {
+		b.advance(group, c, time.Now())
+		return c.state
+	}
+	return CircuitClosed
+}
+
+// States returns the circuit state of every endpoint group that has seen requests.
+func (b *CircuitBreaker) States() map[string]CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	states := make(map[string]CircuitState, len(b.circuits))
+	for group, c := range b.circuits {
+		b.advance(group, c, now)
+		states[group] = c.state
+	}
+	return states
+}
+
+// Healthy reports whether no circuit is open, for use in health checks.
+func (b *CircuitBreaker) Healthy() bool {
+	for _, state := range b.States() {
+		if state == CircuitOpen {
+			return false
+		}
+	}
+	return true
+}
+
+// Reset closes the circuit for an endpoint group.
+func (b *CircuitBreaker) Reset(group string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[group]; ok {
+		b.transition(group, c, CircuitClosed, time.Now())
+	}
+}
+
+// allow reports whether a request to group may be sent, returning the circuit
+// generation to record its result against.
+func (b *CircuitBreaker) allow(group string) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	c, ok := b.circuits[group]
+	if !ok {
+		c = &circuit{windowStart: now}
+		b.circuits[group] = c
+	}
+	b.advance(group, c, now)
+
+	switch c.state {
+	case CircuitOpen:
+		return 0, &CircuitOpenError{Group: group, RetryAfter: c.openedAt.Add(b.opts.CoolDown).Sub(now)}
+	case CircuitHalfOpen:
+		if c.trials >= b.opts.HalfOpenRequests {
+			return 0, &CircuitOpenError{Group: group}
+		}
+		c.trials++
+	}
+	return c.generation, nil
+}
+
+// record applies the result of a request allowed at the given generation.
+func (b *CircuitBreaker) record(group string, generation uint64, abandoned, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[group]
+	if c.generation != generation {
+		return
+	}
+	now := time.Now()
+
+	switch c.state {
+	case CircuitClosed:
+		if abandoned {
+			return
+		}
+		c.requests++
+		if failed {
+			c.failures++
+		}
+		if c.requests >= b.opts.MinRequests && float64(c.failures)/float64(c.requests) >= b.opts.FailureRatio {
+			b.transition(group, c, CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		switch {
+		case abandoned:
+			c.trials-- // Let another trial through
+		case failed:
+			b.transition(group, c, CircuitOpen, now)
+		default:
+			c.successes++
+			if c.successes >= b.opts.HalfOpenRequests {
+				b.transition(group, c, CircuitClosed, now)
+			}
+		}
+	}
+}
+
+// advance applies time-based transitions: window rollover and the end of the cool-down
+// (must be called with lock held).
+func (b *CircuitBreaker) advance(group string, c *circuit, now time.Time) {
+	switch c.state {
+	case CircuitClosed:
+		if now.Sub(c.windowStart) >= b.opts.Window {
+			c.windowStart = now
+			c.requests = 0
+			c.failures = 0
+		}
+	case CircuitOpen:
+		if now.Sub(c.openedAt) >= b.opts.CoolDown {
+			b.transition(group, c, CircuitHalfOpen, now)
+		}
+	}
+}
+
+// transition moves a circuit to a new state (must be called with lock held).
+func (b *CircuitBreaker) transition(group string, c *circuit, to CircuitState, now time.Time) {
+	from := c.state
+	c.state = to
+	c.generation++
+	c.trials = 0
+	c.successes = 0
+	switch to {
+	case CircuitOpen:
+		c.openedAt = now
+	case CircuitClosed:
+		c.windowStart = now
+		c.requests = 0
+		c.failures = 0
+	}
+
+	if from != to && b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(group, from, to)
+	}
+}
+
+// endpointGroup returns the first path segment of the request endpoint.
+func endpointGroup(req *Request) string {
+	endpoint := strings.TrimPrefix(req.Endpoint, "/")
+	if i := strings.IndexByte(endpoint, '/'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	return "/" + endpoint
+}
+
+// isCircuitFailure reports whether a result indicates the endpoint is unhealthy:
+// a 5xx response or a transport error. Rate limiting, other 4xx errors and
+// responses that fail to decode say nothing about the endpoint's health.
+func isCircuitFailure(resp *MiddlewareResponse, err error) bool {
+	if err == nil || errors.Is(err, ErrRateLimited) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
@@ -0,0 +1,292 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_Opens(t *testing.T) {
+	var changes []string
+	breaker := NewCircuitBreaker(&CircuitBreakerOptions{
+		MinRequests:  4,
+		FailureRatio: 0.5,
+		CoolDown:     time.Hour,
+		OnStateChange: func(group string, from, to CircuitState) {
+			changes = append(changes, group+":"+from.String()+"->"+to.String())
+		},
+	})
+	failing := int32(1)
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) != 0 && strings.HasPrefix(r.URL.Path, "/moderation") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithMiddleware(CircuitBreakerMiddleware(breaker)))
+	defer server.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		_, _ = client.GetModerators(ctx, &GetModeratorsParams{BroadcasterID: "1"})
+	}
+	if state := breaker.State("/moderation"); state != CircuitOpen {
+		t.Fatalf("expected open circuit, got %s", state)
+	}
+
+	_, err := client.GetBannedUsers(ctx, &GetBannedUsersParams{BroadcasterID: "1"})
+	var circuitErr *CircuitOpenError
+	if !errors.As(err, &circuitErr) || circuitErr.Group != "/moderation" || circuitErr.RetryAfter <= 0 {
+		t.Errorf("expected *CircuitOpenError, got %v", err)
+	}
+	if !IsCircuitOpenError(err) {
+		t.Error("expected IsCircuitOpenError to be true")
+	}
+	if requests != 4 {
+		t.Errorf("expected request to fail fast, got %d requests", requests)
+	}
+
+	// Other groups are unaffected
+	if _, err := client.GetUsers(ctx, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if breaker.Healthy() {
+		t.Error("expected breaker to be unhealthy")
+	}
+	states := breaker.States()
+	if states["/moderation"] != CircuitOpen || states["/users"] != CircuitClosed {
+		t.Errorf("unexpected states: %v", states)
+	}
+	if len(changes) != 1 || changes[0] != "/moderation:closed->open" {
+		t.Errorf("unexpected state changes: %v", changes)
+	}
+}
+
+func TestCircuitBreaker_BelowRatio(t *testing.T) {
+	breaker := NewCircuitBreaker(&CircuitBreakerOptions{MinRequests: 4, FailureRatio: 0.75})
+	var failing, requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) != 0 && strings.HasPrefix(r.URL.Path, "/moderation") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithMiddleware(CircuitBreakerMiddleware(breaker)))
+	defer server.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 8; i++ {
+		atomic.StoreInt32(&failing, int32(i%2))
+		_, _ = client.GetModerators(ctx, &GetModeratorsParams{BroadcasterID: "1"})
+	}
+	if state := breaker.State("/moderation"); state != CircuitClosed {
+		t.Errorf("expected closed circuit at 50%% failures, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_ClientErrorsIgnored(t *testing.T) {
+	breaker := NewCircuitBreaker(&CircuitBreakerOptions{MinRequests: 2})
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}, WithMiddleware(CircuitBreakerMiddleware(breaker)))
+	defer server.Close()
+
+	for i := 0; i < 5; i++ {
+		_, _ = client.GetUsers(context.Background(), nil)
+	}
+	if state := breaker.State("/users"); state != CircuitClosed {
+		t.Errorf("expected 4xx errors not to open the circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_ExcludedErrors(t *testing.T) {
+	t.Run("rate limited", func(t *testing.T) {
+		breaker := NewCircuitBreaker(&CircuitBreakerOptions{MinRequests: 2})
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}, WithRetry(false, 0), WithMiddleware(CircuitBreakerMiddleware(breaker)))
+		defer server.Close()
+
+		for i := 0; i < 3; i++ {
+			if _, err := client.GetUsers(context.Background(), nil); !errors.Is(err, ErrRateLimited) {
+				t.Fatalf("expected ErrRateLimited, got %v", err)
+			}
+		}
+		if state := breaker.State("/users"); state != CircuitClosed {
+			t.Errorf("expected rate limiting not to open the circuit, got %s", state)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		breaker := NewCircuitBreaker(&CircuitBreakerOptions{MinRequests: 2})
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+		}, WithMiddleware(CircuitBreakerMiddleware(breaker)))
+		defer server.Close()
+
+		for i := 0; i < 3; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+			_, err := client.GetUsers(ctx, nil)
+			cancel()
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected deadline exceeded, got %v", err)
+			}
+		}
+		if state := breaker.State("/users"); state != CircuitClosed {
+			t.Errorf("expected cancelled requests not to open the circuit, got %s", state)
+		}
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		breaker := NewCircuitBreaker(&CircuitBreakerOptions{MinRequests: 2})
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"data":`))
+		}, WithMiddleware(CircuitBreakerMiddleware(breaker)))
+		defer server.Close()
+
+		for i := 0; i < 3; i++ {
+			if _, err := client.GetUsers(context.Background(), nil); err == nil {
+				t.Fatal("expected decode error")
+			}
+		}
+		if state := breaker.State("/users"); state != CircuitClosed {
+			t.Errorf("expected decode errors not to open the circuit, got %s", state)
+		}
+	})
+}
+
+func TestCircuitBreaker_TransportErrors(t *testing.T) {
+	breaker := NewCircuitBreaker(&CircuitBreakerOptions{MinRequests: 2, CoolDown: time.Hour})
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {},
+		WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		})}),
+		WithMiddleware(CircuitBreakerMiddleware(breaker)),
+	)
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		_, _ = client.GetUsers(context.Background(), nil)
+	}
+	if state := breaker.State("/users"); state != CircuitOpen {
+		t.Errorf("expected transport errors to open the circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	breaker := NewCircuitBreaker(&CircuitBreakerOptions{
+		MinRequests:      2,
+		CoolDown:         20 * time.Millisecond,
+		HalfOpenRequests: 2,
+	})
+	failing := int32(1)
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) != 0 && strings.HasPrefix(r.URL.Path, "/moderation") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithMiddleware(CircuitBreakerMiddleware(breaker)))
+	defer server.Close()
+	ctx := context.Background()
+	params := &GetModeratorsParams{BroadcasterID: "1"}
+
+	_, _ = client.GetModerators(ctx, params)
+	_, _ = client.GetModerators(ctx, params)
+	if breaker.State("/moderation") != CircuitOpen {
+		t.Fatal("expected open circuit")
+	}
+
+	// A failed trial reopens the circuit
+	time.Sleep(30 * time.Millisecond)
+	if state := breaker.State("/moderation"); state != CircuitHalfOpen {
+		t.Fatalf("expected half-open circuit, got %s", state)
+	}
+	_, _ = client.GetModerators(ctx, params)
+	if state := breaker.State("/moderation"); state != CircuitOpen {
+		t.Fatalf("expected failed trial to reopen the circuit, got %s", state)
+	}
+
+	// Successful trials close it
+	atomic.StoreInt32(&failing, 0)
+	time.Sleep(30 * time.Millisecond)
+	_, _ = client.GetModerators(ctx, params)
+	if state := breaker.State("/moderation"); state != CircuitHalfOpen {
+		t.Fatalf("expected circuit to stay half-open until all trials succeed, got %s", state)
+	}
+	_, _ = client.GetModerators(ctx, params)
+	if state := breaker.State("/moderation"); state != CircuitClosed {
+		t.Errorf("expected closed circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenLimitsTrials(t *testing.T) {
+	breaker := NewCircuitBreaker(&CircuitBreakerOptions{MinRequests: 1, CoolDown: time.Millisecond})
+	group := "/moderation"
+
+	gen, _ := breaker.allow(group)
+	breaker.record(group, gen, false, true)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := breaker.allow(group); err != nil {
+		t.Fatalf("expected trial to be allowed, got %v", err)
+	}
+	if _, err := breaker.allow(group); !IsCircuitOpenError(err) {
+		t.Errorf("expected second concurrent trial to be rejected, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_WindowAndReset(t *testing.T) {
+	breaker := NewCircuitBreaker(&CircuitBreakerOptions{MinRequests: 2, Window: 10 * time.Millisecond})
+	group := "/streams"
+
+	gen, _ := breaker.allow(group)
+	breaker.record(group, gen, false, true)
+	time.Sleep(20 * time.Millisecond)
+
+	// The earlier failure fell out of the window
+	gen, _ = breaker.allow(group)
+	breaker.record(group, gen, false, true)
+	if state := breaker.State(group); state != CircuitClosed {
+		t.Errorf("expected closed circuit, got %s", state)
+	}
+
+	gen, _ = breaker.allow(group)
+	breaker.record(group, gen, false, true)
+	if state := breaker.State(group); state != CircuitOpen {
+		t.Fatalf("expected open circuit, got %s", state)
+	}
+	breaker.Reset(group)
+	if state := breaker.State(group); state != CircuitClosed {
+		t.Errorf("expected reset to close the circuit, got %s", state)
+	}
+}
+
+func TestEndpointGroup(t *testing.T) {
+	tests := map[string]string{
+		"/moderation/bans": "/moderation",
+		"/users":           "/users",
+		"channels/vips":    "/channels",
+	}
+	for endpoint, want := range tests {
+		if got := endpointGroup(&Request{Endpoint: endpoint}); got != want {
+			t.Errorf("endpointGroup(%q) = %q, want %q", endpoint, got, want)
+		}
+	}
+}
+
+func TestCircuitState_String(t *testing.T) {
+	if CircuitHalfOpen.String() != "half-open" || CircuitState(9).String() != "CircuitState(9)" {
+		t.Error("unexpected state names")
+	}
+}
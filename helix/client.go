@@ -5,8 +5,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"sync"
@@ -26,6 +28,19 @@ type TokenProvider interface {
 	GetToken() *Token
 }
 
+// ContextTokenProvider is a TokenProvider that can choose the token for each
+// request based on its context (see TokenManager and WithUserContext).
+type ContextTokenProvider interface {
+	TokenProvider
+	GetTokenForContext(ctx context.Context) *Token
+}
+
+// tokenRefresher is implemented by token sources that can replace a token
+// rejected by the API (AuthClient and TokenManager).
+type tokenRefresher interface {
+	refreshExpiredToken(ctx context.Context, staleAccessToken string) (*Token, error)
+}
+
 // Client is a Twitch Helix API client.
 type Client struct {
 	clientID      string
@@ -38,6 +53,7 @@ type Client struct {
 	rateLimitRemaining int       // Points remaining in bucket
 	rateLimitReset     time.Time // When bucket resets
 	rateMu             sync.Mutex
+	rateLimiter        *RateLimiter // Optional proactive limiter (may be shared)
 
 	// Retry configuration
 	maxRetries     int           // Maximum retries on 429 (default: 3)
@@ -45,14 +61,42 @@ type Client struct {
 	maxRetryWait   time.Duration // Maximum wait time for retry (default: 60s)
 	baseRetryDelay time.Duration // Base delay for exponential backoff (default: 1s)
 	useExpBackoff  bool          // Use exponential backoff instead of reset time (default: false)
+	refreshOn401   bool          // Refresh the token and replay once on 401 invalid token (default: true)
+	retryPolicy    *RetryPolicy  // Retries of 5xx and network errors (default: nil, disabled)
+	retryBudget    *retryBudget
 
 	// Middleware
 	middleware []Middleware
 
+	// Instrumentation and logging (default: none)
+	instr  Instrumentation
+	logger *slog.Logger
+
+	// Scope validation
+	scopeValidation bool
+	scopeCache      map[string]*tokenScopes // Validated scopes by token hash
+	scopeMu         sync.Mutex
+
+	// Request coalescing
+	coalesceEnabled bool
+	inflight        map[string]*inflightCall // In-flight GET requests by cache key
+	inflightMu      sync.Mutex
+
+	// Request chunking
+	chunkConcurrency int // Concurrent requests when splitting oversized ID lists (default: 4)
+
 	// Cache
-	cache        Cache
-	cacheTTL     time.Duration
-	cacheEnabled bool
+	cache                Cache
+	cacheTTL             time.Duration
+	cacheTTLs            map[string]time.Duration // Per-endpoint TTL overrides
+	cacheEnabled         bool
+	staleWhileRevalidate time.Duration // Serve expired entries while refreshing (0 = disabled)
+	staleIfError         time.Duration // Serve expired entries on 5xx errors (0 = disabled)
+	revalidating         map[string]bool
+	revalidateMu         sync.Mutex
+	cacheInvalidation    bool                            // Invalidate related entries after mutations (default: true)
+	cacheIndex           map[string]map[string]time.Time // Cache keys by "endpoint|broadcaster_id", with when they leave the cache
+	cacheIndexMu         sync.Mutex
 
 	// Base URL (can be overridden for testing)
 	baseURL string
@@ -85,6 +129,15 @@ func WithIngestBaseURL(url string) Option {
 	}
 }
 
+// WithTokenProvider sets a token provider used instead of an AuthClient.
+// If the provider implements ContextTokenProvider, the token is chosen per request.
+func WithTokenProvider(provider TokenProvider) Option {
+	return func(c *Client) {
+		c.authClient = nil
+		c.tokenProvider = provider
+	}
+}
+
 // WithRetry configures retry behavior for rate-limited requests.
 func WithRetry(enabled bool, maxRetries int) Option {
 	return func(c *Client) {
@@ -109,6 +162,16 @@ func WithExponentialBackoff(baseDelay time.Duration) Option {
 	}
 }
 
+// WithTokenRefresh configures whether a request rejected with 401 because of an
+// invalid or expired token is replayed once after refreshing the token through the
+// client's AuthClient or TokenManager. Enabled by default; it has no effect
+// without a refresh token.
+func WithTokenRefresh(enabled bool) Option {
+	return func(c *Client) {
+		c.refreshOn401 = enabled
+	}
+}
+
 // WithMiddleware adds middleware to the client.
 func WithMiddleware(mw ...Middleware) Option {
 	return func(c *Client) {
@@ -116,6 +179,22 @@ func WithMiddleware(mw ...Middleware) Option {
 	}
 }
 
+// WithInstrumentation sets the instrumentation that receives a span and a counter
+// for each HTTP attempt and a counter for each retry.
+func WithInstrumentation(instr Instrumentation) Option {
+	return func(c *Client) {
+		c.instr = instr
+	}
+}
+
+// WithLogger sets the logger for retries, token refreshes and rate limit waits.
+// Access tokens and other secrets are redacted from its output.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = newLogger(logger)
+	}
+}
+
 // NewClient creates a new Helix API client.
 func NewClient(clientID string, authClient *AuthClient, opts ...Option) *Client {
 	c := &Client{
@@ -130,7 +209,9 @@ func NewClient(clientID string, authClient *AuthClient, opts ...Option) *Client
 		maxRetries:         3,
 		maxRetryWait:       60 * time.Second,
 		baseRetryDelay:     time.Second,
+		refreshOn401:       true,
 		cacheTTL:           5 * time.Minute,
+		cacheInvalidation:  true,
 	}
 
 	for _, opt := range opts {
@@ -169,23 +250,34 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
-// APIError represents a Twitch API error.
+// APIError represents a Twitch API error. It matches the sentinel errors in errors.go
+// with errors.Is, e.g. errors.Is(err, ErrNotFound).
 type APIError struct {
 	StatusCode int
 	ErrorType  string
 	Message    string
+
+	Method    string        // Method of the request that failed
+	Endpoint  string        // Endpoint of the request that failed, e.g. "/moderation/bans"
+	RateLimit RateLimitInfo // Rate limit state after the response
 }
 
 func (e *APIError) Error() string {
+	if e.Endpoint != "" {
+		return fmt.Sprintf("twitch api error %d (%s %s): %s - %s", e.StatusCode, e.Method, e.Endpoint, e.ErrorType, e.Message)
+	}
 	return fmt.Sprintf("twitch api error %d: %s - %s", e.StatusCode, e.ErrorType, e.Message)
 }
 
 // RateLimitError is returned when the API rate limit is exceeded and retries are exhausted.
+// It matches ErrRateLimited with errors.Is.
 type RateLimitError struct {
 	ResetAt    time.Time     // When the rate limit resets
 	Remaining  int           // Points remaining (usually 0)
 	Limit      int           // Total bucket size
 	RetryAfter time.Duration // How long until reset
+	Method     string        // Method of the request that was rate limited
+	Endpoint   string        // Endpoint of the request that was rate limited
 }
 
 func (e *RateLimitError) Error() string {
@@ -194,49 +286,110 @@ func (e *RateLimitError) Error() string {
 
 // IsRateLimitError returns true if the error is a rate limit error.
 func IsRateLimitError(err error) bool {
-	_, ok := err.(*RateLimitError)
-	return ok
+	var rateLimitErr *RateLimitError
+	return errors.As(err, &rateLimitErr)
+}
+
+// currentToken returns the token used to authorize a request with the given context, if any.
+func (c *Client) currentToken(ctx context.Context) *Token {
+	if c.authClient != nil {
+		return c.authClient.GetToken()
+	}
+	if p, ok := c.tokenProvider.(ContextTokenProvider); ok {
+		return p.GetTokenForContext(ctx)
+	}
+	if c.tokenProvider != nil {
+		return c.tokenProvider.GetToken()
+	}
+	return nil
+}
+
+// refresher returns the token source able to refresh rejected tokens, if any.
+func (c *Client) refresher() tokenRefresher {
+	if c.authClient != nil {
+		return c.authClient
+	}
+	if r, ok := c.tokenProvider.(tokenRefresher); ok {
+		return r
+	}
+	return nil
 }
 
 // cacheKey generates a cache key that includes base URL and token hash
 // to prevent cache pollution across different clients or tokens.
-func (c *Client) cacheKey(endpoint, query string) string {
+func (c *Client) cacheKey(ctx context.Context, endpoint, query string) string {
 	tokenHash := ""
-	if c.authClient != nil {
-		if token := c.authClient.GetToken(); token != nil {
-			tokenHash = TokenHash(token.AccessToken)
-		}
-	} else if c.tokenProvider != nil {
-		if token := c.tokenProvider.GetToken(); token != nil {
-			tokenHash = TokenHash(token.AccessToken)
-		}
+	if token := c.currentToken(ctx); token != nil {
+		tokenHash = TokenHash(token.AccessToken)
 	}
 	return CacheKeyWithContext(c.baseURL, endpoint, query, tokenHash)
 }
 
 // Do executes an API request with automatic retry on rate limit (429).
 func (c *Client) Do(ctx context.Context, req *Request, result interface{}) error {
+	// Check the token's scopes before sending
+	if c.scopeValidation {
+		if err := c.checkScopes(ctx, req); err != nil {
+			return err
+		}
+	}
+
 	// Check cache for GET requests
+	var stale []byte
 	if c.cacheEnabled && c.cache != nil && req.Method == http.MethodGet && !shouldSkipCache(ctx) {
-		key := c.cacheKey(req.Endpoint, req.Query.Encode())
-		if cached := c.cache.Get(ctx, key); cached != nil {
-			if result != nil {
-				return json.Unmarshal(cached, result)
-			}
-			return nil
+		key := c.cacheKey(ctx, req.Endpoint, req.Query.Encode())
+		cached, age := c.getCached(ctx, key)
+		switch {
+		case cached == nil:
+		case age <= 0:
+			return decodeCached(cached, result)
+		case age <= c.staleWhileRevalidate:
+			c.revalidate(ctx, key, req)
+			return decodeCached(cached, result)
+		case age <= c.staleIfError:
+			stale = cached
 		}
 	}
 
-	// Execute with middleware if configured
+	err := c.send(ctx, req, result)
+	if err != nil && stale != nil && isServerError(err) {
+		return decodeCached(stale, result)
+	}
+	if err == nil {
+		c.invalidateRelated(ctx, req)
+	}
+	return err
+}
+
+// send executes a request that was not answered from the cache.
+func (c *Client) send(ctx context.Context, req *Request, result interface{}) error {
+	// Share a single round trip between identical concurrent GET requests
+	if c.coalesceEnabled && req.Method == http.MethodGet {
+		return c.doCoalesced(ctx, req, result)
+	}
+
+	_, err := c.execute(ctx, req, result)
+	return err
+}
+
+// decodeCached decodes a cached response body into result.
+func decodeCached(cached []byte, result interface{}) error {
+	if result != nil {
+		return json.Unmarshal(cached, result)
+	}
+	return nil
+}
+
+// execute runs a request through the middleware chain (if any) and retry logic.
+func (c *Client) execute(ctx context.Context, req *Request, result interface{}) (*MiddlewareResponse, error) {
 	if len(c.middleware) > 0 {
 		return c.doWithMiddleware(ctx, req, result)
 	}
-
-	return c.doWithRetry(ctx, req, result)
+	return c.doWithRetryAndResponse(ctx, req, result)
 }
 
 // doWithMiddleware executes request through the middleware chain.
-func (c *Client) doWithMiddleware(ctx context.Context, req *Request, result interface{}) error {
+func (c *Client) doWithMiddleware(ctx context.Context, req *Request, result interface{}) (*MiddlewareResponse, error) {
 	// Build middleware chain
 	var chain MiddlewareNext
 	chain = func(ctx context.Context, req *Request) (*MiddlewareResponse, error) {
@@ -253,29 +406,50 @@ func (c *Client) doWithMiddleware(ctx context.Context, req *Request, result inte
 		}
 	}
 
-	_, err := chain(ctx, req)
-	return err
-}
-
-// doWithRetry executes a request with retry logic.
-func (c *Client) doWithRetry(ctx context.Context, req *Request, result interface{}) error {
-	_, err := c.doWithRetryAndResponse(ctx, req, result)
-	return err
+	return chain(ctx, req)
 }
 
 // doWithRetryAndResponse executes a request with retry logic and returns response info for middleware.
 func (c *Client) doWithRetryAndResponse(ctx context.Context, req *Request, result interface{}) (*MiddlewareResponse, error) {
 	var lastErr error
 	var lastResp *MiddlewareResponse
+	refreshed := false
+	transientRetries := 0
+	sent := 0 // Attempts sent, including replays and retries of every kind
+	logger := loggerOrDiscard(c.logger)
+
+	if c.retryBudget != nil {
+		c.retryBudget.deposit()
+	}
 
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		resp, err := c.doOnceWithResponse(ctx, req, result)
+		accessToken := ""
+		if token := c.currentToken(ctx); token != nil {
+			accessToken = token.AccessToken
+		}
+
+		resp, err := c.doAttempt(ctx, req, result, sent)
+		sent++
 		if err == nil {
 			return resp, nil
 		}
 
 		lastResp = resp
 
+		// Refresh an expired token once and replay the request
+		if !refreshed && c.shouldRefreshToken(ctx, err) {
+			refreshed = true
+			logger.InfoContext(ctx, "refreshing token after 401", "method", req.Method, "endpoint", req.Endpoint)
+			// A token that could not be persisted is still usable for the replay
+			if _, refreshErr := c.refresher().refreshExpiredToken(ctx, accessToken); refreshErr != nil && !errors.Is(refreshErr, ErrTokenNotPersisted) {
+				logger.WarnContext(ctx, "token refresh failed", "endpoint", req.Endpoint, "error", refreshErr)
+				return lastResp, err
+			}
+			c.countRetry(ctx, req, "token_refresh")
+			attempt-- // The replay does not count as a rate limit retry
+			continue
+		}
+
 		// Check if it's a rate limit error
 		if apiErr, ok := err.(*APIError); ok && apiErr.StatusCode == http.StatusTooManyRequests {
 			if !c.retryEnabled || attempt >= c.maxRetries {
@@ -290,12 +464,16 @@ func (c *Client) doWithRetryAndResponse(ctx context.Context, req *Request, resul
 				if retryAfter < 0 {
 					retryAfter = 0
 				}
+				logger.WarnContext(ctx, "rate limited, not retrying", "method", req.Method, "endpoint", req.Endpoint,
+					"attempt", attempt, "retry_after", retryAfter)
 
 				return lastResp, &RateLimitError{
 					ResetAt:    resetAt,
 					Remaining:  remaining,
 					Limit:      limit,
 					RetryAfter: retryAfter,
+					Method:     req.Method,
+					Endpoint:   req.Endpoint,
 				}
 			}
 
@@ -321,6 +499,8 @@ func (c *Client) doWithRetryAndResponse(ctx context.Context, req *Request, resul
 			}
 
 			// Wait before retry
+			logger.WarnContext(ctx, "rate limited, waiting to retry", "method", req.Method, "endpoint", req.Endpoint,
+				"attempt", attempt, "wait", waitTime)
 			select {
 			case <-ctx.Done():
 				return lastResp, ctx.Err()
@@ -329,16 +509,82 @@ func (c *Client) doWithRetryAndResponse(ctx context.Context, req *Request, resul
 			}
 
 			lastErr = err
+			c.countRetry(ctx, req, "rate_limit")
 			continue
 		}
 
-		// Not a rate limit error, return immediately
+		// Retry transient failures (5xx, timeouts, connection errors) per the retry policy
+		if c.retryPolicy != nil && transientRetries < c.retryPolicy.MaxRetries && c.retryPolicy.shouldRetry(ctx, req, err) {
+			if waitTime, ok := c.retryPolicy.delay(transientRetries, resp); ok && c.retryBudget.withdraw() {
+				transientRetries++
+				logger.WarnContext(ctx, "retrying request", "method", req.Method, "endpoint", req.Endpoint,
+					"attempt", transientRetries, "wait", waitTime, "error", err)
+				select {
+				case <-ctx.Done():
+					return lastResp, ctx.Err()
+				case <-time.After(waitTime):
+				}
+				c.countRetry(ctx, req, "transient")
+				attempt-- // Transient retries do not count as rate limit retries
+				continue
+			}
+		}
+
+		// Not retryable, return immediately
 		return lastResp, err
 	}
 
 	return lastResp, lastErr
 }
 
+// doAttempt sends one HTTP attempt of a request, recording it with the client's instrumentation.
+// sent is the number of earlier attempts of the same request.
+func (c *Client) doAttempt(ctx context.Context, req *Request, result interface{}, sent int) (*MiddlewareResponse, error) {
+	instr := instrumentationOrNop(c.instr)
+	ctx, span := instr.StartSpan(ctx, SpanHelixRequest,
+		StringAttr(AttrMethod, req.Method),
+		StringAttr(AttrEndpoint, req.Endpoint),
+		IntAttr(AttrRetryAttempt, sent))
+
+	resp, err := c.doOnceWithResponse(ctx, req, result)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	span.SetAttributes(IntAttr(AttrStatus, status), IntAttr(AttrRateLimitRemaining, c.GetRateLimitInfo().Remaining))
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+	instr.AddCounter(ctx, CounterHelixRequests, 1,
+		StringAttr(AttrMethod, req.Method),
+		StringAttr(AttrEndpoint, req.Endpoint),
+		IntAttr(AttrStatus, status))
+
+	return resp, err
+}
+
+// countRetry records a retry of req for the given reason.
+func (c *Client) countRetry(ctx context.Context, req *Request, reason string) {
+	instrumentationOrNop(c.instr).AddCounter(ctx, CounterHelixRetries, 1,
+		StringAttr(AttrMethod, req.Method),
+		StringAttr(AttrEndpoint, req.Endpoint),
+		StringAttr(AttrRetryReason, reason))
+}
+
+// shouldRefreshToken reports whether err is a 401 caused by an invalid or expired
+// token that a refresh through the AuthClient or TokenManager could fix.
+func (c *Client) shouldRefreshToken(ctx context.Context, err error) bool {
+	if !c.refreshOn401 || c.refresher() == nil {
+		return false
+	}
+	if token := c.currentToken(ctx); token == nil || token.RefreshToken == "" {
+		return false
+	}
+	return errors.Is(err, ErrInvalidToken)
+}
+
 // doOnce executes a single API request without retries.
 func (c *Client) doOnce(ctx context.Context, req *Request, result interface{}) error {
 	_, err := c.doOnceWithResponse(ctx, req, result)
@@ -383,14 +629,25 @@ func (c *Client) doOnceWithResponse(ctx context.Context, req *Request, result in
 	}
 
 	// Set authorization
-	var token *Token
-	if c.authClient != nil {
-		token = c.authClient.GetToken()
-	} else if c.tokenProvider != nil {
-		token = c.tokenProvider.GetToken()
+	accessToken := ""
+	if token := c.currentToken(ctx); token != nil {
+		accessToken = token.AccessToken
+	}
+	if accessToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+accessToken)
 	}
-	if token != nil && token.AccessToken != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	// Wait for the proactive rate limiter before sending
+	var limiterKey string
+	if c.rateLimiter != nil {
+		limiterKey = RateLimitKey(c.clientID, accessToken)
+		start := time.Now()
+		if err := c.rateLimiter.Wait(ctx, limiterKey); err != nil {
+			return nil, err
+		}
+		if waited := time.Since(start); waited >= time.Millisecond {
+			loggerOrDiscard(c.logger).DebugContext(ctx, "waited for rate limiter", "endpoint", req.Endpoint, "wait", waited)
+		}
 	}
 
 	// Execute request
@@ -402,6 +659,9 @@ func (c *Client) doOnceWithResponse(ctx context.Context, req *Request, result in
 
 	// Update rate limit info from headers
 	c.updateRateLimit(resp)
+	if c.rateLimiter != nil {
+		c.rateLimiter.Update(limiterKey, resp.Header)
+	}
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
@@ -418,19 +678,20 @@ func (c *Client) doOnceWithResponse(ctx context.Context, req *Request, result in
 
 	// Check for errors
 	if resp.StatusCode >= 400 {
-		var errResp ErrorResponse
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			return mwResp, &APIError{
-				StatusCode: resp.StatusCode,
-				ErrorType:  "unknown",
-				Message:    string(body),
-			}
-		}
-		return mwResp, &APIError{
+		apiErr := &APIError{
 			StatusCode: resp.StatusCode,
-			ErrorType:  errResp.Error,
-			Message:    errResp.Message,
+			ErrorType:  "unknown",
+			Message:    string(body),
+			Method:     req.Method,
+			Endpoint:   req.Endpoint,
+			RateLimit:  c.GetRateLimitInfo(),
+		}
+		var errResp ErrorResponse
+		if err := json.Unmarshal(body, &errResp); err == nil {
+			apiErr.ErrorType = errResp.Error
+			apiErr.Message = errResp.Message
 		}
+		return mwResp, apiErr
 	}
 
 	// Parse response
@@ -442,8 +703,7 @@ func (c *Client) doOnceWithResponse(ctx context.Context, req *Request, result in
 
 	// Cache successful GET responses
 	if c.cacheEnabled && c.cache != nil && req.Method == http.MethodGet && !shouldSkipCache(ctx) && len(body) > 0 {
-		key := c.cacheKey(req.Endpoint, req.Query.Encode())
-		c.cache.Set(ctx, key, body, c.cacheTTL)
+		c.setCached(ctx, req, body)
 	}
 
 	return mwResp, nil
@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
-func newTestClient(handler http.HandlerFunc) (*Client, *httptest.Server) {
+func newTestClient(handler http.HandlerFunc, opts ...Option) (*Client, *httptest.Server) {
 	server := httptest.NewServer(handler)
 
 	authClient := NewAuthClient(AuthConfig{
@@ -22,7 +24,7 @@ func newTestClient(handler http.HandlerFunc) (*Client, *httptest.Server) {
 		AccessToken: "test-access-token",
 	})
 
-	client := NewClient("test-client-id", authClient, WithBaseURL(server.URL))
+	client := NewClient("test-client-id", authClient, append([]Option{WithBaseURL(server.URL)}, opts...)...)
 
 	return client, server
 }
@@ -1223,3 +1225,160 @@ func TestClient_ResponseBodyReadError(t *testing.T) {
 		}
 	}
 }
+
+// newRefreshTestClient creates a client whose API server rejects "old-token" with
+// 401 and whose token endpoint issues "new-token".
+func newRefreshTestClient(t *testing.T, refreshes *int32, opts ...Option) (*Client, func()) {
+	t.Helper()
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(refreshes, 1)
+		time.Sleep(20 * time.Millisecond) // Give concurrent callers time to pile up
+		_ = json.NewEncoder(w).Encode(Token{
+			AccessToken:  "new-token",
+			RefreshToken: "new-refresh",
+			ExpiresIn:    3600,
+		})
+	}))
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "Unauthorized",
+				Status:  401,
+				Message: "Invalid OAuth token",
+			})
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"12345"}]}`))
+	}))
+
+	authClient := NewAuthClient(AuthConfig{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+	})
+	authClient.SetEndpoints(tokenServer.URL, "", "", "", "", "", "")
+	authClient.SetToken(&Token{AccessToken: "old-token", RefreshToken: "old-refresh"})
+
+	opts = append([]Option{WithBaseURL(apiServer.URL)}, opts...)
+	client := NewClient("test-client-id", authClient, opts...)
+
+	return client, func() {
+		apiServer.Close()
+		tokenServer.Close()
+	}
+}
+
+func TestClient_RefreshOn401(t *testing.T) {
+	var refreshes int32
+	client, cleanup := newRefreshTestClient(t, &refreshes)
+	defer cleanup()
+
+	resp, err := client.GetUsers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != "12345" {
+		t.Errorf("expected replayed request to return user, got %+v", resp.Data)
+	}
+	if refreshes != 1 {
+		t.Errorf("expected 1 refresh, got %d", refreshes)
+	}
+	if token := client.authClient.GetToken(); token.AccessToken != "new-token" {
+		t.Errorf("expected refreshed token to be stored, got %s", token.AccessToken)
+	}
+}
+
+func TestClient_RefreshOn401_Coalesced(t *testing.T) {
+	var refreshes int32
+	client, cleanup := newRefreshTestClient(t, &refreshes)
+	defer cleanup()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetUsers(context.Background(), nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if refreshes != 1 {
+		t.Errorf("expected concurrent failures to share 1 refresh, got %d", refreshes)
+	}
+}
+
+func TestClient_RefreshOn401_Disabled(t *testing.T) {
+	var refreshes int32
+	client, cleanup := newRefreshTestClient(t, &refreshes, WithTokenRefresh(false))
+	defer cleanup()
+
+	_, err := client.GetUsers(context.Background(), nil)
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 APIError, got %v", err)
+	}
+	if refreshes != 0 {
+		t.Errorf("expected no refresh, got %d", refreshes)
+	}
+}
+
+func TestClient_RefreshOn401_RefreshFails(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":400,"message":"Invalid refresh token"}`))
+	}))
+	defer tokenServer.Close()
+
+	var apiCalls int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&apiCalls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Unauthorized","status":401,"message":"Invalid OAuth token"}`))
+	})
+	defer server.Close()
+	client.authClient.SetEndpoints(tokenServer.URL, "", "", "", "", "", "")
+	client.authClient.SetToken(&Token{AccessToken: "old-token", RefreshToken: "revoked"})
+
+	_, err := client.GetUsers(context.Background(), nil)
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected original 401 APIError, got %v", err)
+	}
+	if apiCalls != 1 {
+		t.Errorf("expected request not to be replayed, got %d calls", apiCalls)
+	}
+}
+
+func TestClient_RefreshOn401_MissingScopeNotRefreshed(t *testing.T) {
+	var refreshes int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&refreshes, 1)
+	}))
+	defer tokenServer.Close()
+
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Unauthorized","status":401,"message":"Missing scope: moderator:manage:banned_users"}`))
+	})
+	defer server.Close()
+	client.authClient.SetEndpoints(tokenServer.URL, "", "", "", "", "", "")
+	client.authClient.SetToken(&Token{AccessToken: "token", RefreshToken: "refresh"})
+
+	if _, err := client.GetUsers(context.Background(), nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if refreshes != 0 {
+		t.Errorf("expected no refresh for missing scope, got %d", refreshes)
+	}
+}
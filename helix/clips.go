@@ -111,18 +111,10 @@ type ClipDownload struct {
 }
 
 // GetClipsDownload gets a download URL for clips.
+// More than 10 clip IDs are split across concurrent requests.
 // Requires: clips:edit scope for clips the user created, or the broadcaster's clips.
 func (c *Client) GetClipsDownload(ctx context.Context, clipIDs []string) (*Response[ClipDownload], error) {
-	q := url.Values{}
-	for _, id := range clipIDs {
-		q.Add("id", id)
-	}
-
-	var resp Response[ClipDownload]
-	if err := c.get(ctx, "/clips/download", q, &resp); err != nil {
-		return nil, err
-	}
-	return &resp, nil
+	return getChunked[ClipDownload](ctx, c, "/clips/download", url.Values{}, queryValues("id", clipIDs), maxClipDownloadIDs)
 }
 
 // CreateClipFromVODParams contains parameters for CreateClipFromVOD.
@@ -0,0 +1,84 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// inflightCall is a GET request in progress whose response is shared with
+// identical requests made while it runs.
+type inflightCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// WithRequestCoalescing configures whether identical concurrent GET requests share
+// a single HTTP round trip (default: false). Requests are considered identical when
+// they have the same cache key: base URL, endpoint, query and token. Middleware runs
+// once per shared round trip.
+func WithRequestCoalescing(enabled bool) Option {
+	return func(c *Client) {
+		c.coalesceEnabled = enabled
+	}
+}
+
+// doCoalesced executes a GET request, joining an identical in-flight request if there is one.
+// The leader decodes into its own result; followers decode the shared response body.
+func (c *Client) doCoalesced(ctx context.Context, req *Request, result interface{}) error {
+	key := c.cacheKey(ctx, req.Endpoint, req.Query.Encode())
+
+	for {
+		c.inflightMu.Lock()
+		call, ok := c.inflight[key]
+		if !ok {
+			call = &inflightCall{done: make(chan struct{})}
+			if c.inflight == nil {
+				c.inflight = make(map[string]*inflightCall)
+			}
+			c.inflight[key] = call
+			c.inflightMu.Unlock()
+
+			resp, err := c.execute(ctx, req, result)
+			if resp != nil {
+				call.body = resp.Body
+			}
+			call.err = err
+
+			c.inflightMu.Lock()
+			delete(c.inflight, key)
+			c.inflightMu.Unlock()
+			close(call.done)
+			return err
+		}
+		c.inflightMu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-call.done:
+		}
+
+		// The leader's context ended; this caller's has not, so run the request itself
+		if isContextError(call.err) && ctx.Err() == nil {
+			continue
+		}
+
+		if call.err != nil {
+			return call.err
+		}
+		if result != nil && len(call.body) > 0 {
+			if err := json.Unmarshal(call.body, result); err != nil {
+				return fmt.Errorf("parsing response: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// isContextError reports whether err was caused by a cancelled or expired context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
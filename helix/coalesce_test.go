@@ -0,0 +1,204 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Coalescing_IdenticalRequests(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"data":[{"id":"` + r.URL.Query().Get("id") + `"}]}`))
+	}, WithRequestCoalescing(true))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	results := make([]*Response[User], 10)
+	errs := make([]error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = client.GetUsers(context.Background(), &GetUsersParams{IDs: []string{"42"}})
+		}(i)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("result %d: unexpected error: %v", i, errs[i])
+		}
+		if len(results[i].Data) != 1 || results[i].Data[0].ID != "42" {
+			t.Errorf("result %d: unexpected data %+v", i, results[i].Data)
+		}
+	}
+}
+
+func TestClient_Coalescing_DifferentRequests(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"data":[{"id":"` + r.URL.Query().Get("id") + `"}]}`))
+	}, WithRequestCoalescing(true))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"1", "2", "3"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			resp, err := client.GetUsers(context.Background(), &GetUsersParams{IDs: []string{id}})
+			if err != nil || resp.Data[0].ID != id {
+				t.Errorf("unexpected result for %s: %v %v", id, resp, err)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+}
+
+func TestClient_Coalescing_DifferentUsers(t *testing.T) {
+	var requests int32
+	m := NewTokenManager(nil)
+	_ = m.SetUserToken(context.Background(), "1", &Token{AccessToken: "token-1"})
+	_ = m.SetUserToken(context.Background(), "2", &Token{AccessToken: "token-2"})
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithRequestCoalescing(true), WithTokenProvider(m))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for _, userID := range []string{"1", "2"} {
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			_, _ = client.GetUsers(WithUserContext(context.Background(), userID), nil)
+		}(userID)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected requests with different tokens not to be shared, got %d", n)
+	}
+}
+
+func TestClient_Coalescing_SharedError(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"Bad Request","status":400,"message":"bad"}`))
+	}, WithRequestCoalescing(true))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetUsers(context.Background(), nil)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected shared 400 error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestClient_Coalescing_FollowerCancelled(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"data":[{"id":"` + r.URL.Query().Get("id") + `"}]}`))
+	}, WithRequestCoalescing(true))
+	defer server.Close()
+
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := client.GetUsers(context.Background(), nil)
+		leaderDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetUsers(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected follower deadline exceeded, got %v", err)
+	}
+
+	if err := <-leaderDone; err != nil {
+		t.Errorf("expected leader to succeed, got %v", err)
+	}
+}
+
+func TestClient_Coalescing_LeaderCancelled(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"data":[{"id":"` + r.URL.Query().Get("id") + `"}]}`))
+	}, WithRequestCoalescing(true))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	go func() {
+		_, _ = client.GetUsers(ctx, nil)
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	// The follower outlives the leader's context and must run the request itself
+	if _, err := client.GetUsers(context.Background(), nil); err != nil {
+		t.Errorf("expected follower to succeed, got %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestClient_Coalescing_DisabledByDefault(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	})
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = client.GetUsers(context.Background(), nil)
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("expected 3 requests without coalescing, got %d", n)
+	}
+}
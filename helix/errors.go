@@ -0,0 +1,110 @@
+package helix
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for common Twitch API failures. An *APIError matches the sentinel
+// for its status code and any sentinel derived from its message, so callers can
+// use errors.Is instead of inspecting Message:
+//
+//	err := client.BanUser(ctx, params)
+//	switch {
+//	case errors.Is(err, helix.ErrAlreadyBanned):
+//	    // Nothing to do
+//	case errors.Is(err, helix.ErrMissingScope):
+//	    // Ask the broadcaster to re-authorize
+//	}
+//
+// Use errors.As with *APIError for the status, endpoint and rate limit snapshot.
+var (
+	// Status code errors
+	ErrBadRequest    = errors.New("twitch api: bad request")          // 400
+	ErrUnauthorized  = errors.New("twitch api: unauthorized")         // 401
+	ErrForbidden     = errors.New("twitch api: forbidden")            // 403
+	ErrNotFound      = errors.New("twitch api: not found")            // 404
+	ErrConflict      = errors.New("twitch api: conflict")             // 409
+	ErrUnprocessable = errors.New("twitch api: unprocessable entity") // 422
+	ErrRateLimited   = errors.New("twitch api: rate limited")         // 429, also matched by *RateLimitError
+	ErrServerError   = errors.New("twitch api: server error")         // 5xx
+
+	// Message errors
+	ErrMissingScope     = errors.New("twitch api: missing scope") // Also matched by *MissingScopeError
+	ErrAlreadyBanned    = errors.New("twitch api: user is already banned")
+	ErrNotBanned        = errors.New("twitch api: user is not banned")
+	ErrAlreadyModerator = errors.New("twitch api: user is already a moderator")
+	ErrNotModerator     = errors.New("twitch api: user is not a moderator")
+	ErrAlreadyVIP       = errors.New("twitch api: user is already a VIP")
+	ErrNotVIP           = errors.New("twitch api: user is not a VIP")
+)
+
+// apiErrorMessages maps substrings of lowercased Twitch error messages to sentinels.
+var apiErrorMessages = []struct {
+	substr string
+	err    error
+}{
+	{"missing scope", ErrMissingScope},
+	{"missing required scope", ErrMissingScope},
+	{"already banned", ErrAlreadyBanned},
+	{"is not banned", ErrNotBanned},
+	{"already a moderator", ErrAlreadyModerator},
+	{"already a mod", ErrAlreadyModerator},
+	{"is not a moderator", ErrNotModerator},
+	{"already a vip", ErrAlreadyVIP},
+	{"is not a vip", ErrNotVIP},
+}
+
+// Is reports whether the error matches target: the sentinel for its status code,
+// a sentinel derived from its message, or ErrInvalidToken for a 401 caused by an
+// invalid or expired token.
+func (e *APIError) Is(target error) bool {
+	if target == statusError(e.StatusCode) {
+		return true
+	}
+
+	msg := strings.ToLower(e.Message)
+	if target == ErrInvalidToken {
+		return e.StatusCode == http.StatusUnauthorized && strings.Contains(msg, "invalid") && strings.Contains(msg, "token")
+	}
+	for _, m := range apiErrorMessages {
+		if target == m.err && strings.Contains(msg, m.substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusError returns the sentinel for a status code, or nil.
+func statusError(status int) error {
+	switch {
+	case status == http.StatusBadRequest:
+		return ErrBadRequest
+	case status == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case status == http.StatusForbidden:
+		return ErrForbidden
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusConflict:
+		return ErrConflict
+	case status == http.StatusUnprocessableEntity:
+		return ErrUnprocessable
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= 500:
+		return ErrServerError
+	}
+	return nil
+}
+
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// Is reports whether target is ErrMissingScope.
+func (e *MissingScopeError) Is(target error) bool {
+	return target == ErrMissingScope
+}
@@ -0,0 +1,139 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     *APIError
+		matches []error
+		misses  []error
+	}{
+		{
+			name:    "not found",
+			err:     &APIError{StatusCode: 404, ErrorType: "Not Found", Message: "user not found"},
+			matches: []error{ErrNotFound},
+			misses:  []error{ErrBadRequest, ErrAlreadyBanned},
+		},
+		{
+			name:    "already banned",
+			err:     &APIError{StatusCode: 400, ErrorType: "Bad Request", Message: "The user specified in the user_id field is already banned."},
+			matches: []error{ErrBadRequest, ErrAlreadyBanned},
+			misses:  []error{ErrNotBanned, ErrNotFound},
+		},
+		{
+			name:    "not banned",
+			err:     &APIError{StatusCode: 400, ErrorType: "Bad Request", Message: "The user specified in the user_id field is not banned."},
+			matches: []error{ErrBadRequest, ErrNotBanned},
+			misses:  []error{ErrAlreadyBanned},
+		},
+		{
+			name:    "missing scope",
+			err:     &APIError{StatusCode: 401, ErrorType: "Unauthorized", Message: "Missing scope: moderator:manage:banned_users"},
+			matches: []error{ErrUnauthorized, ErrMissingScope},
+			misses:  []error{ErrInvalidToken, ErrForbidden},
+		},
+		{
+			name:    "invalid token",
+			err:     &APIError{StatusCode: 401, ErrorType: "Unauthorized", Message: "Invalid OAuth token"},
+			matches: []error{ErrUnauthorized, ErrInvalidToken},
+			misses:  []error{ErrMissingScope},
+		},
+		{
+			name:    "already VIP",
+			err:     &APIError{StatusCode: 422, ErrorType: "Unprocessable Entity", Message: "The user is already a VIP."},
+			matches: []error{ErrUnprocessable, ErrAlreadyVIP},
+			misses:  []error{ErrNotVIP},
+		},
+		{
+			name:    "server error",
+			err:     &APIError{StatusCode: 503, ErrorType: "Service Unavailable"},
+			matches: []error{ErrServerError},
+			misses:  []error{ErrRateLimited},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("wrapped: %w", tt.err)
+			for _, target := range tt.matches {
+				if !errors.Is(wrapped, target) {
+					t.Errorf("expected error to match %v", target)
+				}
+			}
+			for _, target := range tt.misses {
+				if errors.Is(wrapped, target) {
+					t.Errorf("expected error not to match %v", target)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIError_ErrorIncludesEndpoint(t *testing.T) {
+	err := &APIError{StatusCode: 404, ErrorType: "Not Found", Message: "user not found", Method: "GET", Endpoint: "/users"}
+	want := "twitch api error 404 (GET /users): Not Found - user not found"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestClient_APIErrorCarriesRequestContext(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Ratelimit-Limit", "800")
+		w.Header().Set("Ratelimit-Remaining", "799")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"Bad Request","status":400,"message":"The user specified in the user_id field is already banned."}`))
+	})
+	defer server.Close()
+
+	_, err := client.BanUser(context.Background(), &BanUserParams{
+		BroadcasterID: "1",
+		ModeratorID:   "2",
+		Data:          BanUserData{UserID: "3"},
+	})
+	if !errors.Is(err, ErrAlreadyBanned) {
+		t.Fatalf("expected ErrAlreadyBanned, got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.Method != http.MethodPost || apiErr.Endpoint != "/moderation/bans" {
+		t.Errorf("expected POST /moderation/bans, got %s %s", apiErr.Method, apiErr.Endpoint)
+	}
+	if apiErr.RateLimit.Limit != 800 || apiErr.RateLimit.Remaining != 799 {
+		t.Errorf("expected rate limit snapshot, got %+v", apiErr.RateLimit)
+	}
+}
+
+func TestClient_RateLimitErrorIsRateLimited(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer server.Close()
+	client.retryEnabled = false
+
+	_, err := client.GetUsers(context.Background(), nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.Endpoint != "/users" {
+		t.Errorf("expected *RateLimitError for /users, got %v", err)
+	}
+}
+
+func TestMissingScopeError_Is(t *testing.T) {
+	err := fmt.Errorf("ban: %w", &MissingScopeError{Method: "BanUser", Missing: []string{"moderator:manage:banned_users"}})
+	if !errors.Is(err, ErrMissingScope) {
+		t.Error("expected MissingScopeError to match ErrMissingScope")
+	}
+}
@@ -61,6 +61,7 @@ type EventSubWebhookHandler struct {
 	onNotification  func(*EventSubWebhookMessage)
 	onVerification  func(*EventSubWebhookMessage) bool
 	onRevocation    func(*EventSubWebhookMessage)
+	instr           Instrumentation
 }
 
 // EventSubWebhookOption configures the webhook handler.
@@ -102,6 +103,15 @@ func WithRevocationHandler(fn func(*EventSubWebhookMessage)) EventSubWebhookOpti
 	}
 }
 
+// WithWebhookInstrumentation sets the instrumentation that receives a span and a
+// message counter for each request. Message and subscription types are only
+// recorded for requests that parsed successfully.
+func WithWebhookInstrumentation(instr Instrumentation) EventSubWebhookOption {
+	return func(h *EventSubWebhookHandler) {
+		h.instr = instr
+	}
+}
+
 // NewEventSubWebhookHandler creates a new EventSub webhook handler.
 func NewEventSubWebhookHandler(opts ...EventSubWebhookOption) *EventSubWebhookHandler {
 	h := &EventSubWebhookHandler{
@@ -115,29 +125,46 @@ func NewEventSubWebhookHandler(opts ...EventSubWebhookOption) *EventSubWebhookHa
 
 // ServeHTTP implements http.Handler for the webhook handler.
 func (h *EventSubWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	instr := instrumentationOrNop(h.instr)
+	ctx, span := instr.StartSpan(r.Context(), SpanEventSubWebhookCall)
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	msg := h.serve(rec, r.WithContext(ctx))
+
+	attrs := []Attribute{StringAttr(AttrEventSubTransport, "webhook"), IntAttr(AttrStatus, rec.status)}
+	if msg != nil {
+		attrs = append(attrs, StringAttr(AttrEventSubMessage, msg.MessageType), StringAttr(AttrEventSubType, msg.SubscriptionType))
+	}
+	span.SetAttributes(attrs...)
+	span.End()
+	instr.AddCounter(ctx, CounterEventSubMessages, 1, attrs...)
+}
+
+// serve handles a webhook request, returning the message if it parsed successfully.
+func (h *EventSubWebhookHandler) serve(w http.ResponseWriter, r *http.Request) *EventSubWebhookMessage {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+		return nil
 	}
 
 	// Read body with size limit to prevent memory exhaustion
 	body, err := io.ReadAll(io.LimitReader(r.Body, EventSubMaxBodySize+1))
 	if err != nil {
 		http.Error(w, "Failed to read body", http.StatusBadRequest)
-		return
+		return nil
 	}
 	defer func() { _ = r.Body.Close() }()
 
 	if len(body) > EventSubMaxBodySize {
 		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
-		return
+		return nil
 	}
 
 	// Verify signature
 	if h.secret != "" {
 		if !h.verifySignature(r.Header, body) {
 			http.Error(w, "Invalid signature", http.StatusForbidden)
-			return
+			return nil
 		}
 	}
 
@@ -145,19 +172,19 @@ func (h *EventSubWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 	msg, err := h.parseMessage(r.Header, body)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return nil
 	}
 
 	// Check timestamp age (replay attack prevention)
 	// Reject messages that are too old
 	if time.Since(msg.MessageTimestamp) > h.maxTimestampAge {
 		http.Error(w, "Message timestamp too old", http.StatusBadRequest)
-		return
+		return nil
 	}
 	// Reject messages with future timestamps (clock skew tolerance of 1 minute)
 	if msg.MessageTimestamp.After(time.Now().Add(time.Minute)) {
 		http.Error(w, "Message timestamp in the future", http.StatusBadRequest)
-		return
+		return nil
 	}
 
 	// Handle based on message type
@@ -171,6 +198,7 @@ func (h *EventSubWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 	default:
 		http.Error(w, "Unknown message type", http.StatusBadRequest)
 	}
+	return msg
 }
 
 // verifySignature verifies the HMAC-SHA256 signature of the message.
@@ -412,3 +440,14 @@ func GetRevocationReason(subscription EventSubSubscription) string {
 	}
 	return subscription.Status
 }
+
+// statusRecorder records the status code written to a ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
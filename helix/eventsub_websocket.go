@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
@@ -119,6 +120,9 @@ type EventSubWebSocketClient struct {
 	stopOnce     sync.Once      // ensures stopChan is closed only once
 	wg           sync.WaitGroup // tracks readLoop goroutine
 	reconnectURL string
+
+	instr  Instrumentation
+	logger *slog.Logger
 }
 
 // EventSubWSOption configures the WebSocket client.
@@ -173,6 +177,20 @@ func WithWSKeepaliveHandler(fn func()) EventSubWSOption {
 	}
 }
 
+// WithWSInstrumentation sets the instrumentation that receives connect spans and message counters.
+func WithWSInstrumentation(instr Instrumentation) EventSubWSOption {
+	return func(c *EventSubWebSocketClient) {
+		c.instr = instr
+	}
+}
+
+// WithWSLogger sets the logger for connects, reconnects, revocations and dropped messages.
+func WithWSLogger(logger *slog.Logger) EventSubWSOption {
+	return func(c *EventSubWebSocketClient) {
+		c.logger = newLogger(logger)
+	}
+}
+
 // NewEventSubWebSocketClient creates a new EventSub WebSocket client.
 func NewEventSubWebSocketClient(opts ...EventSubWSOption) *EventSubWebSocketClient {
 	c := &EventSubWebSocketClient{
@@ -188,7 +206,15 @@ func NewEventSubWebSocketClient(opts ...EventSubWSOption) *EventSubWebSocketClie
 // Connect establishes a WebSocket connection to EventSub.
 // Returns the session ID that should be used when creating subscriptions.
 // This method is safe for concurrent use - only one connection attempt will proceed.
-func (c *EventSubWebSocketClient) Connect(ctx context.Context) (string, error) {
+func (c *EventSubWebSocketClient) Connect(ctx context.Context) (_ string, err error) {
+	ctx, span := instrumentationOrNop(c.instr).StartSpan(ctx, SpanEventSubConnect)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
 	c.mu.Lock()
 	if c.connected {
 		sessionID := c.sessionID
@@ -237,6 +263,7 @@ func (c *EventSubWebSocketClient) Connect(ctx context.Context) (string, error) {
 	c.sessionID = sessionID
 	c.connected = true
 	c.mu.Unlock()
+	loggerOrDiscard(c.logger).InfoContext(ctx, "connected to EventSub", "url", c.url, "session_id", sessionID)
 
 	// Start message handler
 	c.wg.Add(1)
@@ -275,6 +302,10 @@ func (c *EventSubWebSocketClient) waitForWelcome(ctx context.Context) (string, e
 	if msg.Metadata.MessageType != WSMessageTypeWelcome {
 		return "", fmt.Errorf("expected welcome message, got %s", msg.Metadata.MessageType)
 	}
+	instrumentationOrNop(c.instr).AddCounter(ctx, CounterEventSubMessages, 1,
+		StringAttr(AttrEventSubTransport, "websocket"),
+		StringAttr(AttrEventSubMessage, msg.Metadata.MessageType),
+		StringAttr(AttrEventSubType, ""))
 
 	var payload WebSocketWelcomePayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
@@ -332,8 +363,11 @@ func (c *EventSubWebSocketClient) readLoop() {
 		_, data, err := conn.ReadMessage()
 		if err != nil {
 			// Don't report errors for expected connection close scenarios
-			if c.onError != nil && !isExpectedCloseError(err) {
-				c.onError(fmt.Errorf("reading message: %w", err))
+			if !isExpectedCloseError(err) {
+				loggerOrDiscard(c.logger).Warn("EventSub connection lost", "session_id", c.SessionID(), "error", err)
+				if c.onError != nil {
+					c.onError(fmt.Errorf("reading message: %w", err))
+				}
 			}
 			return
 		}
@@ -346,15 +380,23 @@ func (c *EventSubWebSocketClient) readLoop() {
 func (c *EventSubWebSocketClient) handleMessage(data []byte) {
 	var msg WebSocketMessage
 	if err := json.Unmarshal(data, &msg); err != nil {
+		loggerOrDiscard(c.logger).Warn("dropping unparsable EventSub message", "error", err)
 		if c.onError != nil {
 			c.onError(fmt.Errorf("parsing message: %w", err))
 		}
 		return
 	}
 
+	instrumentationOrNop(c.instr).AddCounter(context.Background(), CounterEventSubMessages, 1,
+		StringAttr(AttrEventSubTransport, "websocket"),
+		StringAttr(AttrEventSubMessage, msg.Metadata.MessageType),
+		StringAttr(AttrEventSubType, msg.Metadata.SubscriptionType))
+
 	// Recover from handler panics to prevent crashing the read loop
 	defer func() {
 		if r := recover(); r != nil {
+			loggerOrDiscard(c.logger).Error("EventSub handler panicked, message dropped",
+				"message_id", msg.Metadata.MessageID, "message_type", msg.Metadata.MessageType, "panic", fmt.Sprint(r))
 			if c.onError != nil {
 				c.onError(fmt.Errorf("handler panic: %v", r))
 			}
@@ -381,11 +423,15 @@ func (c *EventSubWebSocketClient) handleMessage(data []byte) {
 // handleNotification processes a notification message.
 func (c *EventSubWebSocketClient) handleNotification(msg WebSocketMessage) {
 	if c.onNotification == nil {
+		loggerOrDiscard(c.logger).Debug("dropping notification without handler",
+			"message_id", msg.Metadata.MessageID, "subscription_type", msg.Metadata.SubscriptionType)
 		return
 	}
 
 	var payload WebSocketNotificationPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		loggerOrDiscard(c.logger).Warn("dropping unparsable notification",
+			"message_id", msg.Metadata.MessageID, "subscription_type", msg.Metadata.SubscriptionType, "error", err)
 		if c.onError != nil {
 			c.onError(fmt.Errorf("parsing notification: %w", err))
 		}
@@ -408,6 +454,7 @@ func (c *EventSubWebSocketClient) handleReconnect(msg WebSocketMessage) {
 	c.mu.Lock()
 	c.reconnectURL = payload.Session.ReconnectURL
 	c.mu.Unlock()
+	loggerOrDiscard(c.logger).Info("EventSub reconnect requested", "session_id", payload.Session.ID, "reconnect_url", payload.Session.ReconnectURL)
 
 	if c.onReconnect != nil {
 		c.onReconnect(payload.Session.ReconnectURL)
@@ -416,12 +463,18 @@ func (c *EventSubWebSocketClient) handleReconnect(msg WebSocketMessage) {
 
 // handleRevocation processes a revocation message.
 func (c *EventSubWebSocketClient) handleRevocation(msg WebSocketMessage) {
+	var payload WebSocketNotificationPayload
+	err := json.Unmarshal(msg.Payload, &payload)
+	if err == nil {
+		sub := payload.Subscription
+		loggerOrDiscard(c.logger).Warn("EventSub subscription revoked",
+			"subscription_id", sub.ID, "subscription_type", sub.Type, "reason", sub.Status)
+	}
+
 	if c.onRevocation == nil {
 		return
 	}
-
-	var payload WebSocketNotificationPayload
-	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+	if err != nil {
 		if c.onError != nil {
 			c.onError(fmt.Errorf("parsing revocation: %w", err))
 		}
@@ -502,6 +555,7 @@ func (c *EventSubWebSocketClient) Reconnect(ctx context.Context, url string) (st
 	// Connect to new URL
 	newConn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
 	if err != nil {
+		loggerOrDiscard(c.logger).WarnContext(ctx, "EventSub reconnect failed", "error", err)
 		return "", fmt.Errorf("connecting to reconnect URL: %w", err)
 	}
 
@@ -520,6 +574,7 @@ func (c *EventSubWebSocketClient) Reconnect(ctx context.Context, url string) (st
 		c.stopChan = nil
 		c.mu.Unlock()
 		_ = newConn.Close()
+		loggerOrDiscard(c.logger).WarnContext(ctx, "EventSub reconnect failed", "error", err)
 		return "", err
 	}
 
@@ -527,6 +582,7 @@ func (c *EventSubWebSocketClient) Reconnect(ctx context.Context, url string) (st
 	c.sessionID = sessionID
 	c.connected = true
 	c.mu.Unlock()
+	loggerOrDiscard(c.logger).InfoContext(ctx, "reconnected to EventSub", "session_id", sessionID)
 
 	// Start new read loop
 	c.wg.Add(1)
@@ -0,0 +1,297 @@
+package helix
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileCacheSuffix     = ".cache"
+	fileCacheHeaderSize = 16 // Expiry and stale-until times as Unix nanoseconds
+)
+
+// FileCache is a Cache that keeps each response in its own file so cached responses
+// survive restarts. When the total size exceeds the cap, entries past their stale
+// window are removed first, then those expiring soonest.
+// FileCache implements StaleCache.
+type FileCache struct {
+	dir      string
+	maxBytes int64 // Maximum total size of cache files (0 = unlimited)
+
+	mu      sync.Mutex
+	entries map[string]fileCacheEntry // By file name
+	size    int64
+}
+
+type fileCacheEntry struct {
+	size       int64
+	expiresAt  time.Time
+	staleUntil time.Time
+}
+
+// NewFileCache creates a file-backed cache in dir, picking up entries left by a
+// previous process. The directory is created with 0700 permissions if it does not exist.
+func NewFileCache(dir string, maxBytes int64) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	c := &FileCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		entries:  make(map[string]fileCacheEntry),
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading cache directory: %w", err)
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), fileCacheSuffix) {
+			continue
+		}
+		entry, err := readFileCacheHeader(filepath.Join(dir, f.Name()))
+		if err != nil {
+			_ = os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		c.entries[f.Name()] = entry
+		c.size += entry.size
+	}
+
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+
+	return c, nil
+}
+
+// Get retrieves a cached response. Returns nil if not found or expired.
+func (c *FileCache) Get(ctx context.Context, key string) []byte {
+	value, expiresAt := c.GetStale(ctx, key)
+	if value == nil || time.Now().After(expiresAt) {
+		return nil
+	}
+	return value
+}
+
+// GetStale retrieves a cached response and its expiry time, including an expired
+// entry that is still within its stale window.
+func (c *FileCache) GetStale(ctx context.Context, key string) ([]byte, time.Time) {
+	name := c.fileName(key)
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil || len(data) < fileCacheHeaderSize {
+		return nil, time.Time{}
+	}
+
+	expiresAt, staleUntil := decodeFileCacheHeader(data)
+	if time.Now().After(staleUntil) {
+		c.mu.Lock()
+		c.remove(name)
+		c.mu.Unlock()
+		return nil, time.Time{}
+	}
+	return data[fileCacheHeaderSize:], expiresAt
+}
+
+// Set stores a response in the cache.
+func (c *FileCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	c.SetWithStale(ctx, key, value, ttl, 0)
+}
+
+// SetWithStale stores a response that expires after ttl and is kept for GetStale
+// for a further stale duration. The file is replaced atomically.
+// Write errors are ignored; the response is simply not cached.
+func (c *FileCache) SetWithStale(ctx context.Context, key string, value []byte, ttl, stale time.Duration) {
+	expiresAt := time.Now().Add(ttl)
+	entry := fileCacheEntry{
+		size:       int64(fileCacheHeaderSize + len(value)),
+		expiresAt:  expiresAt,
+		staleUntil: expiresAt.Add(stale),
+	}
+	if c.maxBytes > 0 && entry.size > c.maxBytes {
+		return
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".cache-*")
+	if err != nil {
+		return
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	header := make([]byte, fileCacheHeaderSize)
+	binary.BigEndian.PutUint64(header[:8], uint64(entry.expiresAt.UnixNano()))
+	binary.BigEndian.PutUint64(header[8:], uint64(entry.staleUntil.UnixNano()))
+	if _, err := tmp.Write(append(header, value...)); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+
+	name := c.fileName(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Rename(tmpName, filepath.Join(c.dir, name)); err != nil {
+		return
+	}
+	c.size -= c.entries[name].size
+	c.entries[name] = entry
+	c.size += entry.size
+	c.evict()
+}
+
+// Delete removes a cached response.
+func (c *FileCache) Delete(ctx context.Context, key string) {
+	c.mu.Lock()
+	c.remove(c.fileName(key))
+	c.mu.Unlock()
+}
+
+// Clear removes all cached responses.
+func (c *FileCache) Clear(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name := range c.entries {
+		c.remove(name)
+	}
+}
+
+// Sweep removes entries that are past their stale window and returns how many were removed.
+func (c *FileCache) Sweep() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sweep()
+}
+
+// AutoSweep starts a goroutine that calls Sweep at the given interval.
+//
+// IMPORTANT: The caller MUST call the returned cancel function to stop the goroutine
+// when it's no longer needed, or ensure the parent context is eventually cancelled.
+func (c *FileCache) AutoSweep(ctx context.Context, interval time.Duration) (cancel func()) {
+	ctx, cancelFunc := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.Sweep()
+			}
+		}
+	}()
+
+	return cancelFunc
+}
+
+// Size returns the number of entries in the cache.
+func (c *FileCache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Bytes returns the total size of the cache files.
+func (c *FileCache) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// evict removes entries until the cache is within its size cap (must be called with lock held).
+func (c *FileCache) evict() {
+	if c.maxBytes <= 0 || c.size <= c.maxBytes {
+		return
+	}
+	c.sweep()
+
+	names := make([]string, 0, len(c.entries))
+	for name := range c.entries {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return c.entries[names[i]].expiresAt.Before(c.entries[names[j]].expiresAt)
+	})
+	for _, name := range names {
+		if c.size <= c.maxBytes {
+			break
+		}
+		c.remove(name)
+	}
+}
+
+// sweep removes entries past their stale window (must be called with lock held).
+func (c *FileCache) sweep() int {
+	now := time.Now()
+	removed := 0
+	for name, entry := range c.entries {
+		if now.After(entry.staleUntil) {
+			c.remove(name)
+			removed++
+		}
+	}
+	return removed
+}
+
+// remove deletes a cache file and its index entry (must be called with lock held).
+func (c *FileCache) remove(name string) {
+	_ = os.Remove(filepath.Join(c.dir, name))
+	if entry, ok := c.entries[name]; ok {
+		c.size -= entry.size
+		delete(c.entries, name)
+	}
+}
+
+// fileName returns the file name for key. Keys are hashed so arbitrary keys are safe file names.
+func (c *FileCache) fileName(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:]) + fileCacheSuffix
+}
+
+// readFileCacheHeader reads the index entry for an existing cache file.
+func readFileCacheHeader(path string) (fileCacheEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fileCacheEntry{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fileCacheEntry{}, err
+	}
+	header := make([]byte, fileCacheHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return fileCacheEntry{}, errors.New("invalid cache file")
+	}
+
+	expiresAt, staleUntil := decodeFileCacheHeader(header)
+	return fileCacheEntry{size: info.Size(), expiresAt: expiresAt, staleUntil: staleUntil}, nil
+}
+
+// decodeFileCacheHeader returns the expiry and stale-until times from a cache file header.
+func decodeFileCacheHeader(data []byte) (expiresAt, staleUntil time.Time) {
+	expiresAt = time.Unix(0, int64(binary.BigEndian.Uint64(data[:8])))
+	staleUntil = time.Unix(0, int64(binary.BigEndian.Uint64(data[8:fileCacheHeaderSize])))
+	return expiresAt, staleUntil
+}
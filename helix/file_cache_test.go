@@ -0,0 +1,163 @@
+package helix
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileCache_SetAndGet(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	cache.Set(ctx, "key1", []byte("value1"), time.Minute)
+	if got := cache.Get(ctx, "key1"); string(got) != "value1" {
+		t.Errorf("expected value1, got %q", got)
+	}
+	if got := cache.Get(ctx, "missing"); got != nil {
+		t.Errorf("expected nil for missing key, got %q", got)
+	}
+
+	cache.Delete(ctx, "key1")
+	if got := cache.Get(ctx, "key1"); got != nil {
+		t.Errorf("expected nil after delete, got %q", got)
+	}
+}
+
+func TestFileCache_Expiration(t *testing.T) {
+	cache, _ := NewFileCache(t.TempDir(), 0)
+	ctx := context.Background()
+
+	cache.Set(ctx, "expiring", []byte("value"), time.Millisecond)
+	cache.SetWithStale(ctx, "stale", []byte("value"), time.Millisecond, time.Minute)
+	time.Sleep(5 * time.Millisecond)
+
+	if cache.Get(ctx, "expiring") != nil || cache.Get(ctx, "stale") != nil {
+		t.Error("expected expired entries to miss")
+	}
+	if value, _ := cache.GetStale(ctx, "stale"); string(value) != "value" {
+		t.Errorf("expected stale value, got %q", value)
+	}
+	if cache.Size() != 1 {
+		t.Errorf("expected expired entry to be removed, got size %d", cache.Size())
+	}
+}
+
+func TestFileCache_Sweep(t *testing.T) {
+	dir := t.TempDir()
+	cache, _ := NewFileCache(dir, 0)
+	ctx := context.Background()
+
+	cache.Set(ctx, "a", []byte("value"), time.Millisecond)
+	cache.Set(ctx, "b", []byte("value"), time.Millisecond)
+	cache.Set(ctx, "c", []byte("value"), time.Minute)
+	time.Sleep(5 * time.Millisecond)
+
+	if removed := cache.Sweep(); removed != 2 {
+		t.Errorf("expected 2 entries swept, got %d", removed)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("expected 1 file left, got %d", len(files))
+	}
+}
+
+func TestFileCache_AutoSweep(t *testing.T) {
+	cache, _ := NewFileCache(t.TempDir(), 0)
+	cache.Set(context.Background(), "a", []byte("value"), time.Millisecond)
+
+	cancel := cache.AutoSweep(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for cache.Size() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cache.Size() != 0 {
+		t.Error("expected expired entry to be swept")
+	}
+}
+
+func TestFileCache_SizeCap(t *testing.T) {
+	value := make([]byte, 100)
+	entrySize := int64(fileCacheHeaderSize + len(value))
+	cache, _ := NewFileCache(t.TempDir(), 2*entrySize)
+	ctx := context.Background()
+
+	cache.Set(ctx, "first", value, time.Minute)
+	cache.Set(ctx, "second", value, 2*time.Minute)
+	cache.Set(ctx, "third", value, 3*time.Minute)
+
+	if cache.Bytes() > 2*entrySize || cache.Size() != 2 {
+		t.Errorf("expected cache within cap, got %d bytes in %d entries", cache.Bytes(), cache.Size())
+	}
+	if cache.Get(ctx, "first") != nil {
+		t.Error("expected entry expiring soonest to be evicted")
+	}
+
+	// Overwriting a key does not double count it
+	cache.Set(ctx, "third", value, 3*time.Minute)
+	if cache.Bytes() != 2*entrySize {
+		t.Errorf("expected %d bytes, got %d", 2*entrySize, cache.Bytes())
+	}
+
+	// Values larger than the cap are not stored
+	cache.Set(ctx, "huge", make([]byte, 1000), time.Minute)
+	if cache.Get(ctx, "huge") != nil {
+		t.Error("expected oversized value not to be stored")
+	}
+}
+
+func TestFileCache_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	cache, _ := NewFileCache(dir, 0)
+	cache.Set(ctx, "key", []byte("value"), time.Minute)
+	cache.Set(ctx, "expired", []byte("value"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	_ = os.WriteFile(dir+"/garbage.cache", []byte("x"), 0o600)
+
+	restarted, err := NewFileCache(dir, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := restarted.Get(ctx, "key"); string(got) != "value" {
+		t.Errorf("expected value to survive restart, got %q", got)
+	}
+	if restarted.Size() != 2 {
+		t.Errorf("expected invalid files to be dropped, got size %d", restarted.Size())
+	}
+
+	restarted.Clear(ctx)
+	files, _ := os.ReadDir(dir)
+	if len(files) != 0 || restarted.Bytes() != 0 {
+		t.Errorf("expected empty cache after clear, got %d files", len(files))
+	}
+}
+
+func TestFileCache_WithClient(t *testing.T) {
+	var requests int32
+	cache, _ := NewFileCache(t.TempDir(), 0)
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"data":[{"id":"1"}]}`))
+	}, WithCache(cache, time.Minute))
+	defer server.Close()
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.GetUsers(context.Background(), nil)
+		if err != nil || resp.Data[0].ID != "1" {
+			t.Fatalf("unexpected result: %v %v", resp, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
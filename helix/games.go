@@ -15,31 +15,21 @@ type Game struct {
 
 // GetGamesParams contains parameters for GetGames.
 type GetGamesParams struct {
-	IDs     []string // Game IDs (max 100)
-	Names   []string // Game names (max 100)
-	IGDBIDs []string // IGDB IDs (max 100)
+	IDs     []string // Game IDs
+	Names   []string // Game names
+	IGDBIDs []string // IGDB IDs
 }
 
 // GetGames gets information about one or more games.
+// More than 100 IDs, names and IGDB IDs combined are split across concurrent requests.
 func (c *Client) GetGames(ctx context.Context, params *GetGamesParams) (*Response[Game], error) {
-	q := url.Values{}
+	var values []queryValue
 	if params != nil {
-		for _, id := range params.IDs {
-			q.Add("id", id)
-		}
-		for _, name := range params.Names {
-			q.Add("name", name)
-		}
-		for _, igdbID := range params.IGDBIDs {
-			q.Add("igdb_id", igdbID)
-		}
-	}
-
-	var resp Response[Game]
-	if err := c.get(ctx, "/games", q, &resp); err != nil {
-		return nil, err
+		values = append(values, queryValues("id", params.IDs)...)
+		values = append(values, queryValues("name", params.Names)...)
+		values = append(values, queryValues("igdb_id", params.IGDBIDs)...)
 	}
-	return &resp, nil
+	return getChunked[Game](ctx, c, "/games", url.Values{}, values, maxIDsPerRequest)
 }
 
 // GetTopGamesParams contains parameters for GetTopGames.
@@ -0,0 +1,234 @@
+package helixtest
+
+import (
+	"net/http"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// maxCustomRewards is how many custom rewards a channel may have.
+const maxCustomRewards = 50
+
+// customReward is a stored reward along with the client ID that created it. Only
+// that client may update or delete it.
+type customReward struct {
+	helix.CustomReward
+	clientID string
+}
+
+// rewardFields is the request body of the create and update reward endpoints.
+// Every field is a pointer so an update only touches the fields it sets.
+type rewardFields struct {
+	Title                             *string `json:"title"`
+	Cost                              *int    `json:"cost"`
+	Prompt                            *string `json:"prompt"`
+	IsEnabled                         *bool   `json:"is_enabled"`
+	BackgroundColor                   *string `json:"background_color"`
+	IsUserInputRequired               *bool   `json:"is_user_input_required"`
+	IsMaxPerStreamEnabled             *bool   `json:"is_max_per_stream_enabled"`
+	MaxPerStream                      *int    `json:"max_per_stream"`
+	IsMaxPerUserPerStreamEnabled      *bool   `json:"is_max_per_user_per_stream_enabled"`
+	MaxPerUserPerStream               *int    `json:"max_per_user_per_stream"`
+	IsGlobalCooldownEnabled           *bool   `json:"is_global_cooldown_enabled"`
+	GlobalCooldownSeconds             *int    `json:"global_cooldown_seconds"`
+	ShouldRedemptionsSkipRequestQueue *bool   `json:"should_redemptions_skip_request_queue"`
+	IsPaused                          *bool   `json:"is_paused"`
+}
+
+// apply copies the set fields onto r.
+func (f rewardFields) apply(r *helix.CustomReward) {
+	setField(&r.Title, f.Title)
+	setField(&r.Cost, f.Cost)
+	setField(&r.Prompt, f.Prompt)
+	setField(&r.IsEnabled, f.IsEnabled)
+	setField(&r.BackgroundColor, f.BackgroundColor)
+	setField(&r.IsUserInputRequired, f.IsUserInputRequired)
+	setField(&r.MaxPerStreamSetting.IsEnabled, f.IsMaxPerStreamEnabled)
+	setField(&r.MaxPerStreamSetting.MaxPerStream, f.MaxPerStream)
+	setField(&r.MaxPerUserPerStreamSetting.IsEnabled, f.IsMaxPerUserPerStreamEnabled)
+	setField(&r.MaxPerUserPerStreamSetting.MaxPerUserPerStream, f.MaxPerUserPerStream)
+	setField(&r.GlobalCooldownSetting.IsEnabled, f.IsGlobalCooldownEnabled)
+	setField(&r.GlobalCooldownSetting.GlobalCooldownSeconds, f.GlobalCooldownSeconds)
+	setField(&r.ShouldRedemptionsSkipRequestQueue, f.ShouldRedemptionsSkipRequestQueue)
+	setField(&r.IsPaused, f.IsPaused)
+}
+
+func setField[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// AddCustomReward adds a reward to a broadcaster's channel as if it had been
+// created in the Twitch dashboard, so no client may manage it through the API. An
+// ID is generated if r.ID is empty. It returns the stored reward.
+func (s *Server) AddCustomReward(r helix.CustomReward) helix.CustomReward {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.ID == "" {
+		r.ID = s.newID()
+	}
+	r.BroadcasterLogin, r.BroadcasterName = s.userLabels(r.BroadcasterID)
+	r.IsInStock = true
+	s.rewards[r.BroadcasterID] = append(s.rewards[r.BroadcasterID], &customReward{CustomReward: r})
+	return r
+}
+
+// CustomRewards returns a channel's custom rewards in the order they were created.
+func (s *Server) CustomRewards(broadcasterID string) []helix.CustomReward {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rewards := make([]helix.CustomReward, len(s.rewards[broadcasterID]))
+	for i, r := range s.rewards[broadcasterID] {
+		rewards[i] = r.CustomReward
+	}
+	return rewards
+}
+
+// findReward returns the index of a channel's reward, or -1 if it doesn't exist.
+func (s *Server) findReward(broadcasterID, id string) int {
+	for i, r := range s.rewards[broadcasterID] {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasRewardTitle reports whether another reward in the channel has the title.
+func (s *Server) hasRewardTitle(broadcasterID, id, title string) bool {
+	for _, r := range s.rewards[broadcasterID] {
+		if r.ID != id && r.Title == title {
+			return true
+		}
+	}
+	return false
+}
+
+// manageableReward returns the reward named by the broadcaster_id and id query
+// parameters, writing an error if it doesn't exist or belongs to another client.
+func (s *Server) manageableReward(w http.ResponseWriter, r *http.Request, c *caller) (int, bool) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok || !requireUser(w, c, "broadcaster_id", broadcasterID) {
+		return 0, false
+	}
+	id, ok := requireParam(w, r, "id")
+	if !ok {
+		return 0, false
+	}
+	i := s.findReward(broadcasterID, id)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "The custom reward was not found.")
+		return 0, false
+	}
+	if s.rewards[broadcasterID][i].clientID != c.clientID {
+		writeError(w, http.StatusForbidden, "The ID in the Client-Id header must match the client ID used to create the custom reward.")
+		return 0, false
+	}
+	return i, true
+}
+
+func (s *Server) getCustomRewards(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok || !requireUser(w, c, "broadcaster_id", broadcasterID) {
+		return
+	}
+	q := r.URL.Query()
+	ids := q["id"]
+	if len(ids) > maxCustomRewards {
+		writeError(w, http.StatusBadRequest, "The number of id parameters must not exceed 50")
+		return
+	}
+	onlyManageable := q.Get("only_manageable_rewards") == "true"
+
+	var rewards []helix.CustomReward
+	for _, reward := range s.rewards[broadcasterID] {
+		if !matchesAny(ids, reward.ID) || (onlyManageable && reward.clientID != c.clientID) {
+			continue
+		}
+		rewards = append(rewards, reward.CustomReward)
+	}
+	if len(ids) > 0 && len(rewards) == 0 {
+		writeError(w, http.StatusNotFound, "The custom reward was not found.")
+		return
+	}
+	writeData(w, http.StatusOK, rewards...)
+}
+
+func (s *Server) createCustomReward(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok || !requireUser(w, c, "broadcaster_id", broadcasterID) {
+		return
+	}
+	var fields rewardFields
+	if !decodeBody(w, r, &fields) {
+		return
+	}
+	switch {
+	case fields.Title == nil || *fields.Title == "" || len(*fields.Title) > 45:
+		writeError(w, http.StatusBadRequest, "The title field must contain 1 to 45 characters.")
+		return
+	case fields.Cost == nil || *fields.Cost < 1:
+		writeError(w, http.StatusBadRequest, "The cost field must be at least 1.")
+		return
+	case len(s.rewards[broadcasterID]) >= maxCustomRewards:
+		writeError(w, http.StatusBadRequest, "The channel has the maximum number of custom rewards.")
+		return
+	case s.hasRewardTitle(broadcasterID, "", *fields.Title):
+		writeError(w, http.StatusBadRequest, "CREATE_CUSTOM_REWARD_DUPLICATE_REWARD")
+		return
+	}
+
+	login, name := s.userLabels(broadcasterID)
+	reward := &customReward{
+		CustomReward: helix.CustomReward{
+			BroadcasterID:    broadcasterID,
+			BroadcasterLogin: login,
+			BroadcasterName:  name,
+			ID:               s.newID(),
+			IsEnabled:        true,
+			IsInStock:        true,
+		},
+		clientID: c.clientID,
+	}
+	fields.apply(&reward.CustomReward)
+	s.rewards[broadcasterID] = append(s.rewards[broadcasterID], reward)
+	writeData(w, http.StatusOK, reward.CustomReward)
+}
+
+func (s *Server) updateCustomReward(w http.ResponseWriter, r *http.Request, c *caller) {
+	i, ok := s.manageableReward(w, r, c)
+	if !ok {
+		return
+	}
+	var fields rewardFields
+	if !decodeBody(w, r, &fields) {
+		return
+	}
+	reward := s.rewards[r.URL.Query().Get("broadcaster_id")][i]
+	switch {
+	case fields.Title != nil && (*fields.Title == "" || len(*fields.Title) > 45):
+		writeError(w, http.StatusBadRequest, "The title field must contain 1 to 45 characters.")
+		return
+	case fields.Cost != nil && *fields.Cost < 1:
+		writeError(w, http.StatusBadRequest, "The cost field must be at least 1.")
+		return
+	case fields.Title != nil && s.hasRewardTitle(reward.BroadcasterID, reward.ID, *fields.Title):
+		writeError(w, http.StatusBadRequest, "CREATE_CUSTOM_REWARD_DUPLICATE_REWARD")
+		return
+	}
+
+	fields.apply(&reward.CustomReward)
+	writeData(w, http.StatusOK, reward.CustomReward)
+}
+
+func (s *Server) deleteCustomReward(w http.ResponseWriter, r *http.Request, c *caller) {
+	i, ok := s.manageableReward(w, r, c)
+	if !ok {
+		return
+	}
+	broadcasterID := r.URL.Query().Get("broadcaster_id")
+	rewards := s.rewards[broadcasterID]
+	s.rewards[broadcasterID] = append(rewards[:i], rewards[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,72 @@
+package helixtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+func TestServer_CustomRewards(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeChannelManageRedemptions)
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+
+	reward, err := client.CreateCustomReward(ctx, &helix.CreateCustomRewardParams{BroadcasterID: "1", Title: "Hydrate", Cost: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reward.IsEnabled || reward.BroadcasterLogin != "streamer" {
+		t.Errorf("unexpected reward: %+v", reward)
+	}
+	_, err = client.CreateCustomReward(ctx, &helix.CreateCustomRewardParams{BroadcasterID: "1", Title: "Hydrate", Cost: 50})
+	if !errors.Is(err, helix.ErrBadRequest) {
+		t.Errorf("expected ErrBadRequest for a duplicate title, got %v", err)
+	}
+
+	cost, paused := 200, true
+	updated, err := client.UpdateCustomReward(ctx, &helix.UpdateCustomRewardParams{BroadcasterID: "1", ID: reward.ID, Cost: &cost, IsPaused: &paused})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Cost != 200 || !updated.IsPaused || updated.Title != "Hydrate" {
+		t.Errorf("unexpected updated reward: %+v", updated)
+	}
+
+	if err := client.DeleteCustomReward(ctx, "1", reward.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.DeleteCustomReward(ctx, "1", reward.ID); !errors.Is(err, helix.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestServer_CustomRewards_Manageable(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeChannelManageRedemptions)
+	dashboard := srv.AddCustomReward(helix.CustomReward{BroadcasterID: "1", Title: "Dashboard", Cost: 1})
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+	if _, err := client.CreateCustomReward(ctx, &helix.CreateCustomRewardParams{BroadcasterID: "1", Title: "API", Cost: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	all, err := client.GetCustomRewards(ctx, &helix.GetCustomRewardsParams{BroadcasterID: "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	manageable, err := client.GetCustomRewards(ctx, &helix.GetCustomRewardsParams{BroadcasterID: "1", OnlyManageableRewards: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all.Data) != 2 || len(manageable.Data) != 1 || manageable.Data[0].Title != "API" {
+		t.Errorf("expected 2 rewards with 1 manageable, got %+v and %+v", all.Data, manageable.Data)
+	}
+
+	if err := client.DeleteCustomReward(ctx, "1", dashboard.ID); !errors.Is(err, helix.ErrForbidden) {
+		t.Errorf("expected ErrForbidden deleting a dashboard reward, got %v", err)
+	}
+	if rewards := srv.CustomRewards("1"); len(rewards) != 2 {
+		t.Errorf("expected 2 stored rewards, got %d", len(rewards))
+	}
+}
@@ -0,0 +1,193 @@
+package helixtest
+
+import (
+	"net/http"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// Channel returns the channel of a broadcaster added with AddUser.
+func (s *Server) Channel(broadcasterID string) (helix.Channel, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, ok := s.channels[broadcasterID]
+	if !ok {
+		return helix.Channel{}, false
+	}
+	return *ch, true
+}
+
+// SetChannel replaces a channel's information. The broadcaster's login and name
+// are taken from their user if they have one.
+func (s *Server) SetChannel(ch helix.Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if login, name := s.userLabels(ch.BroadcasterID); login != "" {
+		ch.BroadcasterLogin, ch.BroadcasterName = login, name
+	}
+	if ch.Tags == nil {
+		ch.Tags = []string{}
+	}
+	s.channels[ch.BroadcasterID] = &ch
+}
+
+// StartStream puts a broadcaster live. The stream's ID, user labels, title, game
+// and language default to the broadcaster's user and channel. It returns the
+// stored stream.
+func (s *Server) StartStream(st helix.Stream) helix.Stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st.ID == "" {
+		st.ID = s.newID()
+	}
+	if login, name := s.userLabels(st.UserID); login != "" {
+		st.UserLogin, st.UserName = login, name
+	}
+	if ch, ok := s.channels[st.UserID]; ok {
+		if st.Title == "" {
+			st.Title = ch.Title
+		}
+		if st.GameID == "" {
+			st.GameID, st.GameName = ch.GameID, ch.GameName
+		}
+		if st.Language == "" {
+			st.Language = ch.BroadcasterLanguage
+		}
+		if st.Tags == nil {
+			st.Tags = ch.Tags
+		}
+	}
+	if st.Type == "" {
+		st.Type = "live"
+	}
+	if st.StartedAt.IsZero() {
+		st.StartedAt = s.now().UTC()
+	}
+	if st.Tags == nil {
+		st.Tags = []string{}
+	}
+
+	s.endStream(st.UserID)
+	s.streams = append(s.streams, &st)
+	return st
+}
+
+// EndStream takes a broadcaster offline.
+func (s *Server) EndStream(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endStream(userID)
+}
+
+func (s *Server) endStream(userID string) {
+	for i, st := range s.streams {
+		if st.UserID == userID {
+			s.streams = append(s.streams[:i], s.streams[i+1:]...)
+			return
+		}
+	}
+}
+
+// Streams returns the live streams in the order they started.
+func (s *Server) Streams() []helix.Stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	streams := make([]helix.Stream, len(s.streams))
+	for i, st := range s.streams {
+		streams[i] = *st
+	}
+	return streams
+}
+
+func (s *Server) getChannels(w http.ResponseWriter, r *http.Request, c *caller) {
+	ids := r.URL.Query()["broadcaster_id"]
+	if len(ids) == 0 {
+		writeError(w, http.StatusBadRequest, `Missing required parameter "broadcaster_id"`)
+		return
+	}
+	if len(ids) > 100 {
+		writeError(w, http.StatusBadRequest, "The number of broadcaster_id parameters must not exceed 100")
+		return
+	}
+
+	var channels []helix.Channel
+	for _, id := range ids {
+		if ch, ok := s.channels[id]; ok {
+			channels = append(channels, *ch)
+		}
+	}
+	writeData(w, http.StatusOK, channels...)
+}
+
+func (s *Server) modifyChannel(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok || !requireUser(w, c, "broadcaster_id", broadcasterID) {
+		return
+	}
+	var params helix.ModifyChannelInformationParams
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	ch, ok := s.channels[broadcasterID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Channel not found")
+		return
+	}
+	if len(params.Tags) > 10 {
+		writeError(w, http.StatusBadRequest, "A channel may have at most 10 tags")
+		return
+	}
+
+	if params.Title != "" {
+		ch.Title = params.Title
+	}
+	if params.GameID != "" {
+		ch.GameID, ch.GameName = params.GameID, s.gameName(params.GameID)
+	}
+	if params.BroadcasterLanguage != "" {
+		ch.BroadcasterLanguage = params.BroadcasterLanguage
+	}
+	if params.Delay != nil {
+		ch.Delay = *params.Delay
+	}
+	if params.Tags != nil {
+		ch.Tags = params.Tags
+	}
+	if params.IsBrandedContent != nil {
+		ch.IsBrandedContent = *params.IsBrandedContent
+	}
+
+	// A live stream reflects the channel's new title and game
+	for _, st := range s.streams {
+		if st.UserID == broadcasterID {
+			st.Title, st.GameID, st.GameName, st.Tags = ch.Title, ch.GameID, ch.GameName, ch.Tags
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getStreams(w http.ResponseWriter, r *http.Request, c *caller) {
+	q := r.URL.Query()
+	userIDs, userLogins, gameIDs, languages := q["user_id"], q["user_login"], q["game_id"], q["language"]
+	if len(userIDs)+len(userLogins) > 100 || len(gameIDs) > 100 {
+		writeError(w, http.StatusBadRequest, "The number of user_id, user_login and game_id parameters must not exceed 100")
+		return
+	}
+
+	var streams []helix.Stream
+	for _, st := range s.streams {
+		if len(userIDs)+len(userLogins) > 0 && !containsString(userIDs, st.UserID) && !containsString(userLogins, st.UserLogin) {
+			continue
+		}
+		if !matchesAny(gameIDs, st.GameID) || !matchesAny(languages, st.Language) {
+			continue
+		}
+		if t := q.Get("type"); t != "" && t != "all" && t != st.Type {
+			continue
+		}
+		streams = append(streams, *st)
+	}
+
+	writePage(w, r, streams)
+}
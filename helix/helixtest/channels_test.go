@@ -0,0 +1,83 @@
+package helixtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+func TestServer_ModifyChannelInformation(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeChannelManageBroadcast)
+	game := srv.AddGame(helix.Game{Name: "Chess"})
+	srv.StartStream(helix.Stream{UserID: "1"})
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+
+	err := client.ModifyChannelInformation(ctx, &helix.ModifyChannelInformationParams{
+		BroadcasterID: "1",
+		Title:         "Playing chess",
+		GameID:        game.ID,
+		Tags:          []string{"English"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := client.GetChannelInformation(ctx, &helix.GetChannelInformationParams{BroadcasterIDs: []string{"1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ch := resp.Data[0]
+	if ch.Title != "Playing chess" || ch.GameName != "Chess" || len(ch.Tags) != 1 {
+		t.Errorf("unexpected channel: %+v", ch)
+	}
+	if st := srv.Streams()[0]; st.Title != "Playing chess" || st.GameID != game.ID {
+		t.Errorf("expected the live stream to follow the channel, got %+v", st)
+	}
+
+	err = client.ModifyChannelInformation(ctx, &helix.ModifyChannelInformationParams{BroadcasterID: "2", Title: "Mine now"})
+	if !errors.Is(err, helix.ErrForbidden) {
+		t.Errorf("expected ErrForbidden for another channel, got %v", err)
+	}
+}
+
+func TestServer_GetStreams_Filters(t *testing.T) {
+	srv := newTestServer(t)
+	srv.StartStream(helix.Stream{UserID: "1", GameID: "10", Language: "en"})
+	srv.StartStream(helix.Stream{UserID: "2", GameID: "20", Language: "de"})
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		params *helix.GetStreamsParams
+		want   string
+	}{
+		{"user id", &helix.GetStreamsParams{UserIDs: []string{"2"}}, "2"},
+		{"user login", &helix.GetStreamsParams{UserLogins: []string{"streamer"}}, "1"},
+		{"game", &helix.GetStreamsParams{GameIDs: []string{"20"}}, "2"},
+		{"language", &helix.GetStreamsParams{Language: []string{"en"}}, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetStreams(ctx, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(resp.Data) != 1 || resp.Data[0].UserID != tt.want {
+				t.Errorf("expected stream of %s, got %+v", tt.want, resp.Data)
+			}
+		})
+	}
+
+	srv.EndStream("1")
+	resp, err := client.GetStreams(ctx, &helix.GetStreamsParams{UserIDs: []string{"1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 0 {
+		t.Errorf("expected no stream after EndStream, got %+v", resp.Data)
+	}
+}
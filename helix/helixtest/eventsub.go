@@ -0,0 +1,246 @@
+package helixtest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// maxTotalCost is the total subscription cost each client may have.
+const maxTotalCost = 10000
+
+// EventSub subscription statuses.
+const (
+	subscriptionEnabled             = "enabled"
+	subscriptionVerificationPending = "webhook_callback_verification_pending"
+)
+
+// subscription is a stored EventSub subscription along with the client ID that
+// created it. Clients only see and pay for their own subscriptions.
+type subscription struct {
+	helix.EventSubSubscription
+	clientID string
+}
+
+// Subscriptions returns the EventSub subscriptions in the order they were created.
+func (s *Server) Subscriptions() []helix.EventSubSubscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := make([]helix.EventSubSubscription, len(s.subscriptions))
+	for i, sub := range s.subscriptions {
+		subs[i] = sub.EventSubSubscription
+	}
+	return subs
+}
+
+// SetSubscriptionStatus changes the status of a subscription, for example to
+// "enabled" once a webhook callback would have been verified, or to
+// "authorization_revoked". It returns false if the subscription doesn't exist.
+func (s *Server) SetSubscriptionStatus(id, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.subscriptions {
+		if sub.ID == id {
+			sub.Status = status
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionCost returns the cost of a subscription. Subscriptions are free when
+// the user in the condition authorized the token; others cost 1.
+func subscriptionCost(condition map[string]string, c *caller) int {
+	if c.userID != "" && conditionMatches(condition, c.userID) {
+		return 0
+	}
+	return 1
+}
+
+// conditionMatches reports whether a subscription's condition mentions userID.
+func conditionMatches(condition map[string]string, userID string) bool {
+	for key, v := range condition {
+		if strings.HasSuffix(key, "user_id") && v == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// sameSubscription reports whether two subscriptions have the same type,
+// version, condition and transport target.
+func sameSubscription(a, b *helix.EventSubSubscription) bool {
+	if a.Type != b.Type || a.Version != b.Version || len(a.Condition) != len(b.Condition) {
+		return false
+	}
+	for key, v := range a.Condition {
+		if b.Condition[key] != v {
+			return false
+		}
+	}
+	return a.Transport.Method == b.Transport.Method &&
+		a.Transport.Callback == b.Transport.Callback &&
+		a.Transport.SessionID == b.Transport.SessionID &&
+		a.Transport.ConduitID == b.Transport.ConduitID
+}
+
+// totalCost returns the summed cost of a client's subscriptions.
+func (s *Server) totalCost(clientID string) int {
+	total := 0
+	for _, sub := range s.subscriptions {
+		if sub.clientID == clientID {
+			total += sub.Cost
+		}
+	}
+	return total
+}
+
+// validateTransport checks the transport of a new subscription against the
+// caller's token. It returns an error message, or an empty string if valid.
+func validateTransport(t helix.CreateEventSubTransport, c *caller) string {
+	switch t.Method {
+	case "webhook":
+		if c.userID != "" {
+			return "Webhook subscriptions require an app access token."
+		}
+		if !strings.HasPrefix(t.Callback, "https://") {
+			return "The callback field must use HTTPS."
+		}
+		if len(t.Secret) < 10 || len(t.Secret) > 100 {
+			return "The secret field must contain 10 to 100 characters."
+		}
+	case "websocket":
+		if c.userID == "" {
+			return "WebSocket subscriptions require a user access token."
+		}
+		if t.SessionID == "" {
+			return `Missing required field "session_id"`
+		}
+	case "conduit":
+		if c.userID != "" {
+			return "Conduit subscriptions require an app access token."
+		}
+		if t.ConduitID == "" {
+			return `Missing required field "conduit_id"`
+		}
+	default:
+		return "The transport method must be webhook, websocket or conduit."
+	}
+	return ""
+}
+
+func (s *Server) getSubscriptions(w http.ResponseWriter, r *http.Request, c *caller) {
+	q := r.URL.Query()
+	status, subType, userID := q.Get("status"), q.Get("type"), q.Get("user_id")
+	if (status != "" && subType != "") || (status != "" && userID != "") || (subType != "" && userID != "") {
+		writeError(w, http.StatusBadRequest, "Only one of the status, type and user_id filters may be specified.")
+		return
+	}
+
+	var matched []helix.EventSubSubscription
+	for _, sub := range s.subscriptions {
+		if sub.clientID == c.clientID &&
+			(status == "" || sub.Status == status) &&
+			(subType == "" || sub.Type == subType) &&
+			(userID == "" || conditionMatches(sub.Condition, userID)) {
+			matched = append(matched, sub.EventSubSubscription)
+		}
+	}
+
+	page, pagination, ok := paginate(w, r, matched)
+	if !ok {
+		return
+	}
+	if page == nil {
+		page = []helix.EventSubSubscription{}
+	}
+	writeJSON(w, http.StatusOK, helix.EventSubResponse{
+		Data:         page,
+		Total:        len(matched),
+		TotalCost:    s.totalCost(c.clientID),
+		MaxTotalCost: maxTotalCost,
+		Pagination:   pagination,
+	})
+}
+
+func (s *Server) createSubscription(w http.ResponseWriter, r *http.Request, c *caller) {
+	var params helix.CreateEventSubSubscriptionParams
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	if _, ok := helix.EventSubTypeVersion[params.Type]; !ok || params.Version == "" {
+		writeError(w, http.StatusBadRequest, "The combination of values in the type and version fields is not valid.")
+		return
+	}
+	if len(params.Condition) == 0 {
+		writeError(w, http.StatusBadRequest, `Missing required field "condition"`)
+		return
+	}
+	if msg := validateTransport(params.Transport, c); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if params.Transport.Method == "websocket" && s.eventSub != nil && !s.eventSub.hasSession(params.Transport.SessionID) {
+		writeError(w, http.StatusBadRequest, "The websocket session does not exist or has already disconnected.")
+		return
+	}
+
+	sub := &subscription{clientID: c.clientID}
+	sub.EventSubSubscription = helix.EventSubSubscription{
+		ID:        s.newID(),
+		Status:    subscriptionEnabled,
+		Type:      params.Type,
+		Version:   params.Version,
+		Condition: params.Condition,
+		CreatedAt: s.now().UTC(),
+		Transport: helix.EventSubTransport{
+			Method:    params.Transport.Method,
+			Callback:  params.Transport.Callback,
+			SessionID: params.Transport.SessionID,
+			ConduitID: params.Transport.ConduitID,
+		},
+		Cost: subscriptionCost(params.Condition, c),
+	}
+	if sub.Transport.Method == "webhook" {
+		sub.Status = subscriptionVerificationPending
+	}
+	if sub.Transport.Method == "websocket" {
+		sub.Transport.ConnectedAt = s.now().UTC().Format(time.RFC3339)
+	}
+
+	for _, existing := range s.subscriptions {
+		if existing.clientID == c.clientID && sameSubscription(&existing.EventSubSubscription, &sub.EventSubSubscription) {
+			writeError(w, http.StatusConflict, "subscription already exists")
+			return
+		}
+	}
+	if s.totalCost(c.clientID)+sub.Cost > maxTotalCost {
+		writeError(w, http.StatusTooManyRequests, "The subscription would exceed the maximum total cost.")
+		return
+	}
+
+	s.subscriptions = append(s.subscriptions, sub)
+	writeJSON(w, http.StatusAccepted, helix.EventSubResponse{
+		Data:         []helix.EventSubSubscription{sub.EventSubSubscription},
+		Total:        1,
+		TotalCost:    s.totalCost(c.clientID),
+		MaxTotalCost: maxTotalCost,
+	})
+}
+
+func (s *Server) deleteSubscription(w http.ResponseWriter, r *http.Request, c *caller) {
+	id, ok := requireParam(w, r, "id")
+	if !ok {
+		return
+	}
+	for i, sub := range s.subscriptions {
+		if sub.ID == id && sub.clientID == c.clientID {
+			s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "The subscription was not found.")
+}
@@ -0,0 +1,112 @@
+package helixtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+func TestServer_EventSubWebSocket(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+	transport := helix.CreateEventSubTransport{Method: "websocket", SessionID: "session-1"}
+
+	own, err := client.SubscribeToChannel(ctx, helix.EventSubTypeStreamOnline, "1", transport)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if own.Status != "enabled" || own.Cost != 0 || own.Transport.ConnectedAt == "" {
+		t.Errorf("unexpected subscription: %+v", own)
+	}
+	other, err := client.SubscribeToChannel(ctx, helix.EventSubTypeStreamOnline, "2", transport)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other.Cost != 1 {
+		t.Errorf("expected cost 1 for another broadcaster, got %d", other.Cost)
+	}
+
+	_, err = client.SubscribeToChannel(ctx, helix.EventSubTypeStreamOnline, "1", transport)
+	if !errors.Is(err, helix.ErrConflict) {
+		t.Errorf("expected ErrConflict for a duplicate, got %v", err)
+	}
+
+	resp, err := client.GetEventSubSubscriptions(ctx, &helix.GetEventSubSubscriptionsParams{UserID: "2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Total != 1 || resp.TotalCost != 1 || resp.MaxTotalCost != maxTotalCost || resp.Data[0].ID != other.ID {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	if err := client.DeleteEventSubSubscription(ctx, own.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.DeleteEventSubSubscription(ctx, own.ID); !errors.Is(err, helix.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if subs := srv.Subscriptions(); len(subs) != 1 {
+		t.Errorf("expected 1 subscription, got %d", len(subs))
+	}
+}
+
+func TestServer_EventSubWebhook(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddAppToken("app-token")
+	client := srv.Client("app-token")
+	ctx := context.Background()
+
+	sub, err := client.SubscribeToChannel(ctx, helix.EventSubTypeStreamOnline, "1", helix.CreateEventSubTransport{
+		Method:   "webhook",
+		Callback: "https://example.com/eventsub",
+		Secret:   "0123456789",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.Status != "webhook_callback_verification_pending" || sub.Transport.Secret != "" {
+		t.Errorf("unexpected subscription: %+v", sub)
+	}
+
+	if !srv.SetSubscriptionStatus(sub.ID, "enabled") {
+		t.Fatal("expected SetSubscriptionStatus to find the subscription")
+	}
+	resp, err := client.GetEventSubSubscriptions(ctx, &helix.GetEventSubSubscriptionsParams{Status: "enabled"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 1 {
+		t.Errorf("expected 1 enabled subscription, got %d", len(resp.Data))
+	}
+}
+
+func TestServer_EventSub_Validation(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddAppToken("app-token")
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		token     string
+		subType   string
+		transport helix.CreateEventSubTransport
+	}{
+		{"unknown type", "streamer-token", "nope", helix.CreateEventSubTransport{Method: "websocket", SessionID: "s"}},
+		{"websocket with app token", "app-token", helix.EventSubTypeStreamOnline, helix.CreateEventSubTransport{Method: "websocket", SessionID: "s"}},
+		{"websocket without session", "streamer-token", helix.EventSubTypeStreamOnline, helix.CreateEventSubTransport{Method: "websocket"}},
+		{"webhook with user token", "streamer-token", helix.EventSubTypeStreamOnline, helix.CreateEventSubTransport{Method: "webhook", Callback: "https://example.com", Secret: "0123456789"}},
+		{"webhook over http", "app-token", helix.EventSubTypeStreamOnline, helix.CreateEventSubTransport{Method: "webhook", Callback: "http://example.com", Secret: "0123456789"}},
+		{"short secret", "app-token", helix.EventSubTypeStreamOnline, helix.CreateEventSubTransport{Method: "webhook", Callback: "https://example.com", Secret: "short"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := srv.Client(tt.token).SubscribeToChannel(ctx, tt.subType, "1", tt.transport)
+			if !errors.Is(err, helix.ErrBadRequest) {
+				t.Errorf("expected ErrBadRequest, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,440 @@
+package helixtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// DefaultKeepaliveTimeout is the keepalive timeout, in seconds, that an
+// EventSubServer reports in session_welcome messages.
+const DefaultKeepaliveTimeout = 10
+
+var (
+	// ErrUnknownSession is returned when a WebSocket session doesn't exist or has
+	// disconnected.
+	ErrUnknownSession = errors.New("helixtest: unknown websocket session")
+	// ErrUnknownSubscription is returned when a subscription doesn't exist.
+	ErrUnknownSubscription = errors.New("helixtest: unknown subscription")
+)
+
+// closeReasons are the close frame texts and resulting subscription statuses for
+// Twitch's close codes.
+var closeReasons = map[int]struct{ text, status string }{
+	helix.WSCloseInternalError:         {"internal server error", "websocket_internal_error"},
+	helix.WSCloseClientSentInbound:     {"sent inbound traffic", "websocket_received_inbound_traffic"},
+	helix.WSCloseClientFailedPingPong:  {"ping pong failed", "websocket_failed_ping_pong"},
+	helix.WSCloseConnectionUnused:      {"connection unused", "websocket_connection_unused"},
+	helix.WSCloseReconnectGraceExpired: {"reconnect grace time expired", "websocket_failed_to_reconnect"},
+	helix.WSCloseNetworkTimeout:        {"network timeout", "websocket_network_timeout"},
+	helix.WSCloseNetworkError:          {"network error", "websocket_network_error"},
+	helix.WSCloseInvalidReconnect:      {"invalid reconnect", "websocket_failed_to_reconnect"},
+}
+
+// EventSubServer is a fake EventSub WebSocket server paired with a Server. Clients
+// connect to URL and receive a session_welcome; subscriptions created on the paired
+// Server with that session ID then receive the notifications sent with Notify.
+//
+// Tests script the session lifecycle with Reconnect, Revoke and CloseSession:
+//
+//	api := helixtest.NewServer()
+//	defer api.Close()
+//	es := helixtest.NewEventSubServer(api)
+//	defer es.Close()
+//
+//	ws := helix.NewEventSubWebSocketClient(helix.WithWSURL(es.URL), ...)
+//	sessionID, _ := ws.Connect(ctx)
+//	api.Client("token").SubscribeToChannel(ctx, helix.EventSubTypeStreamOnline, "1",
+//	    helix.CreateEventSubTransport{Method: "websocket", SessionID: sessionID})
+//
+//	es.Notify(helix.EventSubTypeStreamOnline, helix.BroadcasterCondition("1"), event)
+//	es.CloseSession(sessionID, helix.WSCloseNetworkError)
+//
+// All methods are safe for concurrent use.
+type EventSubServer struct {
+	// URL is the WebSocket URL of the server, for helix.WithWSURL.
+	URL string
+
+	api               *Server
+	srv               *httptest.Server
+	upgrader          websocket.Upgrader
+	keepaliveTimeout  int
+	keepaliveInterval time.Duration
+	messageID         atomic.Int64
+
+	mu           sync.Mutex
+	nextID       int
+	sessions     map[string]*wsSession
+	reconnecting map[string]bool
+}
+
+// EventSubServerOption configures an EventSubServer.
+type EventSubServerOption func(*EventSubServer)
+
+// WithKeepaliveTimeout sets the keepalive timeout, in seconds, reported in
+// session_welcome messages. Keepalives are sent at this interval unless
+// WithKeepaliveInterval is also used.
+func WithKeepaliveTimeout(seconds int) EventSubServerOption {
+	return func(e *EventSubServer) {
+		e.keepaliveTimeout = seconds
+	}
+}
+
+// WithKeepaliveInterval sets how long a session may be idle before the server sends
+// a session_keepalive, independently of the reported timeout. Zero disables
+// keepalives.
+func WithKeepaliveInterval(d time.Duration) EventSubServerOption {
+	return func(e *EventSubServer) {
+		e.keepaliveInterval = d
+	}
+}
+
+// wsSession is one client connection. A session keeps its ID across a reconnect,
+// but each connection gets a new wsSession.
+type wsSession struct {
+	id          string
+	conn        *websocket.Conn
+	connectedAt time.Time
+	done        chan struct{}
+	closeOnce   sync.Once
+
+	writeMu   sync.Mutex
+	lastWrite time.Time
+}
+
+// NewEventSubServer starts a fake EventSub WebSocket server paired with api, which
+// then only accepts WebSocket subscriptions for connected sessions. Call Close
+// when done.
+func NewEventSubServer(api *Server, opts ...EventSubServerOption) *EventSubServer {
+	e := &EventSubServer{
+		api:              api,
+		keepaliveTimeout: DefaultKeepaliveTimeout,
+		sessions:         make(map[string]*wsSession),
+		reconnecting:     make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.keepaliveInterval == 0 {
+		e.keepaliveInterval = time.Duration(e.keepaliveTimeout) * time.Second
+	}
+
+	e.srv = httptest.NewServer(http.HandlerFunc(e.serveWS))
+	e.URL = "ws" + strings.TrimPrefix(e.srv.URL, "http")
+
+	api.mu.Lock()
+	api.eventSub = e
+	api.mu.Unlock()
+	return e
+}
+
+// Close disconnects all sessions and shuts down the server.
+func (e *EventSubServer) Close() {
+	e.mu.Lock()
+	sessions := make([]*wsSession, 0, len(e.sessions))
+	for _, sess := range e.sessions {
+		sessions = append(sessions, sess)
+	}
+	e.mu.Unlock()
+
+	for _, sess := range sessions {
+		sess.close(websocket.CloseGoingAway, "server shutting down")
+	}
+	e.srv.Close()
+}
+
+// Sessions returns the IDs of the sessions that are connected or reconnecting.
+func (e *EventSubServer) Sessions() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ids := make([]string, 0, len(e.sessions))
+	for id := range e.sessions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Notify sends a notification with event to every enabled WebSocket subscription of
+// subType whose condition includes all of condition's entries. A nil condition
+// matches every subscription of the type. It returns the number of notifications
+// sent.
+func (e *EventSubServer) Notify(subType string, condition map[string]string, event interface{}) (int, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("marshaling event: %w", err)
+	}
+
+	sent := 0
+	for _, sub := range e.api.websocketSubscriptions(subType, condition) {
+		payload := helix.WebSocketNotificationPayload{Subscription: sub, Event: data}
+		if e.send(sub.Transport.SessionID, helix.WSMessageTypeNotification, &sub, payload) == nil {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// Reconnect sends a session_reconnect message. The session keeps its ID and
+// subscriptions when the client connects to the reconnect URL.
+func (e *EventSubServer) Reconnect(sessionID string) error {
+	e.mu.Lock()
+	sess, ok := e.sessions[sessionID]
+	if ok {
+		e.reconnecting[sessionID] = true
+	}
+	e.mu.Unlock()
+	if !ok {
+		return ErrUnknownSession
+	}
+
+	return e.send(sessionID, helix.WSMessageTypeReconnect, nil, helix.WebSocketReconnectPayload{
+		Session: helix.WebSocketSession{
+			ID:           sessionID,
+			Status:       "reconnecting",
+			ConnectedAt:  sess.connectedAt,
+			ReconnectURL: e.URL + "?reconnect=" + sessionID,
+		},
+	})
+}
+
+// Revoke sets a subscription's status to reason, such as "authorization_revoked"
+// or "user_removed", and sends a revocation message if it is a WebSocket
+// subscription.
+func (e *EventSubServer) Revoke(subscriptionID, reason string) error {
+	sub, ok := e.api.revokeSubscription(subscriptionID, reason)
+	if !ok {
+		return ErrUnknownSubscription
+	}
+	if sub.Transport.Method != "websocket" {
+		return nil
+	}
+	return e.send(sub.Transport.SessionID, helix.WSMessageTypeRevocation, &sub, helix.WebSocketNotificationPayload{Subscription: sub})
+}
+
+// CloseSession closes a session's connection with a close code, such as
+// helix.WSCloseNetworkError, and disables its subscriptions with the status Twitch
+// uses for the code.
+func (e *EventSubServer) CloseSession(sessionID string, code int) error {
+	e.mu.Lock()
+	sess, ok := e.sessions[sessionID]
+	delete(e.sessions, sessionID)
+	delete(e.reconnecting, sessionID)
+	e.mu.Unlock()
+	if !ok {
+		return ErrUnknownSession
+	}
+
+	reason, ok := closeReasons[code]
+	if !ok {
+		reason.status = "websocket_disconnected"
+	}
+	sess.close(code, reason.text)
+	e.api.disconnectSession(sessionID, reason.status)
+	return nil
+}
+
+// hasSession reports whether a session is connected or reconnecting.
+func (e *EventSubServer) hasSession(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.sessions[id]
+	return ok
+}
+
+func (e *EventSubServer) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := e.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	sess := &wsSession{conn: conn, connectedAt: time.Now().UTC(), done: make(chan struct{})}
+
+	e.mu.Lock()
+	var old *wsSession
+	if id := r.URL.Query().Get("reconnect"); id != "" {
+		if !e.reconnecting[id] {
+			e.mu.Unlock()
+			reason := closeReasons[helix.WSCloseInvalidReconnect]
+			sess.close(helix.WSCloseInvalidReconnect, reason.text)
+			return
+		}
+		delete(e.reconnecting, id)
+		sess.id, old = id, e.sessions[id]
+	} else {
+		e.nextID++
+		sess.id = fmt.Sprintf("session-%d", e.nextID)
+	}
+	// Hold the write lock until the welcome is sent so it is the first message
+	sess.writeMu.Lock()
+	e.sessions[sess.id] = sess
+	e.mu.Unlock()
+
+	err = e.write(sess, helix.WSMessageTypeWelcome, nil, helix.WebSocketWelcomePayload{
+		Session: helix.WebSocketSession{
+			ID:                      sess.id,
+			Status:                  "connected",
+			ConnectedAt:             sess.connectedAt,
+			KeepaliveTimeoutSeconds: e.keepaliveTimeout,
+		},
+	})
+	sess.writeMu.Unlock()
+	if old != nil {
+		old.close(websocket.CloseNormalClosure, "")
+	}
+	if err != nil {
+		sess.close(websocket.CloseInternalServerErr, "")
+	}
+
+	go e.keepalive(sess)
+	go e.readLoop(sess)
+}
+
+// readLoop waits for the connection to close, closing it with 4001 if the client
+// sends a message. Twitch doesn't accept inbound traffic.
+func (e *EventSubServer) readLoop(sess *wsSession) {
+	if _, _, err := sess.conn.ReadMessage(); err == nil {
+		e.mu.Lock()
+		if e.sessions[sess.id] == sess {
+			delete(e.sessions, sess.id)
+		}
+		e.mu.Unlock()
+		reason := closeReasons[helix.WSCloseClientSentInbound]
+		sess.close(helix.WSCloseClientSentInbound, reason.text)
+		e.api.disconnectSession(sess.id, reason.status)
+		return
+	}
+
+	// A session waiting for its client to reconnect keeps its subscriptions
+	e.mu.Lock()
+	disconnected := e.sessions[sess.id] == sess && !e.reconnecting[sess.id]
+	if disconnected {
+		delete(e.sessions, sess.id)
+	}
+	e.mu.Unlock()
+	sess.close(websocket.CloseNormalClosure, "")
+	if disconnected {
+		e.api.disconnectSession(sess.id, "websocket_disconnected")
+	}
+}
+
+// keepalive sends a session_keepalive whenever the session has been idle for the
+// keepalive interval.
+func (e *EventSubServer) keepalive(sess *wsSession) {
+	if e.keepaliveInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(e.keepaliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sess.done:
+			return
+		case <-ticker.C:
+			sess.writeMu.Lock()
+			if time.Since(sess.lastWrite) >= e.keepaliveInterval {
+				_ = e.write(sess, helix.WSMessageTypeKeepalive, nil, struct{}{})
+			}
+			sess.writeMu.Unlock()
+		}
+	}
+}
+
+// send writes a message to a session's current connection.
+func (e *EventSubServer) send(sessionID, messageType string, sub *helix.EventSubSubscription, payload interface{}) error {
+	e.mu.Lock()
+	sess, ok := e.sessions[sessionID]
+	e.mu.Unlock()
+	if !ok {
+		return ErrUnknownSession
+	}
+	sess.writeMu.Lock()
+	defer sess.writeMu.Unlock()
+	return e.write(sess, messageType, sub, payload)
+}
+
+// write writes a message to a session. The caller must hold sess.writeMu.
+func (e *EventSubServer) write(sess *wsSession, messageType string, sub *helix.EventSubSubscription, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	msg := helix.WebSocketMessage{
+		Metadata: helix.WebSocketMetadata{
+			MessageID:        fmt.Sprintf("message-%d", e.messageID.Add(1)),
+			MessageType:      messageType,
+			MessageTimestamp: time.Now().UTC(),
+		},
+		Payload: data,
+	}
+	if sub != nil {
+		msg.Metadata.SubscriptionType = sub.Type
+		msg.Metadata.SubscriptionVersion = sub.Version
+	}
+	sess.lastWrite = time.Now()
+	return sess.conn.WriteJSON(msg)
+}
+
+// close sends a close frame with code and text and closes the connection.
+func (s *wsSession) close(code int, text string) {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		msg := websocket.FormatCloseMessage(code, text)
+		_ = s.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		_ = s.conn.Close()
+	})
+}
+
+// websocketSubscriptions returns the enabled WebSocket subscriptions of subType
+// whose condition includes all of condition's entries.
+func (s *Server) websocketSubscriptions(subType string, condition map[string]string) []helix.EventSubSubscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var subs []helix.EventSubSubscription
+	for _, sub := range s.subscriptions {
+		if sub.Type != subType || sub.Transport.Method != "websocket" || sub.Status != subscriptionEnabled {
+			continue
+		}
+		matches := true
+		for key, v := range condition {
+			matches = matches && sub.Condition[key] == v
+		}
+		if matches {
+			subs = append(subs, sub.EventSubSubscription)
+		}
+	}
+	return subs
+}
+
+// revokeSubscription sets a subscription's status and returns it.
+func (s *Server) revokeSubscription(id, status string) (helix.EventSubSubscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.subscriptions {
+		if sub.ID == id {
+			sub.Status = status
+			return sub.EventSubSubscription, true
+		}
+	}
+	return helix.EventSubSubscription{}, false
+}
+
+// disconnectSession disables the enabled subscriptions of a WebSocket session.
+func (s *Server) disconnectSession(sessionID, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.subscriptions {
+		if sub.Transport.SessionID == sessionID && sub.Status == subscriptionEnabled {
+			sub.Status = status
+			sub.Transport.DisconnectedAt = s.now().UTC().Format(time.RFC3339)
+		}
+	}
+}
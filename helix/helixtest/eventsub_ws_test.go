@@ -0,0 +1,257 @@
+package helixtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// newTestEventSubServer returns an EventSubServer paired with a test server.
+func newTestEventSubServer(t *testing.T, opts ...EventSubServerOption) (*Server, *EventSubServer) {
+	t.Helper()
+	api := newTestServer(t)
+	es := NewEventSubServer(api, opts...)
+	t.Cleanup(es.Close)
+	return api, es
+}
+
+// subscribe creates a stream.online subscription for broadcasterID on a session.
+func subscribe(t *testing.T, api *Server, sessionID, broadcasterID string) *helix.EventSubSubscription {
+	t.Helper()
+	sub, err := api.Client("streamer-token").SubscribeToChannel(context.Background(), helix.EventSubTypeStreamOnline, broadcasterID,
+		helix.CreateEventSubTransport{Method: "websocket", SessionID: sessionID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return sub
+}
+
+// dial connects to the server and reads the welcome message.
+func dial(t *testing.T, url string) (*websocket.Conn, helix.WebSocketSession) {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	var msg helix.WebSocketMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("reading welcome: %v", err)
+	}
+	if msg.Metadata.MessageType != helix.WSMessageTypeWelcome {
+		t.Fatalf("expected welcome, got %s", msg.Metadata.MessageType)
+	}
+	var payload helix.WebSocketWelcomePayload
+	_ = json.Unmarshal(msg.Payload, &payload)
+	return conn, payload.Session
+}
+
+// expectClose reads from conn until it is closed and returns the close code.
+func expectClose(t *testing.T, conn *websocket.Conn) int {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		var closeErr *websocket.CloseError
+		if errors.As(err, &closeErr) {
+			return closeErr.Code
+		}
+		if err != nil {
+			t.Fatalf("expected a close frame, got %v", err)
+		}
+	}
+}
+
+func TestEventSubServer_Notify(t *testing.T) {
+	api, es := newTestEventSubServer(t)
+	events := make(chan json.RawMessage, 1)
+	ws := helix.NewEventSubWebSocketClient(
+		helix.WithWSURL(es.URL),
+		helix.WithWSNotificationHandler(func(sub *helix.EventSubSubscription, event json.RawMessage) {
+			events <- event
+		}),
+	)
+	sessionID, err := ws.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() { _ = ws.Close() }()
+
+	subscribe(t, api, sessionID, "1")
+
+	n, err := es.Notify(helix.EventSubTypeStreamOnline, helix.BroadcasterCondition("2"), map[string]string{})
+	if err != nil || n != 0 {
+		t.Errorf("expected no notification for another broadcaster, got %d, %v", n, err)
+	}
+	n, err = es.Notify(helix.EventSubTypeStreamOnline, helix.BroadcasterCondition("1"), map[string]string{"broadcaster_user_id": "1", "type": "live"})
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 notification, got %d, %v", n, err)
+	}
+
+	select {
+	case event := <-events:
+		if !strings.Contains(string(event), `"type":"live"`) {
+			t.Errorf("unexpected event: %s", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+}
+
+func TestEventSubServer_UnknownSession(t *testing.T) {
+	api, _ := newTestEventSubServer(t)
+	_, err := api.Client("streamer-token").SubscribeToChannel(context.Background(), helix.EventSubTypeStreamOnline, "1",
+		helix.CreateEventSubTransport{Method: "websocket", SessionID: "nope"})
+	if !errors.Is(err, helix.ErrBadRequest) {
+		t.Errorf("expected ErrBadRequest, got %v", err)
+	}
+}
+
+func TestEventSubServer_Keepalive(t *testing.T) {
+	_, es := newTestEventSubServer(t, WithKeepaliveInterval(20*time.Millisecond))
+	keepalives := make(chan struct{}, 10)
+	ws := helix.NewEventSubWebSocketClient(
+		helix.WithWSURL(es.URL),
+		helix.WithWSKeepaliveHandler(func() { keepalives <- struct{}{} }),
+	)
+	if _, err := ws.Connect(context.Background()); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() { _ = ws.Close() }()
+
+	select {
+	case <-keepalives:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for keepalive")
+	}
+}
+
+func TestEventSubServer_Reconnect(t *testing.T) {
+	api, es := newTestEventSubServer(t)
+	reconnected := make(chan string, 1)
+	var ws *helix.EventSubWebSocketClient
+	ws = helix.NewEventSubWebSocketClient(
+		helix.WithWSURL(es.URL),
+		helix.WithWSReconnectHandler(func(url string) {
+			go func() {
+				id, err := ws.Reconnect(context.Background(), url)
+				if err != nil {
+					t.Errorf("reconnect failed: %v", err)
+				}
+				reconnected <- id
+			}()
+		}),
+	)
+	sessionID, err := ws.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() { _ = ws.Close() }()
+	sub := subscribe(t, api, sessionID, "1")
+
+	if err := es.Reconnect(sessionID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case id := <-reconnected:
+		if id != sessionID {
+			t.Errorf("expected session %s after reconnect, got %s", sessionID, id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnect")
+	}
+
+	if subs := api.Subscriptions(); subs[0].ID != sub.ID || subs[0].Status != "enabled" {
+		t.Errorf("expected the subscription to survive the reconnect, got %+v", subs[0])
+	}
+	if n, _ := es.Notify(helix.EventSubTypeStreamOnline, nil, struct{}{}); n != 1 {
+		t.Errorf("expected 1 notification on the new connection, got %d", n)
+	}
+}
+
+func TestEventSubServer_InvalidReconnect(t *testing.T) {
+	_, es := newTestEventSubServer(t)
+	conn, _, err := websocket.DefaultDialer.Dial(es.URL+"?reconnect=session-1", nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if code := expectClose(t, conn); code != helix.WSCloseInvalidReconnect {
+		t.Errorf("expected close code %d, got %d", helix.WSCloseInvalidReconnect, code)
+	}
+}
+
+func TestEventSubServer_Revoke(t *testing.T) {
+	api, es := newTestEventSubServer(t)
+	revoked := make(chan *helix.EventSubSubscription, 1)
+	ws := helix.NewEventSubWebSocketClient(
+		helix.WithWSURL(es.URL),
+		helix.WithWSRevocationHandler(func(sub *helix.EventSubSubscription) { revoked <- sub }),
+	)
+	sessionID, err := ws.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer func() { _ = ws.Close() }()
+	sub := subscribe(t, api, sessionID, "1")
+
+	if err := es.Revoke(sub.ID, "authorization_revoked"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case got := <-revoked:
+		if got.ID != sub.ID || got.Status != "authorization_revoked" {
+			t.Errorf("unexpected revocation: %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for revocation")
+	}
+	if !errors.Is(es.Revoke("nope", "user_removed"), ErrUnknownSubscription) {
+		t.Error("expected ErrUnknownSubscription")
+	}
+}
+
+func TestEventSubServer_CloseSession(t *testing.T) {
+	api, es := newTestEventSubServer(t)
+	conn, session := dial(t, es.URL)
+	subscribe(t, api, session.ID, "1")
+
+	if err := es.CloseSession(session.ID, helix.WSCloseNetworkTimeout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code := expectClose(t, conn); code != helix.WSCloseNetworkTimeout {
+		t.Errorf("expected close code %d, got %d", helix.WSCloseNetworkTimeout, code)
+	}
+
+	sub := api.Subscriptions()[0]
+	if sub.Status != "websocket_network_timeout" || sub.Transport.DisconnectedAt == "" {
+		t.Errorf("expected a disconnected subscription, got %+v", sub)
+	}
+	if len(es.Sessions()) != 0 {
+		t.Errorf("expected no sessions, got %v", es.Sessions())
+	}
+	if !errors.Is(es.CloseSession(session.ID, helix.WSCloseNetworkError), ErrUnknownSession) {
+		t.Error("expected ErrUnknownSession")
+	}
+}
+
+func TestEventSubServer_InboundTraffic(t *testing.T) {
+	_, es := newTestEventSubServer(t)
+	conn, _ := dial(t, es.URL)
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if code := expectClose(t, conn); code != helix.WSCloseClientSentInbound {
+		t.Errorf("expected close code %d, got %d", helix.WSCloseClientSentInbound, code)
+	}
+}
@@ -0,0 +1,234 @@
+package helixtest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// maxBanDuration is the longest timeout Twitch allows, in seconds.
+const maxBanDuration = 1209600
+
+// AddModerator makes userID a moderator of broadcasterID's channel.
+func (s *Server) AddModerator(broadcasterID, userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isModerator(broadcasterID, userID) {
+		s.moderators[broadcasterID] = append(s.moderators[broadcasterID], userID)
+	}
+}
+
+// Moderators returns the moderators of a channel in the order they were added.
+func (s *Server) Moderators(broadcasterID string) []helix.Moderator {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.moderatorList(broadcasterID)
+}
+
+// Bans returns the bans and timeouts in a channel, including expired timeouts.
+func (s *Server) Bans(broadcasterID string) []helix.BannedUser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]helix.BannedUser(nil), s.bans[broadcasterID]...)
+}
+
+func (s *Server) isModerator(broadcasterID, userID string) bool {
+	return containsString(s.moderators[broadcasterID], userID)
+}
+
+// canModerate reports whether userID is the broadcaster or one of their moderators.
+func (s *Server) canModerate(broadcasterID, userID string) bool {
+	return userID == broadcasterID || s.isModerator(broadcasterID, userID)
+}
+
+func (s *Server) moderatorList(broadcasterID string) []helix.Moderator {
+	mods := make([]helix.Moderator, 0, len(s.moderators[broadcasterID]))
+	for _, id := range s.moderators[broadcasterID] {
+		login, name := s.userLabels(id)
+		mods = append(mods, helix.Moderator{UserID: id, UserLogin: login, UserName: name})
+	}
+	return mods
+}
+
+// activeBan returns the index of userID's ban in the channel, or -1 if they are not
+// banned or their timeout has expired.
+func (s *Server) activeBan(broadcasterID, userID string) int {
+	now := s.now()
+	for i, b := range s.bans[broadcasterID] {
+		if b.UserID == userID && (b.ExpiresAt.IsZero() || now.Before(b.ExpiresAt)) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) getBannedUsers(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok {
+		return
+	}
+	if !s.canModerate(broadcasterID, c.userID) {
+		writeError(w, http.StatusForbidden, "The user in the OAuth token is not the broadcaster or one of their moderators.")
+		return
+	}
+	userIDs := r.URL.Query()["user_id"]
+
+	var bans []helix.BannedUser
+	for _, b := range s.bans[broadcasterID] {
+		if s.activeBan(broadcasterID, b.UserID) >= 0 && matchesAny(userIDs, b.UserID) {
+			bans = append(bans, b)
+		}
+	}
+	writePage(w, r, bans)
+}
+
+func (s *Server) banUser(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok {
+		return
+	}
+	moderatorID, ok := requireParam(w, r, "moderator_id")
+	if !ok || !requireUser(w, c, "moderator_id", moderatorID) {
+		return
+	}
+	if !s.canModerate(broadcasterID, moderatorID) {
+		writeError(w, http.StatusForbidden, "The user in moderator_id is not one of the broadcaster's moderators.")
+		return
+	}
+	var params helix.BanUserParams
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	userID := params.Data.UserID
+	if userID == "" {
+		writeError(w, http.StatusBadRequest, `Missing required field "user_id"`)
+		return
+	}
+	if params.Data.Duration < 0 || params.Data.Duration > maxBanDuration {
+		writeError(w, http.StatusBadRequest, "The value in the duration field is not valid.")
+		return
+	}
+	if userID == broadcasterID || s.isModerator(broadcasterID, userID) {
+		writeError(w, http.StatusBadRequest, "The user specified in the user_id field may not be banned.")
+		return
+	}
+	if s.activeBan(broadcasterID, userID) >= 0 {
+		writeError(w, http.StatusBadRequest, "The user specified in the user_id field is already banned.")
+		return
+	}
+
+	now := s.now().UTC()
+	login, name := s.userLabels(userID)
+	modLogin, modName := s.userLabels(moderatorID)
+	ban := helix.BannedUser{
+		UserID:         userID,
+		UserLogin:      login,
+		UserName:       name,
+		CreatedAt:      now,
+		Reason:         params.Data.Reason,
+		ModeratorID:    moderatorID,
+		ModeratorLogin: modLogin,
+		ModeratorName:  modName,
+	}
+	if params.Data.Duration > 0 {
+		ban.ExpiresAt = now.Add(time.Duration(params.Data.Duration) * time.Second)
+	}
+	s.bans[broadcasterID] = append(s.bans[broadcasterID], ban)
+
+	writeData(w, http.StatusOK, helix.BanUserResponse{
+		BroadcasterID: broadcasterID,
+		ModeratorID:   moderatorID,
+		UserID:        userID,
+		CreatedAt:     ban.CreatedAt,
+		EndTime:       ban.ExpiresAt,
+	})
+}
+
+func (s *Server) unbanUser(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok {
+		return
+	}
+	moderatorID, ok := requireParam(w, r, "moderator_id")
+	if !ok || !requireUser(w, c, "moderator_id", moderatorID) {
+		return
+	}
+	userID, ok := requireParam(w, r, "user_id")
+	if !ok {
+		return
+	}
+	if !s.canModerate(broadcasterID, moderatorID) {
+		writeError(w, http.StatusForbidden, "The user in moderator_id is not one of the broadcaster's moderators.")
+		return
+	}
+
+	i := s.activeBan(broadcasterID, userID)
+	if i < 0 {
+		writeError(w, http.StatusBadRequest, "The user specified in the user_id field is not banned.")
+		return
+	}
+	s.bans[broadcasterID] = append(s.bans[broadcasterID][:i], s.bans[broadcasterID][i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getModerators(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok || !requireUser(w, c, "broadcaster_id", broadcasterID) {
+		return
+	}
+	userIDs := r.URL.Query()["user_id"]
+
+	var mods []helix.Moderator
+	for _, m := range s.moderatorList(broadcasterID) {
+		if matchesAny(userIDs, m.UserID) {
+			mods = append(mods, m)
+		}
+	}
+	writePage(w, r, mods)
+}
+
+func (s *Server) addModerator(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok || !requireUser(w, c, "broadcaster_id", broadcasterID) {
+		return
+	}
+	userID, ok := requireParam(w, r, "user_id")
+	if !ok {
+		return
+	}
+	if _, ok := s.users[userID]; !ok {
+		writeError(w, http.StatusBadRequest, "The ID in user_id was not found.")
+		return
+	}
+	if s.isModerator(broadcasterID, userID) {
+		writeError(w, http.StatusBadRequest, "The user specified in the user_id field is already a moderator.")
+		return
+	}
+	if s.activeBan(broadcasterID, userID) >= 0 {
+		writeError(w, http.StatusBadRequest, "The user specified in the user_id field is banned.")
+		return
+	}
+	s.moderators[broadcasterID] = append(s.moderators[broadcasterID], userID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) removeModerator(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok || !requireUser(w, c, "broadcaster_id", broadcasterID) {
+		return
+	}
+	userID, ok := requireParam(w, r, "user_id")
+	if !ok {
+		return
+	}
+	mods := s.moderators[broadcasterID]
+	for i, id := range mods {
+		if id == userID {
+			s.moderators[broadcasterID] = append(mods[:i], mods[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusBadRequest, "The user specified in the user_id field is not a moderator.")
+}
@@ -0,0 +1,121 @@
+package helixtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+func TestServer_BanUser(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeModeratorManageBannedUsers)
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+	ban := &helix.BanUserParams{BroadcasterID: "1", ModeratorID: "1", Data: helix.BanUserData{UserID: "2", Reason: "spam"}}
+
+	if _, err := client.BanUser(ctx, ban); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.BanUser(ctx, ban); !errors.Is(err, helix.ErrAlreadyBanned) {
+		t.Errorf("expected ErrAlreadyBanned, got %v", err)
+	}
+
+	resp, err := client.GetBannedUsers(ctx, &helix.GetBannedUsersParams{BroadcasterID: "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].UserLogin != "viewer" || resp.Data[0].Reason != "spam" {
+		t.Errorf("unexpected bans: %+v", resp.Data)
+	}
+
+	if err := client.UnbanUser(ctx, "1", "1", "2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.UnbanUser(ctx, "1", "1", "2"); !errors.Is(err, helix.ErrNotBanned) {
+		t.Errorf("expected ErrNotBanned, got %v", err)
+	}
+}
+
+func TestServer_BanUser_Timeout(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeModeratorManageBannedUsers)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv.now = func() time.Time { return now }
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+
+	resp, err := client.BanUser(ctx, &helix.BanUserParams{BroadcasterID: "1", ModeratorID: "1", Data: helix.BanUserData{UserID: "2", Duration: 60}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.EndTime.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected end time one minute from now, got %v", resp.EndTime)
+	}
+
+	now = now.Add(2 * time.Minute)
+	bans, err := client.GetBannedUsers(ctx, &helix.GetBannedUsersParams{BroadcasterID: "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bans.Data) != 0 {
+		t.Errorf("expected the timeout to have expired, got %+v", bans.Data)
+	}
+}
+
+func TestServer_BanUser_Moderator(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddUser(helix.User{ID: "3", Login: "mod"})
+	srv.AddUserToken("mod-token", "3", helix.ScopeModeratorManageBannedUsers)
+	client := srv.Client("mod-token")
+	ctx := context.Background()
+	ban := &helix.BanUserParams{BroadcasterID: "1", ModeratorID: "3", Data: helix.BanUserData{UserID: "2"}}
+
+	if _, err := client.BanUser(ctx, ban); !errors.Is(err, helix.ErrForbidden) {
+		t.Errorf("expected ErrForbidden before being made a moderator, got %v", err)
+	}
+
+	srv.AddModerator("1", "3")
+	if _, err := client.BanUser(ctx, ban); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bans := srv.Bans("1"); len(bans) != 1 || bans[0].ModeratorLogin != "mod" {
+		t.Errorf("unexpected bans: %+v", bans)
+	}
+
+	ban.ModeratorID = "1"
+	if _, err := client.BanUser(ctx, ban); !errors.Is(err, helix.ErrForbidden) {
+		t.Errorf("expected ErrForbidden for a moderator_id that isn't the token's user, got %v", err)
+	}
+}
+
+func TestServer_Moderators(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeChannelManageModerators)
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+
+	if err := client.AddChannelModerator(ctx, "1", "2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.AddChannelModerator(ctx, "1", "2"); !errors.Is(err, helix.ErrAlreadyModerator) {
+		t.Errorf("expected ErrAlreadyModerator, got %v", err)
+	}
+
+	resp, err := client.GetModerators(ctx, &helix.GetModeratorsParams{BroadcasterID: "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].UserID != "2" {
+		t.Errorf("unexpected moderators: %+v", resp.Data)
+	}
+
+	if err := client.RemoveChannelModerator(ctx, "1", "2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.RemoveChannelModerator(ctx, "1", "2"); !errors.Is(err, helix.ErrNotModerator) {
+		t.Errorf("expected ErrNotModerator, got %v", err)
+	}
+	if mods := srv.Moderators("1"); len(mods) != 0 {
+		t.Errorf("expected no moderators, got %+v", mods)
+	}
+}
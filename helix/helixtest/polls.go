@@ -0,0 +1,294 @@
+package helixtest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// Poll and prediction statuses.
+const (
+	statusActive     = "ACTIVE"
+	statusCompleted  = "COMPLETED"
+	statusTerminated = "TERMINATED"
+	statusArchived   = "ARCHIVED"
+	statusLocked     = "LOCKED"
+	statusResolved   = "RESOLVED"
+	statusCanceled   = "CANCELED"
+)
+
+// Polls returns a channel's polls, newest first.
+func (s *Server) Polls(broadcasterID string) []helix.Poll {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	polls := make([]helix.Poll, len(s.polls[broadcasterID]))
+	for i, p := range s.polls[broadcasterID] {
+		polls[i] = clonePoll(p)
+	}
+	return polls
+}
+
+// SetPollVotes sets the number of votes for a choice, as if viewers had voted.
+// It returns false if the poll or choice doesn't exist.
+func (s *Server) SetPollVotes(broadcasterID, pollID, choiceID string, votes int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.findPoll(broadcasterID, pollID)
+	if p == nil {
+		return false
+	}
+	for i := range p.Choices {
+		if p.Choices[i].ID == choiceID {
+			p.Choices[i].Votes = votes
+			return true
+		}
+	}
+	return false
+}
+
+// Predictions returns a channel's predictions, newest first.
+func (s *Server) Predictions(broadcasterID string) []helix.Prediction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	predictions := make([]helix.Prediction, len(s.predictions[broadcasterID]))
+	for i, p := range s.predictions[broadcasterID] {
+		predictions[i] = clonePrediction(p)
+	}
+	return predictions
+}
+
+// clonePoll returns a copy of p that doesn't share its choices.
+func clonePoll(p *helix.Poll) helix.Poll {
+	c := *p
+	c.Choices = append([]helix.PollChoice(nil), p.Choices...)
+	return c
+}
+
+// clonePrediction returns a copy of p that doesn't share its outcomes.
+func clonePrediction(p *helix.Prediction) helix.Prediction {
+	c := *p
+	c.Outcomes = append([]helix.PredictionOutcome(nil), p.Outcomes...)
+	return c
+}
+
+// findPoll returns a channel's poll by ID, ending it first if its time is up.
+func (s *Server) findPoll(broadcasterID, id string) *helix.Poll {
+	for _, p := range s.polls[broadcasterID] {
+		if p.ID == id {
+			s.expirePoll(p)
+			return p
+		}
+	}
+	return nil
+}
+
+// expirePoll completes an active poll whose duration has passed.
+func (s *Server) expirePoll(p *helix.Poll) {
+	end := p.StartedAt.Add(time.Duration(p.Duration) * time.Second)
+	if p.Status == statusActive && !s.now().Before(end) {
+		p.Status = statusCompleted
+		p.EndedAt = end
+	}
+}
+
+func (s *Server) getPolls(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok || !requireUser(w, c, "broadcaster_id", broadcasterID) {
+		return
+	}
+	ids := r.URL.Query()["id"]
+
+	var polls []helix.Poll
+	for _, p := range s.polls[broadcasterID] {
+		s.expirePoll(p)
+		if matchesAny(ids, p.ID) {
+			polls = append(polls, clonePoll(p))
+		}
+	}
+	writePage(w, r, polls)
+}
+
+func (s *Server) createPoll(w http.ResponseWriter, r *http.Request, c *caller) {
+	var params helix.CreatePollParams
+	if !decodeBody(w, r, &params) || !requireUser(w, c, "broadcaster_id", params.BroadcasterID) {
+		return
+	}
+	switch {
+	case params.Title == "" || len(params.Title) > 60:
+		writeError(w, http.StatusBadRequest, "The title field must contain 1 to 60 characters.")
+		return
+	case len(params.Choices) < 2 || len(params.Choices) > 5:
+		writeError(w, http.StatusBadRequest, "The poll must contain 2 to 5 choices.")
+		return
+	case params.Duration < 15 || params.Duration > 1800:
+		writeError(w, http.StatusBadRequest, "The duration field must be between 15 and 1800 seconds.")
+		return
+	}
+	for _, p := range s.polls[params.BroadcasterID] {
+		s.expirePoll(p)
+		if p.Status == statusActive {
+			writeError(w, http.StatusBadRequest, "The broadcaster already has an active poll.")
+			return
+		}
+	}
+
+	login, name := s.userLabels(params.BroadcasterID)
+	poll := &helix.Poll{
+		ID:                         s.newID(),
+		BroadcasterID:              params.BroadcasterID,
+		BroadcasterLogin:           login,
+		BroadcasterName:            name,
+		Title:                      params.Title,
+		ChannelPointsVotingEnabled: params.ChannelPointsVotingEnabled,
+		ChannelPointsPerVote:       params.ChannelPointsPerVote,
+		Status:                     statusActive,
+		Duration:                   params.Duration,
+		StartedAt:                  s.now().UTC(),
+	}
+	for _, choice := range params.Choices {
+		poll.Choices = append(poll.Choices, helix.PollChoice{ID: s.newID(), Title: choice.Title})
+	}
+	s.polls[params.BroadcasterID] = append([]*helix.Poll{poll}, s.polls[params.BroadcasterID]...)
+	writeData(w, http.StatusOK, clonePoll(poll))
+}
+
+func (s *Server) endPoll(w http.ResponseWriter, r *http.Request, c *caller) {
+	var params helix.EndPollParams
+	if !decodeBody(w, r, &params) || !requireUser(w, c, "broadcaster_id", params.BroadcasterID) {
+		return
+	}
+	if params.Status != statusTerminated && params.Status != statusArchived {
+		writeError(w, http.StatusBadRequest, "The status field must be TERMINATED or ARCHIVED.")
+		return
+	}
+	p := s.findPoll(params.BroadcasterID, params.ID)
+	if p == nil {
+		writeError(w, http.StatusNotFound, "The poll was not found.")
+		return
+	}
+	if params.Status == statusTerminated && p.Status != statusActive {
+		writeError(w, http.StatusBadRequest, "The poll is not active.")
+		return
+	}
+
+	if p.Status == statusActive {
+		p.EndedAt = s.now().UTC()
+	}
+	p.Status = params.Status
+	writeData(w, http.StatusOK, clonePoll(p))
+}
+
+func (s *Server) getPredictions(w http.ResponseWriter, r *http.Request, c *caller) {
+	broadcasterID, ok := requireParam(w, r, "broadcaster_id")
+	if !ok || !requireUser(w, c, "broadcaster_id", broadcasterID) {
+		return
+	}
+	ids := r.URL.Query()["id"]
+
+	var predictions []helix.Prediction
+	for _, p := range s.predictions[broadcasterID] {
+		if matchesAny(ids, p.ID) {
+			predictions = append(predictions, clonePrediction(p))
+		}
+	}
+	writePage(w, r, predictions)
+}
+
+func (s *Server) createPrediction(w http.ResponseWriter, r *http.Request, c *caller) {
+	var params helix.CreatePredictionParams
+	if !decodeBody(w, r, &params) || !requireUser(w, c, "broadcaster_id", params.BroadcasterID) {
+		return
+	}
+	switch {
+	case params.Title == "" || len(params.Title) > 45:
+		writeError(w, http.StatusBadRequest, "The title field must contain 1 to 45 characters.")
+		return
+	case len(params.Outcomes) < 2 || len(params.Outcomes) > 10:
+		writeError(w, http.StatusBadRequest, "The prediction must contain 2 to 10 outcomes.")
+		return
+	case params.PredictionWindow < 30 || params.PredictionWindow > 1800:
+		writeError(w, http.StatusBadRequest, "The prediction_window field must be between 30 and 1800 seconds.")
+		return
+	}
+	for _, p := range s.predictions[params.BroadcasterID] {
+		if p.Status == statusActive || p.Status == statusLocked {
+			writeError(w, http.StatusBadRequest, "The broadcaster already has an active prediction.")
+			return
+		}
+	}
+
+	login, name := s.userLabels(params.BroadcasterID)
+	prediction := &helix.Prediction{
+		ID:               s.newID(),
+		BroadcasterID:    params.BroadcasterID,
+		BroadcasterLogin: login,
+		BroadcasterName:  name,
+		Title:            params.Title,
+		PredictionWindow: params.PredictionWindow,
+		Status:           statusActive,
+		CreatedAt:        s.now().UTC(),
+	}
+	for i, outcome := range params.Outcomes {
+		color := "PINK"
+		if i == 0 {
+			color = "BLUE"
+		}
+		prediction.Outcomes = append(prediction.Outcomes, helix.PredictionOutcome{ID: s.newID(), Title: outcome.Title, Color: color})
+	}
+	s.predictions[params.BroadcasterID] = append([]*helix.Prediction{prediction}, s.predictions[params.BroadcasterID]...)
+	writeData(w, http.StatusOK, clonePrediction(prediction))
+}
+
+func (s *Server) endPrediction(w http.ResponseWriter, r *http.Request, c *caller) {
+	var params helix.EndPredictionParams
+	if !decodeBody(w, r, &params) || !requireUser(w, c, "broadcaster_id", params.BroadcasterID) {
+		return
+	}
+	var p *helix.Prediction
+	for _, candidate := range s.predictions[params.BroadcasterID] {
+		if candidate.ID == params.ID {
+			p = candidate
+		}
+	}
+	if p == nil {
+		writeError(w, http.StatusNotFound, "The prediction was not found.")
+		return
+	}
+
+	now := s.now().UTC()
+	switch params.Status {
+	case statusLocked:
+		if p.Status != statusActive {
+			writeError(w, http.StatusBadRequest, "Only an active prediction can be locked.")
+			return
+		}
+		p.LockedAt = now
+	case statusResolved:
+		if p.Status != statusActive && p.Status != statusLocked {
+			writeError(w, http.StatusBadRequest, "The prediction has already ended.")
+			return
+		}
+		found := false
+		for _, o := range p.Outcomes {
+			found = found || o.ID == params.WinningOutcomeID
+		}
+		if !found {
+			writeError(w, http.StatusBadRequest, "The winning_outcome_id field must be one of the prediction's outcomes.")
+			return
+		}
+		p.WinningOutcomeID = params.WinningOutcomeID
+		p.EndedAt = now
+	case statusCanceled:
+		if p.Status != statusActive && p.Status != statusLocked {
+			writeError(w, http.StatusBadRequest, "The prediction has already ended.")
+			return
+		}
+		p.EndedAt = now
+	default:
+		writeError(w, http.StatusBadRequest, "The status field must be RESOLVED, CANCELED or LOCKED.")
+		return
+	}
+	p.Status = params.Status
+	writeData(w, http.StatusOK, clonePrediction(p))
+}
@@ -0,0 +1,127 @@
+package helixtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+func TestServer_Polls(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeChannelManagePolls)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv.now = func() time.Time { return now }
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+	params := &helix.CreatePollParams{
+		BroadcasterID: "1",
+		Title:         "Best?",
+		Choices:       []helix.CreatePollChoice{{Title: "A"}, {Title: "B"}},
+		Duration:      60,
+	}
+
+	poll, err := client.CreatePoll(ctx, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if poll.Status != "ACTIVE" || len(poll.Choices) != 2 || poll.Choices[0].ID == "" {
+		t.Fatalf("unexpected poll: %+v", poll)
+	}
+	if _, err := client.CreatePoll(ctx, params); !errors.Is(err, helix.ErrBadRequest) {
+		t.Errorf("expected ErrBadRequest while a poll is active, got %v", err)
+	}
+
+	if !srv.SetPollVotes("1", poll.ID, poll.Choices[1].ID, 7) {
+		t.Fatal("expected SetPollVotes to find the choice")
+	}
+	now = now.Add(time.Minute)
+	resp, err := client.GetPolls(ctx, &helix.GetPollsParams{BroadcasterID: "1", IDs: []string{poll.ID}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := resp.Data[0]; got.Status != "COMPLETED" || got.Choices[1].Votes != 7 {
+		t.Errorf("expected a completed poll with 7 votes, got %+v", got)
+	}
+
+	if _, err := client.EndPoll(ctx, &helix.EndPollParams{BroadcasterID: "1", ID: poll.ID, Status: "TERMINATED"}); !errors.Is(err, helix.ErrBadRequest) {
+		t.Errorf("expected ErrBadRequest terminating a completed poll, got %v", err)
+	}
+	ended, err := client.EndPoll(ctx, &helix.EndPollParams{BroadcasterID: "1", ID: poll.ID, Status: "ARCHIVED"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ended.Status != "ARCHIVED" {
+		t.Errorf("expected ARCHIVED, got %s", ended.Status)
+	}
+}
+
+func TestServer_CreatePoll_Validation(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeChannelManagePolls)
+	client := srv.Client("streamer-token")
+
+	tests := []struct {
+		name   string
+		params helix.CreatePollParams
+	}{
+		{"one choice", helix.CreatePollParams{BroadcasterID: "1", Title: "Q", Choices: []helix.CreatePollChoice{{Title: "A"}}, Duration: 60}},
+		{"short duration", helix.CreatePollParams{BroadcasterID: "1", Title: "Q", Choices: []helix.CreatePollChoice{{Title: "A"}, {Title: "B"}}, Duration: 5}},
+		{"no title", helix.CreatePollParams{BroadcasterID: "1", Choices: []helix.CreatePollChoice{{Title: "A"}, {Title: "B"}}, Duration: 60}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.CreatePoll(context.Background(), &tt.params); !errors.Is(err, helix.ErrBadRequest) {
+				t.Errorf("expected ErrBadRequest, got %v", err)
+			}
+		})
+	}
+}
+
+func TestServer_Predictions(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeChannelManagePredictions)
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+
+	prediction, err := client.CreatePrediction(ctx, &helix.CreatePredictionParams{
+		BroadcasterID:    "1",
+		Title:            "Win?",
+		Outcomes:         []helix.CreatePredictionOutcome{{Title: "Yes"}, {Title: "No"}},
+		PredictionWindow: 120,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prediction.Outcomes[0].Color != "BLUE" || prediction.Outcomes[1].Color != "PINK" {
+		t.Errorf("unexpected outcome colors: %+v", prediction.Outcomes)
+	}
+
+	locked, err := client.EndPrediction(ctx, &helix.EndPredictionParams{BroadcasterID: "1", ID: prediction.ID, Status: "LOCKED"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if locked.Status != "LOCKED" || locked.LockedAt.IsZero() {
+		t.Errorf("expected a locked prediction, got %+v", locked)
+	}
+
+	_, err = client.EndPrediction(ctx, &helix.EndPredictionParams{BroadcasterID: "1", ID: prediction.ID, Status: "RESOLVED", WinningOutcomeID: "nope"})
+	if !errors.Is(err, helix.ErrBadRequest) {
+		t.Errorf("expected ErrBadRequest for an unknown outcome, got %v", err)
+	}
+	resolved, err := client.EndPrediction(ctx, &helix.EndPredictionParams{
+		BroadcasterID:    "1",
+		ID:               prediction.ID,
+		Status:           "RESOLVED",
+		WinningOutcomeID: prediction.Outcomes[0].ID,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved.WinningOutcomeID != prediction.Outcomes[0].ID {
+		t.Errorf("expected winning outcome %s, got %s", prediction.Outcomes[0].ID, resolved.WinningOutcomeID)
+	}
+
+	if got := srv.Predictions("1"); len(got) != 1 || got[0].Status != "RESOLVED" {
+		t.Errorf("unexpected stored predictions: %+v", got)
+	}
+}
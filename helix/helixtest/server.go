@@ -0,0 +1,423 @@
+// Package helixtest provides a stateful, in-process fake of the Twitch Helix API for
+// testing code built on helix.Client.
+//
+// The fake covers users, games, channels, streams, moderation, polls, predictions,
+// channel points custom rewards and EventSub subscriptions. It checks the
+// Authorization and Client-Id headers and token scopes like Twitch does, sends
+// rate limit headers, paginates with cursors, and lets tests seed and inspect state:
+//
+//	srv := helixtest.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddUser(helix.User{ID: "1", Login: "streamer"})
+//	srv.AddUserToken("streamer-token", "1", helix.ScopeChannelManagePolls)
+//
+//	client := srv.Client("streamer-token")
+//	poll, err := client.CreatePoll(ctx, &helix.CreatePollParams{...})
+//
+//	polls := srv.Polls("1")
+package helixtest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// DefaultClientID is the client ID the server accepts unless Server.ClientID is changed.
+const DefaultClientID = "helixtest-client-id"
+
+// DefaultRateLimit is the number of points each token gets per minute.
+const DefaultRateLimit = 800
+
+// Server is a fake Helix API. Create one with NewServer and point a client at it
+// with helix.WithBaseURL(srv.URL), or use Server.Client.
+//
+// All methods are safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the fake API.
+	URL string
+	// ClientID is the client ID that requests must send in the Client-Id header.
+	// Change it before registering tokens.
+	ClientID string
+
+	srv    *httptest.Server
+	routes map[string]handlerFunc
+
+	mu        sync.Mutex
+	now       func() time.Time
+	nextID    int
+	tokens    map[string]*token
+	rateLimit int
+	buckets   map[string]*bucket
+
+	users         map[string]*helix.User
+	userOrder     []string
+	games         []helix.Game
+	channels      map[string]*helix.Channel
+	streams       []*helix.Stream
+	bans          map[string][]helix.BannedUser
+	moderators    map[string][]string
+	polls         map[string][]*helix.Poll
+	predictions   map[string][]*helix.Prediction
+	rewards       map[string][]*customReward
+	subscriptions []*subscription
+	eventSub      *EventSubServer // Set by NewEventSubServer
+}
+
+// token is an access token the server accepts.
+type token struct {
+	userID   string // Empty for app access tokens
+	clientID string
+	scopes   []string
+}
+
+// bucket is the rate limit state for one token.
+type bucket struct {
+	remaining int
+	reset     time.Time
+}
+
+// caller is the authenticated identity making a request.
+type caller struct {
+	token    string
+	userID   string // Empty for app access tokens
+	clientID string
+	scopes   []string
+}
+
+// hasScope reports whether the caller's token has the scope.
+func (c *caller) hasScope(scope string) bool {
+	for _, s := range c.scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// handlerFunc handles an authenticated request. It runs with Server.mu held.
+type handlerFunc func(w http.ResponseWriter, r *http.Request, c *caller)
+
+// NewServer starts a fake Helix API server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		ClientID:    DefaultClientID,
+		now:         time.Now,
+		tokens:      make(map[string]*token),
+		rateLimit:   DefaultRateLimit,
+		buckets:     make(map[string]*bucket),
+		users:       make(map[string]*helix.User),
+		channels:    make(map[string]*helix.Channel),
+		bans:        make(map[string][]helix.BannedUser),
+		moderators:  make(map[string][]string),
+		polls:       make(map[string][]*helix.Poll),
+		predictions: make(map[string][]*helix.Prediction),
+		rewards:     make(map[string][]*customReward),
+	}
+	s.routes = map[string]handlerFunc{
+		"GET /users":                            s.getUsers,
+		"PUT /users":                            s.updateUser,
+		"GET /games":                            s.getGames,
+		"GET /channels":                         s.getChannels,
+		"PATCH /channels":                       s.modifyChannel,
+		"GET /streams":                          s.getStreams,
+		"GET /moderation/banned":                s.getBannedUsers,
+		"POST /moderation/bans":                 s.banUser,
+		"DELETE /moderation/bans":               s.unbanUser,
+		"GET /moderation/moderators":            s.getModerators,
+		"POST /moderation/moderators":           s.addModerator,
+		"DELETE /moderation/moderators":         s.removeModerator,
+		"GET /polls":                            s.getPolls,
+		"POST /polls":                           s.createPoll,
+		"PATCH /polls":                          s.endPoll,
+		"GET /predictions":                      s.getPredictions,
+		"POST /predictions":                     s.createPrediction,
+		"PATCH /predictions":                    s.endPrediction,
+		"GET /channel_points/custom_rewards":    s.getCustomRewards,
+		"POST /channel_points/custom_rewards":   s.createCustomReward,
+		"PATCH /channel_points/custom_rewards":  s.updateCustomReward,
+		"DELETE /channel_points/custom_rewards": s.deleteCustomReward,
+		"GET /eventsub/subscriptions":           s.getSubscriptions,
+		"POST /eventsub/subscriptions":          s.createSubscription,
+		"DELETE /eventsub/subscriptions":        s.deleteSubscription,
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a helix.Client pointed at the server and authenticated with
+// accessToken. Retries are disabled so that errors are returned immediately.
+func (s *Server) Client(accessToken string, opts ...helix.Option) *helix.Client {
+	authClient := helix.NewAuthClient(helix.AuthConfig{ClientID: s.ClientID})
+	authClient.SetToken(&helix.Token{AccessToken: accessToken, TokenType: "bearer"})
+
+	opts = append([]helix.Option{
+		helix.WithBaseURL(s.URL),
+		helix.WithRetry(false, 0),
+		helix.WithTokenRefresh(false),
+	}, opts...)
+	return helix.NewClient(s.ClientID, authClient, opts...)
+}
+
+// AddUserToken registers a user access token for userID with the given scopes.
+func (s *Server) AddUserToken(accessToken, userID string, scopes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[accessToken] = &token{userID: userID, clientID: s.ClientID, scopes: scopes}
+}
+
+// AddAppToken registers an app access token.
+func (s *Server) AddAppToken(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[accessToken] = &token{clientID: s.ClientID}
+}
+
+// RevokeToken makes the server reject accessToken as invalid.
+func (s *Server) RevokeToken(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, accessToken)
+	delete(s.buckets, accessToken)
+}
+
+// SetRateLimit sets the number of points each token gets per minute and refills
+// all buckets.
+func (s *Server) SetRateLimit(points int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimit = points
+	s.buckets = make(map[string]*bucket)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handler, ok := s.routes[r.Method+" "+r.URL.Path]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	c, status, message := s.authenticate(r)
+	if c == nil {
+		writeError(w, status, message)
+		return
+	}
+
+	if !s.takePoint(w, c) {
+		writeError(w, http.StatusTooManyRequests, "Too Many Requests")
+		return
+	}
+
+	if req, ok := helix.ScopeRequirementFor(r.Method, r.URL.Path); ok {
+		if c.userID == "" {
+			writeError(w, http.StatusUnauthorized, "Missing User OAUTH Token")
+			return
+		}
+		if missing := req.Missing(c.scopes); missing != nil {
+			writeError(w, http.StatusUnauthorized, "Missing scope: "+strings.Join(missing, " or "))
+			return
+		}
+	}
+
+	handler(w, r, c)
+}
+
+// authenticate checks the Authorization and Client-Id headers. It returns the
+// caller, or nil with the status and message to reply with.
+func (s *Server) authenticate(r *http.Request) (*caller, int, string) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return nil, http.StatusUnauthorized, "OAuth token is missing"
+	}
+	accessToken, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok {
+		return nil, http.StatusUnauthorized, "Invalid OAuth token"
+	}
+	clientID := r.Header.Get("Client-Id")
+	if clientID == "" {
+		return nil, http.StatusUnauthorized, "Client-Id header required"
+	}
+
+	t, ok := s.tokens[accessToken]
+	if !ok {
+		return nil, http.StatusUnauthorized, "Invalid OAuth token"
+	}
+	if t.clientID != clientID {
+		return nil, http.StatusUnauthorized, "Client ID and OAuth token do not match"
+	}
+	return &caller{token: accessToken, userID: t.userID, clientID: t.clientID, scopes: t.scopes}, 0, ""
+}
+
+// takePoint takes a point from the caller's bucket and sets the rate limit headers.
+// It returns false if the bucket is empty.
+func (s *Server) takePoint(w http.ResponseWriter, c *caller) bool {
+	now := s.now()
+	b, ok := s.buckets[c.token]
+	if !ok || !now.Before(b.reset) {
+		b = &bucket{remaining: s.rateLimit, reset: now.Add(time.Minute)}
+		s.buckets[c.token] = b
+	}
+
+	allowed := b.remaining > 0
+	if allowed {
+		b.remaining--
+	}
+	w.Header().Set("Ratelimit-Limit", strconv.Itoa(s.rateLimit))
+	w.Header().Set("Ratelimit-Remaining", strconv.Itoa(b.remaining))
+	w.Header().Set("Ratelimit-Reset", strconv.FormatInt(b.reset.Unix(), 10))
+	return allowed
+}
+
+// newID returns a new sequential ID.
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+// writeError writes a Helix error response.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, helix.ErrorResponse{
+		Error:   http.StatusText(status),
+		Status:  status,
+		Message: message,
+	})
+}
+
+// writeJSON writes v as the JSON response body.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeData writes a Response with data as its only field.
+func writeData[T any](w http.ResponseWriter, status int, data ...T) {
+	if data == nil {
+		data = []T{}
+	}
+	writeJSON(w, status, helix.Response[T]{Data: data})
+}
+
+// decodeBody decodes the JSON request body into v, replying with 400 on failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Malformed request body")
+		return false
+	}
+	return true
+}
+
+// requireParam returns a required query parameter, replying with 400 if it is missing.
+func requireParam(w http.ResponseWriter, r *http.Request, name string) (string, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Missing required parameter %q", name))
+		return "", false
+	}
+	return v, true
+}
+
+// requireUser replies with 403 unless the caller's token belongs to userID.
+func requireUser(w http.ResponseWriter, c *caller, param, userID string) bool {
+	if c.userID != userID {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("The ID in %s must match the user ID found in the request's OAuth token.", param))
+		return false
+	}
+	return true
+}
+
+// paginate returns the page of items selected by the first and after query
+// parameters, and the pagination to return with it.
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) ([]T, *helix.Pagination, bool) {
+	first := 20
+	if v := r.URL.Query().Get("first"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			writeError(w, http.StatusBadRequest, "The value in the first field must be between 1 and 100")
+			return nil, nil, false
+		}
+		first = n
+	}
+
+	start := 0
+	if after := r.URL.Query().Get("after"); after != "" {
+		n, ok := decodeCursor(after)
+		if !ok || n > len(items) {
+			writeError(w, http.StatusBadRequest, "Invalid cursor")
+			return nil, nil, false
+		}
+		start = n
+	}
+
+	end := min(start+first, len(items))
+	pagination := &helix.Pagination{}
+	if end < len(items) {
+		pagination.Cursor = encodeCursor(end)
+	}
+	return items[start:end], pagination, true
+}
+
+// encodeCursor returns an opaque cursor for an offset.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of a cursor from encodeCursor.
+func decodeCursor(cursor string) (int, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	v, ok := strings.CutPrefix(string(b), "offset:")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	return n, err == nil && n >= 0
+}
+
+// writePage writes the page of items selected by the request's first and after
+// parameters.
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	page, pagination, ok := paginate(w, r, items)
+	if !ok {
+		return
+	}
+	if page == nil {
+		page = []T{}
+	}
+	writeJSON(w, http.StatusOK, helix.Response[T]{Data: page, Pagination: pagination})
+}
+
+// containsString reports whether values contains v.
+func containsString(values []string, v string) bool {
+	for _, want := range values {
+		if want == v {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny reports whether values contains v, or values is empty.
+func matchesAny(values []string, v string) bool {
+	return len(values) == 0 || containsString(values, v)
+}
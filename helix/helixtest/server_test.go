@@ -0,0 +1,152 @@
+package helixtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// newTestServer returns a server with a broadcaster "1" (streamer) and a viewer
+// "2" (viewer). The broadcaster's token "streamer-token" has the given scopes.
+func newTestServer(t *testing.T, scopes ...string) *Server {
+	t.Helper()
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddUser(helix.User{ID: "1", Login: "streamer", DisplayName: "Streamer"})
+	srv.AddUser(helix.User{ID: "2", Login: "viewer", DisplayName: "Viewer"})
+	srv.AddUserToken("streamer-token", "1", scopes...)
+	return srv
+}
+
+func TestServer_RequiresHeaders(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		auth     string
+		clientID string
+	}{
+		{"missing token", "", DefaultClientID},
+		{"not bearer", "OAuth streamer-token", DefaultClientID},
+		{"missing client id", "Bearer streamer-token", ""},
+		{"wrong client id", "Bearer streamer-token", "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/users", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if tt.clientID != "" {
+				req.Header.Set("Client-Id", tt.clientID)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("expected 401, got %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestServer_InvalidToken(t *testing.T) {
+	srv := newTestServer(t)
+	srv.RevokeToken("streamer-token")
+
+	_, err := srv.Client("streamer-token").GetUsers(context.Background(), nil)
+	if !errors.Is(err, helix.ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestServer_MissingScope(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddAppToken("app-token")
+	params := &helix.CreatePollParams{
+		BroadcasterID: "1",
+		Title:         "Best?",
+		Choices:       []helix.CreatePollChoice{{Title: "A"}, {Title: "B"}},
+		Duration:      60,
+	}
+
+	_, err := srv.Client("streamer-token").CreatePoll(context.Background(), params)
+	if !errors.Is(err, helix.ErrMissingScope) {
+		t.Errorf("expected ErrMissingScope, got %v", err)
+	}
+
+	_, err = srv.Client("app-token").CreatePoll(context.Background(), params)
+	if !errors.Is(err, helix.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized for app token, got %v", err)
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	srv := newTestServer(t)
+	srv.SetRateLimit(2)
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetUsers(ctx, nil); err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+	}
+	if info := client.GetRateLimitInfo(); info.Limit != 2 || info.Remaining != 0 {
+		t.Errorf("expected limit 2 and 0 remaining, got %+v", info)
+	}
+
+	_, err := client.GetUsers(ctx, nil)
+	if !errors.Is(err, helix.ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestServer_Pagination(t *testing.T) {
+	srv := newTestServer(t)
+	for i := 0; i < 25; i++ {
+		srv.StartStream(helix.Stream{UserID: "streamer" + string(rune('a'+i))})
+	}
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+
+	var seen []string
+	params := &helix.GetStreamsParams{PaginationParams: &helix.PaginationParams{First: 10}}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not end")
+		}
+		resp, err := client.GetStreams(ctx, params)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, st := range resp.Data {
+			seen = append(seen, st.UserID)
+		}
+		if resp.Pagination == nil || resp.Pagination.Cursor == "" {
+			break
+		}
+		params.After = resp.Pagination.Cursor
+	}
+	if len(seen) != 25 || seen[0] != "streamera" || seen[24] != "streamery" {
+		t.Errorf("expected 25 streams in order, got %v", seen)
+	}
+
+	params.After = "not-a-cursor"
+	if _, err := client.GetStreams(ctx, params); !errors.Is(err, helix.ErrBadRequest) {
+		t.Errorf("expected ErrBadRequest for a bad cursor, got %v", err)
+	}
+}
+
+func TestServer_UnknownEndpoint(t *testing.T) {
+	srv := newTestServer(t)
+	err := srv.Client("streamer-token").Do(context.Background(), &helix.Request{Method: http.MethodGet, Endpoint: "/nope"}, nil)
+	if !errors.Is(err, helix.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package helixtest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+// AddUser adds or replaces a user and creates an empty channel for them. An ID is
+// generated if u.ID is empty, Login defaults to the lowercased DisplayName and
+// DisplayName to Login. It returns the stored user.
+func (s *Server) AddUser(u helix.User) helix.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u.ID == "" {
+		u.ID = s.newID()
+	}
+	if u.Login == "" {
+		u.Login = strings.ToLower(u.DisplayName)
+	}
+	if u.DisplayName == "" {
+		u.DisplayName = u.Login
+	}
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = s.now().UTC()
+	}
+
+	if _, ok := s.users[u.ID]; !ok {
+		s.userOrder = append(s.userOrder, u.ID)
+	}
+	s.users[u.ID] = &u
+	if _, ok := s.channels[u.ID]; !ok {
+		s.channels[u.ID] = &helix.Channel{
+			BroadcasterID:       u.ID,
+			BroadcasterLanguage: "en",
+			Tags:                []string{},
+		}
+	}
+	s.channels[u.ID].BroadcasterLogin = u.Login
+	s.channels[u.ID].BroadcasterName = u.DisplayName
+	return u
+}
+
+// User returns the user with the given ID.
+func (s *Server) User(id string) (helix.User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return helix.User{}, false
+	}
+	return *u, true
+}
+
+// Users returns all users in the order they were added.
+func (s *Server) Users() []helix.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := make([]helix.User, 0, len(s.userOrder))
+	for _, id := range s.userOrder {
+		users = append(users, *s.users[id])
+	}
+	return users
+}
+
+// AddGame adds a game or category. An ID is generated if g.ID is empty.
+func (s *Server) AddGame(g helix.Game) helix.Game {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if g.ID == "" {
+		g.ID = s.newID()
+	}
+	s.games = append(s.games, g)
+	return g
+}
+
+// userLabels returns the login and display name of a user, or empty strings for an
+// unknown user.
+func (s *Server) userLabels(id string) (login, name string) {
+	if u, ok := s.users[id]; ok {
+		return u.Login, u.DisplayName
+	}
+	return "", ""
+}
+
+// gameName returns the name of a game, or an empty string for an unknown game.
+func (s *Server) gameName(id string) string {
+	for _, g := range s.games {
+		if g.ID == id {
+			return g.Name
+		}
+	}
+	return ""
+}
+
+func (s *Server) getUsers(w http.ResponseWriter, r *http.Request, c *caller) {
+	q := r.URL.Query()
+	ids, logins := q["id"], q["login"]
+	if len(ids)+len(logins) > 100 {
+		writeError(w, http.StatusBadRequest, "The combined number of id and login parameters must not exceed 100")
+		return
+	}
+	if len(ids)+len(logins) == 0 {
+		if c.userID == "" {
+			writeError(w, http.StatusBadRequest, "Must provide an ID, Login or OAuth Token")
+			return
+		}
+		ids = []string{c.userID}
+	}
+
+	var users []helix.User
+	for _, id := range s.userOrder {
+		u := *s.users[id]
+		if !containsString(ids, u.ID) && !containsString(logins, u.Login) {
+			continue
+		}
+		// Email is only visible to the user themselves with user:read:email
+		if u.ID != c.userID || !c.hasScope(helix.ScopeUserReadEmail) {
+			u.Email = ""
+		}
+		users = append(users, u)
+	}
+	writeData(w, http.StatusOK, users...)
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request, c *caller) {
+	u := s.users[c.userID]
+	if u == nil {
+		writeError(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if description, ok := r.URL.Query()["description"]; ok {
+		u.Description = description[0]
+	}
+	writeData(w, http.StatusOK, *u)
+}
+
+func (s *Server) getGames(w http.ResponseWriter, r *http.Request, c *caller) {
+	q := r.URL.Query()
+	ids, names, igdbIDs := q["id"], q["name"], q["igdb_id"]
+	if len(ids)+len(names)+len(igdbIDs) > 100 {
+		writeError(w, http.StatusBadRequest, "The combined number of id, name and igdb_id parameters must not exceed 100")
+		return
+	}
+
+	var games []helix.Game
+	for _, g := range s.games {
+		if containsString(ids, g.ID) || containsString(names, g.Name) || (g.IGDBId != "" && containsString(igdbIDs, g.IGDBId)) {
+			games = append(games, g)
+		}
+	}
+	writeData(w, http.StatusOK, games...)
+}
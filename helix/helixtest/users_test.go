@@ -0,0 +1,75 @@
+package helixtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Its-donkey/kappopher/helix"
+)
+
+func TestServer_GetUsers(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddUser(helix.User{ID: "1", Login: "streamer", DisplayName: "Streamer", Email: "streamer@example.com"})
+	client := srv.Client("streamer-token")
+	ctx := context.Background()
+
+	resp, err := client.GetUsers(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != "1" {
+		t.Fatalf("expected the token's user, got %+v", resp.Data)
+	}
+	if resp.Data[0].Email != "" {
+		t.Error("expected email to be hidden without user:read:email")
+	}
+
+	resp, err = client.GetUsers(ctx, &helix.GetUsersParams{IDs: []string{"2"}, Logins: []string{"streamer", "nobody"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 2 {
+		t.Errorf("expected 2 users, got %d", len(resp.Data))
+	}
+}
+
+func TestServer_GetUsers_Email(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeUserReadEmail)
+	srv.AddUser(helix.User{ID: "1", Login: "streamer", Email: "streamer@example.com"})
+
+	resp, err := srv.Client("streamer-token").GetUsers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Data[0].Email != "streamer@example.com" {
+		t.Errorf("expected email with user:read:email, got %q", resp.Data[0].Email)
+	}
+}
+
+func TestServer_UpdateUser(t *testing.T) {
+	srv := newTestServer(t, helix.ScopeUserEdit)
+
+	user, err := srv.Client("streamer-token").UpdateUser(context.Background(), &helix.UpdateUserParams{Description: "hello"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.Description != "hello" {
+		t.Errorf("expected description hello, got %q", user.Description)
+	}
+	if u, _ := srv.User("1"); u.Description != "hello" {
+		t.Errorf("expected stored description hello, got %q", u.Description)
+	}
+}
+
+func TestServer_GetGames(t *testing.T) {
+	srv := newTestServer(t)
+	game := srv.AddGame(helix.Game{Name: "Just Chatting"})
+
+	resp, err := srv.Client("streamer-token").GetGames(context.Background(), &helix.GetGamesParams{Names: []string{"Just Chatting"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != game.ID {
+		t.Errorf("expected game %s, got %+v", game.ID, resp.Data)
+	}
+}
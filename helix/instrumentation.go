@@ -0,0 +1,163 @@
+package helix
+
+import (
+	"context"
+	"strings"
+)
+
+// Instrumentation receives spans and counters from every part of the package:
+// Helix requests, auth requests, IRC, EventSub WebSocket and EventSub webhooks.
+// It is modeled on OpenTelemetry so an adapter to a tracer and meter is a few lines;
+// NewPrometheusInstrumentation provides one for Prometheus.
+//
+// Implementations must be safe for concurrent use.
+type Instrumentation interface {
+	// StartSpan starts a span, returning a context carrying it for child spans.
+	StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+	// AddCounter adds value to the counter with the given name and attributes.
+	AddCounter(ctx context.Context, name string, value int64, attrs ...Attribute)
+}
+
+// Span is an operation started by Instrumentation.StartSpan.
+type Span interface {
+	// SetAttributes adds attributes known after the span started, such as the response status.
+	SetAttributes(attrs ...Attribute)
+	// RecordError marks the span as failed.
+	RecordError(err error)
+	// End completes the span. Other methods must not be called afterwards.
+	End()
+}
+
+// Attribute is a key-value pair describing a span or counter.
+type Attribute struct {
+	Key   string
+	Value interface{} // string, int64 or bool
+}
+
+// StringAttr returns a string attribute.
+func StringAttr(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// IntAttr returns an integer attribute.
+func IntAttr(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// BoolAttr returns a boolean attribute.
+func BoolAttr(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span names.
+const (
+	SpanHelixRequest        = "helix.request"              // One HTTP attempt of a Helix API request
+	SpanAuthRequest         = "auth.request"               // One request to the OAuth or OIDC endpoints
+	SpanIRCConnect          = "irc.connect"                // Connecting and authenticating to IRC
+	SpanEventSubConnect     = "eventsub.websocket.connect" // Connecting to EventSub WebSocket until the welcome message
+	SpanEventSubWebhookCall = "eventsub.webhook.request"   // Handling one webhook request
+)
+
+// Counter names.
+const (
+	CounterHelixRequests    = "helix.requests"    // HTTP attempts of Helix API requests
+	CounterHelixRetries     = "helix.retries"     // Retries of Helix API requests, by reason
+	CounterAuthRequests     = "auth.requests"     // Requests to the OAuth or OIDC endpoints
+	CounterIRCMessages      = "irc.messages"      // IRC messages sent and received
+	CounterIRCReconnects    = "irc.reconnects"    // IRC reconnect attempts
+	CounterEventSubMessages = "eventsub.messages" // EventSub messages received over any transport
+)
+
+// Attribute keys.
+const (
+	AttrMethod             = "http.method"
+	AttrStatus             = "http.status_code"
+	AttrEndpoint           = "helix.endpoint"
+	AttrRetryAttempt       = "helix.retry_attempt" // 0 for the first attempt
+	AttrRetryReason        = "helix.retry_reason"  // "rate_limit", "token_refresh" or "transient"
+	AttrRateLimitRemaining = "helix.ratelimit_remaining"
+	AttrAuthOperation      = "auth.operation" // e.g. "token", "validate", "revoke"
+	AttrIRCCommand         = "irc.command"    // e.g. "PRIVMSG", "JOIN"
+	AttrIRCDirection       = "irc.direction"  // "in" or "out"
+	AttrEventSubTransport  = "eventsub.transport"
+	AttrEventSubMessage    = "eventsub.message_type" // e.g. "notification", "revocation"
+	AttrEventSubType       = "eventsub.type"         // Subscription type, e.g. "channel.follow"
+)
+
+// MultiInstrumentation returns an Instrumentation that sends everything to each of
+// instruments, for example a tracer adapter and NewPrometheusInstrumentation.
+func MultiInstrumentation(instruments ...Instrumentation) Instrumentation {
+	return multiInstrumentation(instruments)
+}
+
+type multiInstrumentation []Instrumentation
+
+func (m multiInstrumentation) StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	spans := make(multiSpan, len(m))
+	for i, instr := range m {
+		ctx, spans[i] = instr.StartSpan(ctx, name, attrs...)
+	}
+	return ctx, spans
+}
+
+func (m multiInstrumentation) AddCounter(ctx context.Context, name string, value int64, attrs ...Attribute) {
+	for _, instr := range m {
+		instr.AddCounter(ctx, name, value, attrs...)
+	}
+}
+
+type multiSpan []Span
+
+func (m multiSpan) SetAttributes(attrs ...Attribute) {
+	for _, s := range m {
+		s.SetAttributes(attrs...)
+	}
+}
+
+func (m multiSpan) RecordError(err error) {
+	for _, s := range m {
+		s.RecordError(err)
+	}
+}
+
+func (m multiSpan) End() {
+	for _, s := range m {
+		s.End()
+	}
+}
+
+// nopInstrumentation discards everything. It is used when no Instrumentation is configured.
+type nopInstrumentation struct{}
+
+func (nopInstrumentation) StartSpan(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+func (nopInstrumentation) AddCounter(context.Context, string, int64, ...Attribute) {}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...Attribute) {}
+func (nopSpan) RecordError(error)          {}
+func (nopSpan) End()                       {}
+
+// instrumentationOrNop returns instr, or a no-op Instrumentation if it is nil.
+func instrumentationOrNop(instr Instrumentation) Instrumentation {
+	if instr == nil {
+		return nopInstrumentation{}
+	}
+	return instr
+}
+
+// ircCommand returns the command of a raw outgoing IRC line for instrumentation.
+func ircCommand(line string) string {
+	if strings.HasPrefix(line, "@") {
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			line = line[i+1:]
+		}
+	}
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		line = line[:i]
+	}
+	return strings.ToUpper(line)
+}
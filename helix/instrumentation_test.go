@@ -0,0 +1,363 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// recordingInstrumentation records spans and counters for assertions.
+type recordingInstrumentation struct {
+	mu       sync.Mutex
+	spans    []*recordedSpan
+	counters []recordedCounter
+}
+
+type recordedSpan struct {
+	rec   *recordingInstrumentation
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+type recordedCounter struct {
+	name  string
+	value int64
+	attrs map[string]interface{}
+}
+
+func (r *recordingInstrumentation) StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &recordedSpan{rec: r, name: name, attrs: make(map[string]interface{})}
+	s.SetAttributes(attrs...)
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+	return ctx, s
+}
+
+func (r *recordingInstrumentation) AddCounter(_ context.Context, name string, value int64, attrs ...Attribute) {
+	c := recordedCounter{name: name, value: value, attrs: make(map[string]interface{})}
+	for _, attr := range attrs {
+		c.attrs[attr.Key] = attr.Value
+	}
+	r.mu.Lock()
+	r.counters = append(r.counters, c)
+	r.mu.Unlock()
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.rec.mu.Lock()
+	s.err = err
+	s.rec.mu.Unlock()
+}
+
+func (s *recordedSpan) End() {
+	s.rec.mu.Lock()
+	s.ended = true
+	s.rec.mu.Unlock()
+}
+
+// spansNamed returns the ended spans with the given name.
+func (r *recordingInstrumentation) spansNamed(name string) []*recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var spans []*recordedSpan
+	for _, s := range r.spans {
+		if s.name == name && s.ended {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+// count sums the counter with the given name over entries matching attrs.
+func (r *recordingInstrumentation) count(name string, attrs ...Attribute) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total int64
+next:
+	for _, c := range r.counters {
+		if c.name != name {
+			continue
+		}
+		for _, attr := range attrs {
+			if c.attrs[attr.Key] != attr.Value {
+				continue next
+			}
+		}
+		total += c.value
+	}
+	return total
+}
+
+func TestInstrumentation_ClientRequests(t *testing.T) {
+	var requests int
+	rec := &recordingInstrumentation{}
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Ratelimit-Remaining", "700")
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithInstrumentation(rec), WithRetryPolicy(&RetryPolicy{BaseDelay: time.Millisecond}))
+	defer server.Close()
+
+	if _, err := client.GetUsers(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := rec.spansNamed(SpanHelixRequest)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 request spans, got %d", len(spans))
+	}
+	for i, want := range []int64{503, 200} {
+		s := spans[i]
+		if s.attrs[AttrStatus] != want || s.attrs[AttrRetryAttempt] != int64(i) {
+			t.Errorf("span %d: unexpected attributes %v", i, s.attrs)
+		}
+		if s.attrs[AttrEndpoint] != "/users" || s.attrs[AttrMethod] != http.MethodGet {
+			t.Errorf("span %d: unexpected request attributes %v", i, s.attrs)
+		}
+		if s.attrs[AttrRateLimitRemaining] != int64(700) {
+			t.Errorf("span %d: expected rate limit remaining 700, got %v", i, s.attrs[AttrRateLimitRemaining])
+		}
+	}
+	if spans[0].err == nil || spans[1].err != nil {
+		t.Errorf("expected only the first span to record an error, got %v and %v", spans[0].err, spans[1].err)
+	}
+
+	if n := rec.count(CounterHelixRequests, IntAttr(AttrStatus, 200)); n != 1 {
+		t.Errorf("expected 1 successful request counted, got %d", n)
+	}
+	if n := rec.count(CounterHelixRetries, StringAttr(AttrRetryReason, "transient")); n != 1 {
+		t.Errorf("expected 1 transient retry counted, got %d", n)
+	}
+}
+
+func TestInstrumentation_AuthClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	rec := &recordingInstrumentation{}
+	client := NewAuthClient(AuthConfig{ClientID: "id", ClientSecret: "secret"})
+	client.SetHTTPClient(server.Client())
+	client.SetEndpoints(server.URL, "", "", "", "", "", "")
+	client.SetInstrumentation(rec)
+
+	if _, err := client.GetAppAccessToken(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := rec.spansNamed(SpanAuthRequest)
+	if len(spans) != 1 || spans[0].attrs[AttrAuthOperation] != "token" || spans[0].attrs[AttrStatus] != int64(200) {
+		t.Fatalf("unexpected auth spans: %+v", spans)
+	}
+	if n := rec.count(CounterAuthRequests, StringAttr(AttrAuthOperation, "token")); n != 1 {
+		t.Errorf("expected 1 token request counted, got %d", n)
+	}
+}
+
+func TestInstrumentation_IRC(t *testing.T) {
+	received := make(chan struct{})
+	mock := newMockIRCServer(func(conn *websocket.Conn) {
+		for i := 0; i < 3; i++ { // CAP, PASS, NICK
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(":tmi.twitch.tv 001 testuser :Welcome, GLHF!\r\n"))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(":user!user@user.tmi.twitch.tv PRIVMSG #channel :hello\r\n"))
+		time.Sleep(200 * time.Millisecond)
+	})
+	defer mock.Close()
+
+	rec := &recordingInstrumentation{}
+	client := NewIRCClient("testuser", "token",
+		WithIRCURL(mock.URL()),
+		WithAutoReconnect(false),
+		WithIRCInstrumentation(rec),
+		WithMessageHandler(func(*ChatMessage) { close(received) }),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	select {
+	case <-received:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for message")
+	}
+
+	if spans := rec.spansNamed(SpanIRCConnect); len(spans) != 1 || spans[0].err != nil {
+		t.Errorf("expected 1 successful connect span, got %+v", spans)
+	}
+	if n := rec.count(CounterIRCMessages, StringAttr(AttrIRCCommand, "PASS"), StringAttr(AttrIRCDirection, "out")); n != 1 {
+		t.Errorf("expected 1 outgoing PASS counted, got %d", n)
+	}
+	if n := rec.count(CounterIRCMessages, StringAttr(AttrIRCCommand, "PRIVMSG"), StringAttr(AttrIRCDirection, "in")); n != 1 {
+		t.Errorf("expected 1 incoming PRIVMSG counted, got %d", n)
+	}
+}
+
+func TestInstrumentation_EventSubWebSocket(t *testing.T) {
+	received := make(chan struct{})
+	mock := newMockWSServer(func(conn *websocket.Conn) {
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageID: "1", MessageType: WSMessageTypeWelcome, MessageTimestamp: time.Now()},
+			Payload:  mustMarshal(WebSocketWelcomePayload{Session: WebSocketSession{ID: "session", KeepaliveTimeoutSeconds: 10}}),
+		})
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageID: "2", MessageType: WSMessageTypeNotification, MessageTimestamp: time.Now(), SubscriptionType: "channel.follow"},
+			Payload:  mustMarshal(WebSocketNotificationPayload{Subscription: EventSubSubscription{Type: "channel.follow"}}),
+		})
+		time.Sleep(200 * time.Millisecond)
+	})
+	defer mock.Close()
+
+	rec := &recordingInstrumentation{}
+	client := NewEventSubWebSocketClient(
+		WithWSURL(mock.URL()),
+		WithWSInstrumentation(rec),
+		WithWSNotificationHandler(func(*EventSubSubscription, json.RawMessage) { close(received) }),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	select {
+	case <-received:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for notification")
+	}
+
+	if spans := rec.spansNamed(SpanEventSubConnect); len(spans) != 1 {
+		t.Errorf("expected 1 connect span, got %d", len(spans))
+	}
+	if n := rec.count(CounterEventSubMessages, StringAttr(AttrEventSubMessage, WSMessageTypeWelcome)); n != 1 {
+		t.Errorf("expected 1 welcome counted, got %d", n)
+	}
+	if n := rec.count(CounterEventSubMessages,
+		StringAttr(AttrEventSubTransport, "websocket"),
+		StringAttr(AttrEventSubMessage, WSMessageTypeNotification),
+		StringAttr(AttrEventSubType, "channel.follow")); n != 1 {
+		t.Errorf("expected 1 notification counted, got %d", n)
+	}
+}
+
+func TestInstrumentation_Webhook(t *testing.T) {
+	secret := "secret"
+	rec := &recordingInstrumentation{}
+	handler := NewEventSubWebhookHandler(WithWebhookSecret(secret), WithWebhookInstrumentation(rec))
+
+	body := []byte(`{"challenge":"abc","subscription":{"type":"channel.follow"}}`)
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	newRequest := func(signature string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+		req.Header.Set(EventSubHeaderMessageID, "msg-1")
+		req.Header.Set(EventSubHeaderMessageTimestamp, timestamp)
+		req.Header.Set(EventSubHeaderMessageType, EventSubMessageTypeVerification)
+		req.Header.Set(EventSubHeaderSubscriptionType, "channel.follow")
+		req.Header.Set(EventSubHeaderMessageSignature, signature)
+		return req
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), newRequest("sha256=bad"))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("msg-1" + timestamp))
+	mac.Write(body)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest("sha256="+hex.EncodeToString(mac.Sum(nil))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if n := rec.count(CounterEventSubMessages, IntAttr(AttrStatus, http.StatusForbidden)); n != 1 {
+		t.Errorf("expected 1 rejected request counted, got %d", n)
+	}
+	if n := rec.count(CounterEventSubMessages,
+		StringAttr(AttrEventSubTransport, "webhook"),
+		StringAttr(AttrEventSubMessage, EventSubMessageTypeVerification),
+		StringAttr(AttrEventSubType, "channel.follow"),
+		IntAttr(AttrStatus, http.StatusOK)); n != 1 {
+		t.Errorf("expected 1 verification counted, got %d", n)
+	}
+	for _, c := range rec.counters {
+		if c.attrs[AttrStatus] == int64(http.StatusForbidden) && c.attrs[AttrEventSubType] != nil {
+			t.Errorf("expected no subscription type for unverified request, got %v", c.attrs)
+		}
+	}
+	if spans := rec.spansNamed(SpanEventSubWebhookCall); len(spans) != 2 {
+		t.Errorf("expected 2 webhook spans, got %d", len(spans))
+	}
+}
+
+func TestMultiInstrumentation(t *testing.T) {
+	a, b := &recordingInstrumentation{}, &recordingInstrumentation{}
+	multi := MultiInstrumentation(a, b)
+
+	_, span := multi.StartSpan(context.Background(), "op", StringAttr("k", "v"))
+	span.RecordError(errors.New("failed"))
+	span.End()
+	multi.AddCounter(context.Background(), "count", 2)
+
+	for _, rec := range []*recordingInstrumentation{a, b} {
+		spans := rec.spansNamed("op")
+		if len(spans) != 1 || spans[0].attrs["k"] != "v" || spans[0].err == nil {
+			t.Errorf("unexpected spans: %+v", spans)
+		}
+		if rec.count("count") != 2 {
+			t.Errorf("expected counter 2, got %d", rec.count("count"))
+		}
+	}
+}
+
+func TestIRCCommand(t *testing.T) {
+	tests := map[string]string{
+		"PRIVMSG #channel :hello":                     "PRIVMSG",
+		"@reply-parent-msg-id=1 PRIVMSG #channel :hi": "PRIVMSG",
+		"pong :tmi.twitch.tv":                         "PONG",
+		"JOIN":                                        "JOIN",
+	}
+	for line, want := range tests {
+		if got := ircCommand(line); got != want {
+			t.Errorf("ircCommand(%q) = %q, want %q", line, got, want)
+		}
+	}
+	if strings.Contains(ircCommand("PASS oauth:secret"), "secret") {
+		t.Error("expected command only")
+	}
+}
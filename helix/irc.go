@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
@@ -15,8 +16,11 @@ const (
 	// TwitchIRCWebSocket is the WebSocket URL for Twitch IRC.
 	TwitchIRCWebSocket = "wss://irc-ws.chat.twitch.tv:443"
 
-	// TwitchIRCTCP is the TCP address for Twitch IRC.
+	// TwitchIRCTCP is the TLS address for Twitch IRC.
 	TwitchIRCTCP = "irc.chat.twitch.tv:6697"
+
+	// TwitchIRCTCPPlain is the unencrypted TCP address for Twitch IRC.
+	TwitchIRCTCPPlain = "irc.chat.twitch.tv:6667"
 )
 
 // IRC command constants
@@ -45,6 +49,13 @@ var (
 	ErrIRCAuthFailed       = errors.New("irc: authentication failed")
 	ErrIRCInvalidNick      = errors.New("irc: nick is required")
 	ErrIRCInvalidToken     = errors.New("irc: token is required")
+	ErrIRCQueueFull        = errors.New("irc: outbound queue full")
+	ErrIRCMessageDropped   = errors.New("irc: queued message dropped")
+	ErrIRCMessageTooLong   = errors.New("irc: message longer than 500 characters")
+	// ErrIRCDuplicateTooLong is returned for a message that repeats the previous
+	// one within 30 seconds but is too long for the duplicate bypass to be added,
+	// so Twitch would drop it.
+	ErrIRCDuplicateTooLong = errors.New("irc: duplicate message too long for the duplicate bypass")
 )
 
 // sanitizeIRCMessage removes CR/LF characters to prevent IRC command injection.
@@ -54,10 +65,15 @@ func sanitizeIRCMessage(msg string) string {
 	return msg
 }
 
+// normalizeChannel lowercases a channel name, strips its leading # and sanitizes it.
+func normalizeChannel(channel string) string {
+	return sanitizeIRCMessage(strings.ToLower(strings.TrimPrefix(channel, "#")))
+}
+
 // IRCClient manages a connection to Twitch IRC.
 type IRCClient struct {
 	url   string
-	conn  *websocket.Conn
+	conn  IRCConn
 	nick  string
 	token string
 
@@ -92,11 +108,20 @@ type IRCClient struct {
 	writeMu      sync.Mutex
 	globalState  *GlobalUserState
 	pongReceived chan struct{}
+	lastChat     map[string]sentChat // Last message sent per channel
+	lastChatMu   sync.Mutex
 
 	// Options
-	autoReconnect  bool
-	reconnectDelay time.Duration
-	capabilities   []string
+	autoReconnect    bool
+	reconnectDelay   time.Duration
+	capabilities     []string
+	instr            Instrumentation
+	logger           *slog.Logger
+	transport        IRCTransport
+	outbox           *ircOutbox // Set by WithIRCRateLimit
+	splitMessages    bool
+	maxMessageLength int
+	duplicateBypass  bool
 }
 
 // IRCOption configures the IRC client.
@@ -126,7 +151,6 @@ func NewIRCClientE(nick, token string, opts ...IRCOption) (*IRCClient, error) {
 	}
 
 	c := &IRCClient{
-		url:            TwitchIRCWebSocket,
 		nick:           strings.ToLower(nick),
 		token:          token,
 		channels:       make(map[string]bool),
@@ -137,17 +161,26 @@ func NewIRCClientE(nick, token string, opts ...IRCOption) (*IRCClient, error) {
 			"twitch.tv/commands",
 			"twitch.tv/membership",
 		},
-		pongReceived: make(chan struct{}, 1),
+		pongReceived:    make(chan struct{}, 1),
+		lastChat:        make(map[string]sentChat),
+		duplicateBypass: true,
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.transport == nil {
+		c.transport = &IRCWebSocketTransport{}
+	}
+	if c.url == "" {
+		c.url = c.transport.DefaultAddr()
+	}
 
 	return c, nil
 }
 
-// WithIRCURL sets a custom WebSocket URL.
+// WithIRCURL sets a custom WebSocket URL, or a host:port address with a TCP
+// transport.
 func WithIRCURL(url string) IRCOption {
 	return func(c *IRCClient) {
 		c.url = url
@@ -168,6 +201,22 @@ func WithReconnectDelay(d time.Duration) IRCOption {
 	}
 }
 
+// WithIRCInstrumentation sets the instrumentation that receives connect spans and
+// message and reconnect counters.
+func WithIRCInstrumentation(instr Instrumentation) IRCOption {
+	return func(c *IRCClient) {
+		c.instr = instr
+	}
+}
+
+// WithIRCLogger sets the logger for connects, reconnects and dropped messages.
+// The "oauth:" password is redacted from its output.
+func WithIRCLogger(logger *slog.Logger) IRCOption {
+	return func(c *IRCClient) {
+		c.logger = newLogger(logger)
+	}
+}
+
 // WithMessageHandler sets the handler for chat messages.
 func WithMessageHandler(fn func(*ChatMessage)) IRCOption {
 	return func(c *IRCClient) {
@@ -281,7 +330,15 @@ func WithRawMessageHandler(fn func(string)) IRCOption {
 }
 
 // Connect establishes a connection to Twitch IRC.
-func (c *IRCClient) Connect(ctx context.Context) error {
+func (c *IRCClient) Connect(ctx context.Context) (err error) {
+	ctx, span := instrumentationOrNop(c.instr).StartSpan(ctx, SpanIRCConnect)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
 	c.mu.Lock()
 	if c.connected {
 		c.mu.Unlock()
@@ -301,7 +358,7 @@ func (c *IRCClient) Connect(ctx context.Context) error {
 		c.mu.Unlock()
 	}()
 
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.url, nil)
+	conn, err := c.transport.Dial(ctx, c.url)
 	if err != nil {
 		return fmt.Errorf("connecting to IRC: %w", err)
 	}
@@ -356,6 +413,10 @@ func (c *IRCClient) Connect(ctx context.Context) error {
 	c.wg.Add(1)
 	go c.readLoop()
 
+	if c.outbox != nil {
+		c.outbox.start(c)
+	}
+
 	// Rejoin channels
 	c.mu.RLock()
 	channels := make([]string, 0, len(c.channels))
@@ -370,6 +431,7 @@ func (c *IRCClient) Connect(ctx context.Context) error {
 		}
 	}
 
+	loggerOrDiscard(c.logger).InfoContext(ctx, "connected to IRC", "url", c.url, "nick", c.nick, "channels", len(channels))
 	if c.onConnect != nil {
 		c.onConnect()
 	}
@@ -395,7 +457,7 @@ func (c *IRCClient) waitForAuth(ctx context.Context) error {
 		default:
 		}
 
-		_, data, err := c.conn.ReadMessage()
+		data, err := c.conn.ReadMessage()
 		if err != nil {
 			// Check if context was cancelled (deadline-related error)
 			select {
@@ -450,6 +512,9 @@ func (c *IRCClient) readLoop() {
 		}
 		c.mu.Unlock()
 
+		if wasConnected {
+			loggerOrDiscard(c.logger).Info("disconnected from IRC", "reconnect", shouldReconnect)
+		}
 		if wasConnected && c.onDisconnect != nil {
 			c.onDisconnect()
 		}
@@ -479,8 +544,13 @@ func (c *IRCClient) readLoop() {
 			return
 		}
 
-		_, data, err := conn.ReadMessage()
+		data, err := conn.ReadMessage()
 		if err != nil {
+			select {
+			case <-stopChan: // Closed by Close
+			default:
+				loggerOrDiscard(c.logger).Warn("reading from IRC failed", "error", err)
+			}
 			if c.onError != nil && !errors.Is(err, websocket.ErrCloseSent) {
 				c.onError(fmt.Errorf("reading message: %w", err))
 			}
@@ -497,6 +567,7 @@ func (c *IRCClient) readLoop() {
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
+						loggerOrDiscard(c.logger).Error("IRC handler panicked, message dropped", "panic", fmt.Sprint(r), "line", line)
 						if c.onError != nil {
 							c.onError(fmt.Errorf("handler panic: %v", r))
 						}
@@ -516,6 +587,8 @@ func (c *IRCClient) readLoop() {
 // handleMessage processes a single IRC message.
 func (c *IRCClient) handleMessage(raw string) {
 	msg := parseIRCMessage(raw)
+	instrumentationOrNop(c.instr).AddCounter(context.Background(), CounterIRCMessages, 1,
+		StringAttr(AttrIRCCommand, msg.Command), StringAttr(AttrIRCDirection, "in"))
 
 	switch msg.Command {
 	case ircPING:
@@ -543,8 +616,13 @@ func (c *IRCClient) handleMessage(raw string) {
 		}
 
 	case ircNOTICE:
+		notice := parseNotice(msg)
+		if strings.HasPrefix(notice.MsgID, "msg_") {
+			// Twitch rejected a message we sent
+			loggerOrDiscard(c.logger).Warn("message dropped by Twitch", "channel", notice.Channel, "msg_id", notice.MsgID, "notice", notice.Message)
+		}
 		if c.onNotice != nil {
-			c.onNotice(parseNotice(msg))
+			c.onNotice(notice)
 		}
 
 	case ircROOMSTATE:
@@ -572,8 +650,12 @@ func (c *IRCClient) handleMessage(raw string) {
 		}
 
 	case ircUSERSTATE:
+		state := parseUserState(msg)
+		if c.outbox != nil {
+			c.outbox.setPrivileged(state.Channel, isPrivileged(state))
+		}
 		if c.onUserState != nil {
-			c.onUserState(parseUserState(msg))
+			c.onUserState(state)
 		}
 
 	case ircJOIN:
@@ -649,6 +731,8 @@ func (c *IRCClient) reconnect() {
 		if c.onReconnect != nil {
 			c.onReconnect()
 		}
+		instrumentationOrNop(c.instr).AddCounter(context.Background(), CounterIRCReconnects, 1)
+		loggerOrDiscard(c.logger).Info("reconnecting to IRC", "url", c.url)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		err := c.Connect(ctx)
@@ -658,6 +742,7 @@ func (c *IRCClient) reconnect() {
 			return
 		}
 
+		loggerOrDiscard(c.logger).Warn("IRC reconnect failed", "error", err, "retry_in", c.reconnectDelay)
 		if c.onError != nil {
 			c.onError(fmt.Errorf("reconnect failed: %w", err))
 		}
@@ -677,11 +762,17 @@ func (c *IRCClient) send(message string) error {
 		return ErrIRCNotConnected
 	}
 
-	return conn.WriteMessage(websocket.TextMessage, []byte(message+"\r\n"))
+	instrumentationOrNop(c.instr).AddCounter(context.Background(), CounterIRCMessages, 1,
+		StringAttr(AttrIRCCommand, ircCommand(message)), StringAttr(AttrIRCDirection, "out"))
+	return conn.WriteMessage([]byte(message + "\r\n"))
 }
 
 // Close closes the IRC connection.
 func (c *IRCClient) Close() error {
+	if c.outbox != nil {
+		c.outbox.stop()
+	}
+
 	c.mu.Lock()
 	// Close if connected OR if there's an in-progress connection (conn != nil)
 	if !c.connected && c.conn == nil {
@@ -724,8 +815,7 @@ func (c *IRCClient) IsConnected() bool {
 func (c *IRCClient) Join(channels ...string) error {
 	c.mu.Lock()
 	for _, ch := range channels {
-		ch = sanitizeIRCMessage(strings.ToLower(strings.TrimPrefix(ch, "#")))
-		c.channels[ch] = true
+		c.channels[normalizeChannel(ch)] = true
 	}
 	c.mu.Unlock()
 
@@ -734,7 +824,13 @@ func (c *IRCClient) Join(channels ...string) error {
 	}
 
 	for _, ch := range channels {
-		ch = sanitizeIRCMessage(strings.ToLower(strings.TrimPrefix(ch, "#")))
+		ch = normalizeChannel(ch)
+		if c.outbox != nil {
+			if err := c.enqueue(&ircQueued{channel: ch, line: "JOIN #" + ch, join: true}); err != nil {
+				return fmt.Errorf("joining %s: %w", ch, err)
+			}
+			continue
+		}
 		if err := c.send(fmt.Sprintf("JOIN #%s", ch)); err != nil {
 			return fmt.Errorf("joining %s: %w", ch, err)
 		}
@@ -748,8 +844,11 @@ func (c *IRCClient) Join(channels ...string) error {
 func (c *IRCClient) Part(channels ...string) error {
 	c.mu.Lock()
 	for _, ch := range channels {
-		ch = sanitizeIRCMessage(strings.ToLower(strings.TrimPrefix(ch, "#")))
+		ch = normalizeChannel(ch)
 		delete(c.channels, ch)
+		if c.outbox != nil {
+			c.outbox.forget(ch)
+		}
 	}
 	c.mu.Unlock()
 
@@ -758,7 +857,7 @@ func (c *IRCClient) Part(channels ...string) error {
 	}
 
 	for _, ch := range channels {
-		ch = sanitizeIRCMessage(strings.ToLower(strings.TrimPrefix(ch, "#")))
+		ch = normalizeChannel(ch)
 		if err := c.send(fmt.Sprintf("PART #%s", ch)); err != nil {
 			return fmt.Errorf("parting %s: %w", ch, err)
 		}
@@ -769,19 +868,93 @@ func (c *IRCClient) Part(channels ...string) error {
 
 // Say sends a message to a channel.
 // The channel name and message are sanitized to prevent IRC command injection.
+// Messages over 500 characters return ErrIRCMessageTooLong unless
+// WithIRCMessageSplitting is set.
 func (c *IRCClient) Say(channel, message string) error {
-	channel = sanitizeIRCMessage(strings.ToLower(strings.TrimPrefix(channel, "#")))
+	return c.SayWithPriority(channel, message, IRCPriorityNormal)
+}
+
+// SayWithPriority sends a message to a channel, queueing it at the given priority
+// when WithIRCRateLimit is set. Without it, the message is sent immediately.
+func (c *IRCClient) SayWithPriority(channel, message string, priority IRCPriority) error {
+	channel = normalizeChannel(channel)
 	message = sanitizeIRCMessage(message)
-	return c.send(fmt.Sprintf("PRIVMSG #%s :%s", channel, message))
+	parts, err := c.splitChat(message)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		if err := c.sendChat(channel, fmt.Sprintf("PRIVMSG #%s :", channel), part, priority); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Reply sends a reply to a message.
 // The channel name, parent message ID, and message are sanitized to prevent IRC command injection.
 func (c *IRCClient) Reply(channel, parentMsgID, message string) error {
-	channel = sanitizeIRCMessage(strings.ToLower(strings.TrimPrefix(channel, "#")))
+	return c.ReplyWithPriority(channel, parentMsgID, message, IRCPriorityNormal)
+}
+
+// ReplyWithPriority sends a reply to a message, queueing it at the given priority
+// when WithIRCRateLimit is set. Without it, the reply is sent immediately.
+func (c *IRCClient) ReplyWithPriority(channel, parentMsgID, message string, priority IRCPriority) error {
+	channel = normalizeChannel(channel)
 	parentMsgID = sanitizeIRCMessage(parentMsgID)
 	message = sanitizeIRCMessage(message)
-	return c.send(fmt.Sprintf("@reply-parent-msg-id=%s PRIVMSG #%s :%s", parentMsgID, channel, message))
+	parts, err := c.splitChat(message)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		if err := c.sendChat(channel, fmt.Sprintf("@reply-parent-msg-id=%s PRIVMSG #%s :", parentMsgID, channel), part, priority); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendChat sends the chat line prefix+text, or queues it if the outbound queue is
+// enabled.
+func (c *IRCClient) sendChat(channel, prefix, text string, priority IRCPriority) error {
+	if c.outbox == nil {
+		return c.writeChat(channel, prefix, text)
+	}
+	return c.enqueue(&ircQueued{channel: channel, line: prefix, text: text, priority: priority})
+}
+
+// writeChat writes the chat line prefix+text with the duplicate bypass applied to
+// text, which becomes the channel's last message once it is written.
+func (c *IRCClient) writeChat(channel, prefix, text string) error {
+	c.lastChatMu.Lock()
+	text, err := c.bypassDuplicate(channel, text, time.Now())
+	if err != nil {
+		c.lastChatMu.Unlock()
+		return err
+	}
+	// Claim the channel's last message while writing, so a concurrent identical
+	// message gets the bypass
+	prev, hadPrev := c.lastChat[channel]
+	c.lastChat[channel] = sentChat{text: text, at: time.Now()}
+	c.lastChatMu.Unlock()
+
+	err = c.send(prefix + text)
+
+	c.lastChatMu.Lock()
+	defer c.lastChatMu.Unlock()
+	if c.lastChat[channel].text != text {
+		return err // Another message has been written since
+	}
+	switch {
+	case err == nil:
+		c.lastChat[channel] = sentChat{text: text, at: time.Now()}
+	case hadPrev:
+		c.lastChat[channel] = prev
+	default:
+		delete(c.lastChat, channel)
+	}
+	return err
 }
 
 // Whisper sends a whisper to a user.
@@ -0,0 +1,182 @@
+package helix
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxIRCMessageLength is the longest chat message Twitch accepts, in characters.
+const MaxIRCMessageLength = 500
+
+// Twitch drops a message identical to the user's previous message in the same
+// channel within this window. Appending duplicateBypass makes it differ.
+const (
+	duplicateWindow = 30 * time.Second
+	duplicateBypass = " \U000E0000"
+)
+
+const zeroWidthJoiner = '\u200d'
+
+// sentChat is the last message sent to a channel.
+type sentChat struct {
+	text string
+	at   time.Time
+}
+
+// WithIRCMessageSplitting splits Say and Reply messages longer than maxLength
+// characters into several messages instead of returning ErrIRCMessageTooLong.
+// Messages are split at whitespace, and words longer than maxLength between
+// grapheme clusters, so emotes, emoji and multi-byte characters stay whole. A
+// maxLength of 0 or more than MaxIRCMessageLength uses MaxIRCMessageLength.
+func WithIRCMessageSplitting(maxLength int) IRCOption {
+	return func(c *IRCClient) {
+		if maxLength <= 0 || maxLength > MaxIRCMessageLength {
+			maxLength = MaxIRCMessageLength
+		}
+		c.splitMessages = true
+		c.maxMessageLength = maxLength
+	}
+}
+
+// WithIRCDuplicateBypass enables or disables the duplicate message bypass, which
+// is on by default. When a message is identical to the previous message sent to
+// the channel within 30 seconds, an invisible character is appended so Twitch
+// doesn't drop it. Queued messages are compared when they are sent. A repeat too
+// long for the bypass fails with ErrIRCDuplicateTooLong.
+func WithIRCDuplicateBypass(enabled bool) IRCOption {
+	return func(c *IRCClient) {
+		c.duplicateBypass = enabled
+	}
+}
+
+// splitChat returns the messages to send for message, split as configured.
+func (c *IRCClient) splitChat(message string) ([]string, error) {
+	limit := MaxIRCMessageLength
+	if c.maxMessageLength > 0 {
+		limit = c.maxMessageLength
+	}
+	if utf8.RuneCountInString(message) <= limit {
+		return []string{message}, nil
+	}
+	if !c.splitMessages {
+		return nil, ErrIRCMessageTooLong
+	}
+	// Leave room for the bypass so a split part never needs to be split again
+	if c.duplicateBypass {
+		limit -= utf8.RuneCountInString(duplicateBypass)
+	}
+	return splitMessage(message, limit), nil
+}
+
+// bypassDuplicate returns text with the duplicate bypass appended if it repeats
+// the last message sent to channel within the duplicate window, or
+// ErrIRCDuplicateTooLong if there is no room for it
+// (must be called with lastChatMu held).
+func (c *IRCClient) bypassDuplicate(channel, text string, now time.Time) (string, error) {
+	last, ok := c.lastChat[channel]
+	if !c.duplicateBypass || !ok || last.text != text || now.Sub(last.at) >= duplicateWindow {
+		return text, nil
+	}
+	if utf8.RuneCountInString(text+duplicateBypass) > MaxIRCMessageLength {
+		return "", ErrIRCDuplicateTooLong
+	}
+	return text + duplicateBypass, nil
+}
+
+// splitMessage splits message into parts of at most limit characters. It splits
+// at whitespace where it can, and otherwise between grapheme clusters. The
+// whitespace a message is split at is dropped; spacing within a part is kept.
+func splitMessage(message string, limit int) []string {
+	var parts []string
+	s := message
+	for utf8.RuneCountInString(s) > limit {
+		end := 0
+		for n := 0; n < limit; n++ {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+
+		cut := strings.LastIndexFunc(s[:end], unicode.IsSpace)
+		if r, _ := utf8.DecodeRuneInString(s[end:]); unicode.IsSpace(r) {
+			cut = end
+		}
+		if cut >= 0 {
+			if part := strings.TrimRightFunc(s[:cut], unicode.IsSpace); part != "" {
+				parts = append(parts, part)
+				s = strings.TrimLeftFunc(s[cut:], unicode.IsSpace)
+				continue
+			}
+		}
+
+		// No whitespace to split at, so split the word between grapheme clusters
+		n, size := 0, 0
+		for _, g := range splitGraphemes(s) {
+			gn := utf8.RuneCountInString(g)
+			if size > 0 && n+gn > limit {
+				break
+			}
+			n += gn
+			size += len(g)
+		}
+		parts = append(parts, s[:size])
+		s = s[size:]
+	}
+	if s != "" {
+		parts = append(parts, s)
+	}
+	return parts
+}
+
+// splitGraphemes splits s into grapheme clusters. It approximates the Unicode
+// rules closely enough for chat: combining marks, emoji modifiers, variation
+// selectors and tags stay with the preceding character, ZWJ sequences and
+// regional indicator (flag) pairs are kept together.
+func splitGraphemes(s string) []string {
+	var clusters []string
+	start := 0
+	prev := rune(-1)
+	regional := 0 // Regional indicators in a row, ending at prev
+	for i, r := range s {
+		if i > 0 {
+			joined := isGraphemeExtend(r) || prev == zeroWidthJoiner ||
+				(isRegionalIndicator(r) && regional%2 == 1)
+			if !joined {
+				clusters = append(clusters, s[start:i])
+				start = i
+			}
+		}
+		if isRegionalIndicator(r) {
+			regional++
+		} else {
+			regional = 0
+		}
+		prev = r
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+// isGraphemeExtend reports whether r continues the preceding grapheme cluster.
+func isGraphemeExtend(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	case r == zeroWidthJoiner:
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // Emoji skin tone modifiers
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // Tags
+		return true
+	case r >= 0x1160 && r <= 0x11FF: // Hangul medial vowels and final consonants
+		return true
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
@@ -0,0 +1,232 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSplitGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"ascii", "abc", []string{"a", "b", "c"}},
+		{"combining mark", "éx", []string{"é", "x"}},
+		{"skin tone", "👍🏽!", []string{"👍🏽", "!"}},
+		{"zwj sequence", "👩‍👩‍👧a", []string{"👩‍👩‍👧", "a"}},
+		{"flags", "🇳🇿🇦🇺", []string{"🇳🇿", "🇦🇺"}},
+		{"variation selector", "❤️x", []string{"❤️", "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitGraphemes(tt.in)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitGraphemes(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		limit int
+		want  []string
+	}{
+		{"words", "Kappa hello world PogChamp", 11, []string{"Kappa hello", "world", "PogChamp"}},
+		{"exact fit", "aaa bbb", 7, []string{"aaa bbb"}},
+		{"long word", "abcdefgh xy", 3, []string{"abc", "def", "gh", "xy"}},
+		{"long word joins next", "abcd e", 3, []string{"abc", "d e"}},
+		{"repeated spaces", "a  b   c    d", 8, []string{"a  b   c", "d"}},
+		{"spaces at the split", "ab    cd", 3, []string{"ab", "cd"}},
+		{"under the limit", "  a  b  ", 8, []string{"  a  b  "}},
+		{"emoji", "👍🏽👍🏽👍🏽", 4, []string{"👍🏽👍🏽", "👍🏽"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMessage(tt.in, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitMessage(%q, %d) = %q, want %q", tt.in, tt.limit, got, tt.want)
+			}
+			for _, part := range got {
+				if !utf8.ValidString(part) || utf8.RuneCountInString(part) > tt.limit {
+					t.Errorf("invalid part %q", part)
+				}
+			}
+		})
+	}
+}
+
+func TestIRCClient_SplitChat(t *testing.T) {
+	long := strings.Repeat("word ", 120)
+	client := NewIRCClient("testuser", "token")
+	if _, err := client.splitChat(long); !errors.Is(err, ErrIRCMessageTooLong) {
+		t.Errorf("expected ErrIRCMessageTooLong without splitting, got %v", err)
+	}
+
+	client = NewIRCClient("testuser", "token", WithIRCMessageSplitting(0))
+	parts, err := client.splitChat(long)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != 2 || utf8.RuneCountInString(parts[0]) > MaxIRCMessageLength-utf8.RuneCountInString(duplicateBypass) {
+		t.Errorf("expected 2 parts within the limit, got %d", len(parts))
+	}
+	if parts, _ := client.splitChat("short  message "); len(parts) != 1 || parts[0] != "short  message " {
+		t.Errorf("expected a short message unchanged, got %q", parts)
+	}
+}
+
+// connectChatClient connects a client to the pool test server s.
+func connectChatClient(t *testing.T, s *poolServer, opts ...IRCOption) *IRCClient {
+	t.Helper()
+	opts = append([]IRCOption{WithIRCURL(s.URL()), WithAutoReconnect(false)}, opts...)
+	client := NewIRCClient("testuser", "token", opts...)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestIRCClient_DuplicateBypass(t *testing.T) {
+	s := newPoolServer(t)
+	client := connectChatClient(t, s)
+	texts := func(channel string) []string {
+		var texts []string
+		for _, line := range s.sent(0, "PRIVMSG #"+channel+" :") {
+			texts = append(texts, strings.TrimPrefix(line, "PRIVMSG #"+channel+" :"))
+		}
+		return texts
+	}
+
+	for _, channel := range []string{"a", "a", "a", "b"} {
+		if err := client.Say(channel, "hi"); err != nil {
+			t.Fatalf("Say failed: %v", err)
+		}
+	}
+	waitFor(t, "PRIVMSGs", func() bool { return len(texts("a")) == 3 && len(texts("b")) == 1 })
+	if got := texts("a"); strings.Join(got, "|") != "hi|hi"+duplicateBypass+"|hi" {
+		t.Errorf("expected the bypass to alternate, got %q", got)
+	}
+	if got := texts("b"); got[0] != "hi" {
+		t.Errorf("expected channels to be tracked separately, got %q", got)
+	}
+
+	client.lastChatMu.Lock()
+	client.lastChat["b"] = sentChat{text: "hi", at: time.Now().Add(-duplicateWindow)}
+	client.lastChatMu.Unlock()
+	_ = client.Say("b", "hi")
+	waitFor(t, "PRIVMSG", func() bool { return len(texts("b")) == 2 })
+	if got := texts("b"); got[1] != "hi" {
+		t.Errorf("expected no bypass after the duplicate window, got %q", got[1])
+	}
+
+	// A repeated message with no room for the bypass is refused, not dropped by Twitch
+	long := strings.Repeat("x", MaxIRCMessageLength)
+	if err := client.Say("c", long); err != nil {
+		t.Fatalf("Say failed: %v", err)
+	}
+	if err := client.Say("c", long); !errors.Is(err, ErrIRCDuplicateTooLong) {
+		t.Errorf("expected ErrIRCDuplicateTooLong, got %v", err)
+	}
+
+	disabled := NewIRCClient("testuser", "token", WithIRCDuplicateBypass(false))
+	if text, err := disabled.bypassDuplicate("a", "hi", time.Now()); err != nil || text != "hi" {
+		t.Errorf("expected no bypass when disabled, got %q, %v", text, err)
+	}
+
+	unconnected := NewIRCClient("testuser", "token")
+	if err := unconnected.Say("a", "hi"); !errors.Is(err, ErrIRCNotConnected) {
+		t.Fatalf("expected ErrIRCNotConnected, got %v", err)
+	}
+	if _, ok := unconnected.lastChat["a"]; ok {
+		t.Error("expected a message that wasn't sent not to be recorded")
+	}
+}
+
+func TestIRCClient_DuplicateBypass_Queued(t *testing.T) {
+	s := newPoolServer(t)
+	client := NewIRCClient("testuser", "token", WithIRCURL(s.URL()), WithAutoReconnect(false),
+		WithIRCRateLimit(IRCRateLimit{ChannelInterval: 100 * time.Millisecond}))
+
+	// Queued while disconnected, the messages are compared when they are sent
+	_ = client.Say("a", "hi")
+	_ = client.Say("a", "hi")
+	client.outbox.mu.Lock()
+	for _, item := range client.outbox.channels["a"] {
+		if item.text != "hi" {
+			t.Errorf("expected queued text without the bypass, got %q", item.text)
+		}
+	}
+	client.outbox.mu.Unlock()
+
+	connected := time.Now()
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	waitFor(t, "PRIVMSGs", func() bool { return len(s.sent(0, "PRIVMSG #a")) == 2 })
+	if got := s.sent(0, "PRIVMSG #a"); got[0] != "PRIVMSG #a :hi" || got[1] != "PRIVMSG #a :hi"+duplicateBypass {
+		t.Errorf("expected the bypass on the second message, got %q", got)
+	}
+	client.lastChatMu.Lock()
+	last := client.lastChat["a"]
+	client.lastChatMu.Unlock()
+	if last.at.Before(connected.Add(100 * time.Millisecond)) {
+		t.Errorf("expected the last message to be recorded when it was sent, got %v after connecting", last.at.Sub(connected))
+	}
+}
+
+func TestIRCClient_Say_Split(t *testing.T) {
+	received := make(chan string, 10)
+	mock := newMockIRCServer(func(conn *websocket.Conn) {
+		defer func() { _ = conn.Close() }()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(":tmi.twitch.tv 001 testuser :Welcome\r\n"))
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if strings.Contains(string(data), "PRIVMSG") {
+				received <- strings.TrimSpace(string(data))
+			}
+		}
+	})
+	defer mock.Close()
+
+	client := NewIRCClient("testuser", "token",
+		WithIRCURL(mock.URL()),
+		WithAutoReconnect(false),
+		WithIRCMessageSplitting(12),
+	)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	// Two of the 12 characters are kept free for the duplicate bypass
+	if err := client.Reply("testchannel", "abc", "hello there chat"); err != nil {
+		t.Fatalf("Reply failed: %v", err)
+	}
+	for _, want := range []string{"hello", "there chat"} {
+		select {
+		case msg := <-received:
+			if msg != "@reply-parent-msg-id=abc PRIVMSG #testchannel :"+want {
+				t.Errorf("unexpected message: %s", msg)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for message")
+		}
+	}
+}
@@ -0,0 +1,621 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultIRCPoolSize is the number of connections an IRCPool opens by default.
+const DefaultIRCPoolSize = 4
+
+// ErrIRCPoolClosed is returned when connecting a pool that has been closed.
+var ErrIRCPoolClosed = errors.New("irc: pool is closed")
+
+// ChatHandlers is the event handler interface shared by ChatBotClient and IRCPool.
+type ChatHandlers interface {
+	OnMessage(fn func(*ChatMessage))
+	OnSub(fn func(*UserNotice))
+	OnResub(fn func(*UserNotice))
+	OnSubGift(fn func(*UserNotice))
+	OnRaid(fn func(*UserNotice))
+	OnCheer(fn func(*ChatMessage))
+	OnJoin(fn func(channel, user string))
+	OnPart(fn func(channel, user string))
+	OnRoomState(fn func(*RoomState))
+	OnNotice(fn func(*Notice))
+	OnClearChat(fn func(*ClearChat))
+	OnWhisper(fn func(*Whisper))
+	OnConnect(fn func())
+	OnDisconnect(fn func())
+	OnError(fn func(error))
+	SetCommandRouter(router *CommandRouter)
+}
+
+var (
+	_ ChatHandlers = (*ChatBotClient)(nil)
+	_ ChatHandlers = (*IRCPool)(nil)
+)
+
+// IRCPool spreads channels over several IRCClient connections, for bots that
+// join more channels than one connection should carry. Channels are assigned to
+// the connection with the fewest channels and joined no faster than the
+// account's JOIN limit allows. When a connection drops, its channels are moved
+// to the remaining connections and a replacement connection is opened, which
+// then takes channels back from the busiest connections up to an even share.
+//
+// OnConnect and OnDisconnect are called for each connection. Whispers and
+// notices without a channel, which Twitch sends on every connection, are only
+// passed on from one of them.
+type IRCPool struct {
+	chatHandlers
+
+	nick           string
+	token          string
+	size           int
+	ircOpts        []IRCOption
+	joins          int
+	joinWindow     time.Duration
+	reconnectDelay time.Duration
+	sendWindow     *ircSendWindow // Message window shared by the connections' outboxes
+
+	poolMu    sync.Mutex
+	conns     []*poolConn
+	channels  map[string]int // Channel to connection index, -1 if unassigned
+	joinQueue []string
+	queued    map[string]bool       // Channels in joinQueue
+	leaving   map[string]*IRCClient // Connection to part a moved channel from once it is rejoined
+	joinsSent []time.Time
+	started   bool
+	closed    bool
+	wake      chan struct{}
+	ctx       context.Context // Cancelled by Close
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// poolConn is one of an IRCPool's connections.
+type poolConn struct {
+	client *IRCClient
+	alive  bool
+	load   int // Channels assigned
+}
+
+// IRCPoolOption configures an IRCPool.
+type IRCPoolOption func(*IRCPool)
+
+// NewIRCPool creates a pool of IRC connections. Call Connect to open them.
+func NewIRCPool(nick, token string, opts ...IRCPoolOption) (*IRCPool, error) {
+	if nick == "" {
+		return nil, ErrIRCInvalidNick
+	}
+	if token == "" {
+		return nil, ErrIRCInvalidToken
+	}
+
+	p := &IRCPool{
+		nick:           nick,
+		token:          token,
+		size:           DefaultIRCPoolSize,
+		joins:          DefaultIRCJoinLimit,
+		joinWindow:     DefaultIRCJoinWindow,
+		reconnectDelay: 5 * time.Second,
+		sendWindow:     &ircSendWindow{},
+		channels:       make(map[string]int),
+		queued:         make(map[string]bool),
+		leaving:        make(map[string]*IRCClient),
+		wake:           make(chan struct{}, 1),
+	}
+	p.sender = p
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// WithIRCPoolSize sets the number of connections.
+func WithIRCPoolSize(n int) IRCPoolOption {
+	return func(p *IRCPool) {
+		if n > 0 {
+			p.size = n
+		}
+	}
+}
+
+// WithIRCPoolJoinLimit sets how many channels the pool joins per window, across
+// all connections. The default is Twitch's 20 per 10 seconds.
+func WithIRCPoolJoinLimit(joins int, window time.Duration) IRCPoolOption {
+	return func(p *IRCPool) {
+		if joins > 0 && window > 0 {
+			p.joins = joins
+			p.joinWindow = window
+		}
+	}
+}
+
+// WithIRCPoolReconnectDelay sets the delay before replacing a dropped connection.
+func WithIRCPoolReconnectDelay(d time.Duration) IRCPoolOption {
+	return func(p *IRCPool) {
+		p.reconnectDelay = d
+	}
+}
+
+// WithIRCPoolOptions passes options to every connection's IRCClient. Handler and
+// auto-reconnect options are ignored; the pool handles both. A WithIRCRateLimit
+// message limit is shared by all connections rather than applied to each.
+func WithIRCPoolOptions(opts ...IRCOption) IRCPoolOption {
+	return func(p *IRCPool) {
+		p.ircOpts = append(p.ircOpts, opts...)
+	}
+}
+
+// Connect opens the pool's connections and starts joining channels. If any
+// connection fails, the others are closed and the error is returned. A closed
+// pool cannot be reconnected and returns ErrIRCPoolClosed.
+func (p *IRCPool) Connect(ctx context.Context) error {
+	p.poolMu.Lock()
+	if p.closed {
+		p.poolMu.Unlock()
+		return ErrIRCPoolClosed
+	}
+	if p.started {
+		p.poolMu.Unlock()
+		return ErrIRCAlreadyConnected
+	}
+	p.started = true
+	p.conns = make([]*poolConn, p.size)
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.poolMu.Unlock()
+
+	for i := range p.conns {
+		client, err := p.connect(ctx, i)
+		if err != nil {
+			_ = p.Close()
+			return fmt.Errorf("connecting pool connection %d: %w", i, err)
+		}
+		p.poolMu.Lock()
+		if p.closed {
+			p.poolMu.Unlock()
+			_ = client.Close()
+			return ErrIRCPoolClosed
+		}
+		p.conns[i] = &poolConn{client: client, alive: true}
+		p.poolMu.Unlock()
+	}
+
+	p.poolMu.Lock()
+	if p.closed {
+		p.poolMu.Unlock()
+		return ErrIRCPoolClosed
+	}
+	p.assignPending()
+	p.wg.Add(1)
+	poolCtx := p.ctx
+	p.poolMu.Unlock()
+
+	go p.joinLoop(poolCtx)
+	return nil
+}
+
+// connect creates and connects the client for connection i.
+func (p *IRCPool) connect(ctx context.Context, i int) (*IRCClient, error) {
+	var client *IRCClient
+	opts := append(append([]IRCOption{}, p.ircOpts...), p.ircHandlerOptions()...)
+	opts = append(opts,
+		withIRCSendWindow(p.sendWindow),
+		WithAutoReconnect(false),
+		WithWhisperHandler(func(w *Whisper) {
+			if p.isPrimary(client) {
+				p.handleWhisper(w)
+			}
+		}),
+		WithNoticeHandler(func(n *Notice) {
+			if n.Channel != "" || p.isPrimary(client) {
+				p.handleNotice(n)
+			}
+		}),
+		WithDisconnectHandler(func() {
+			p.handleDisconnect()
+			p.connectionLost(i, client)
+		}),
+	)
+	client, err := NewIRCClientE(p.nick, p.token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Connect(ctx); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// isPrimary reports whether client is the first live connection, which passes on
+// events Twitch sends on every connection.
+func (p *IRCPool) isPrimary(client *IRCClient) bool {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	for _, conn := range p.conns {
+		if conn != nil && conn.alive {
+			return conn.client == client
+		}
+	}
+	return false
+}
+
+// connectionLost moves the channels of a dropped connection to the others and
+// starts replacing it.
+func (p *IRCPool) connectionLost(i int, client *IRCClient) {
+	p.poolMu.Lock()
+	if p.closed || p.conns[i] == nil || p.conns[i].client != client {
+		p.poolMu.Unlock()
+		return
+	}
+	p.conns[i].alive = false
+	p.conns[i].load = 0
+	moved := 0
+	for channel, idx := range p.channels {
+		if idx == i {
+			p.channels[channel] = -1
+			moved++
+		}
+	}
+	for channel, leaving := range p.leaving {
+		if leaving == client {
+			delete(p.leaving, channel)
+		}
+	}
+	p.assignPending()
+	p.wg.Add(1)
+	poolCtx := p.ctx
+	p.poolMu.Unlock()
+
+	loggerOrDiscard(client.logger).Warn("IRC pool connection lost, moving channels", "connection", i, "channels", moved)
+	go p.replace(poolCtx, i, client)
+}
+
+// replace closes a dropped connection and opens a new one in its place, then
+// moves channels onto it. It gives up when poolCtx is cancelled.
+func (p *IRCPool) replace(poolCtx context.Context, i int, old *IRCClient) {
+	defer p.wg.Done()
+	_ = old.Close()
+
+	for {
+		select {
+		case <-poolCtx.Done():
+			return
+		case <-time.After(p.reconnectDelay):
+		}
+
+		ctx, cancel := context.WithTimeout(poolCtx, 30*time.Second)
+		client, err := p.connect(ctx, i)
+		cancel()
+		if poolCtx.Err() != nil {
+			if err == nil {
+				_ = client.Close()
+			}
+			return
+		}
+		if err != nil {
+			p.handleError(fmt.Errorf("replacing pool connection %d: %w", i, err))
+			continue
+		}
+
+		p.poolMu.Lock()
+		if p.closed {
+			p.poolMu.Unlock()
+			_ = client.Close()
+			return
+		}
+		p.conns[i] = &poolConn{client: client, alive: true}
+		p.assignPending()
+		p.rebalance(i)
+		p.poolMu.Unlock()
+		return
+	}
+}
+
+// rebalance moves channels from the connections with the most channels to
+// connection i until it has an even share. Each moved channel is parted from its
+// old connection when its JOIN on i is sent, within the join limit. The caller
+// must hold p.poolMu.
+func (p *IRCPool) rebalance(i int) {
+	moved := 0
+	for {
+		from := -1
+		for j, conn := range p.conns {
+			if j != i && conn != nil && conn.alive && (from < 0 || conn.load > p.conns[from].load) {
+				from = j
+			}
+		}
+		// Stop once moving a channel would no longer even out the loads
+		if from < 0 || p.conns[from].load <= p.conns[i].load+1 {
+			break
+		}
+
+		channel := ""
+		for ch, idx := range p.channels {
+			if idx == from && (channel == "" || ch < channel) {
+				channel = ch
+			}
+		}
+		if channel == "" {
+			break
+		}
+		p.channels[channel] = i
+		p.conns[from].load--
+		p.conns[i].load++
+		if _, ok := p.leaving[channel]; !ok {
+			p.leaving[channel] = p.conns[from].client
+		}
+		if !p.queued[channel] {
+			p.queued[channel] = true
+			p.joinQueue = append(p.joinQueue, channel)
+		}
+		moved++
+	}
+	if moved > 0 {
+		p.wakeJoinLoop()
+	}
+}
+
+// assignPending assigns unassigned channels to the live connections with the
+// fewest channels and queues their JOINs. The caller must hold p.poolMu.
+func (p *IRCPool) assignPending() {
+	var pending []string
+	for channel, idx := range p.channels {
+		if idx < 0 {
+			pending = append(pending, channel)
+		}
+	}
+	sort.Strings(pending)
+
+	for _, channel := range pending {
+		best := -1
+		for i, conn := range p.conns {
+			if conn != nil && conn.alive && (best < 0 || conn.load < p.conns[best].load) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return
+		}
+		p.channels[channel] = best
+		p.conns[best].load++
+		if !p.queued[channel] {
+			p.queued[channel] = true
+			p.joinQueue = append(p.joinQueue, channel)
+		}
+	}
+	if len(pending) > 0 {
+		p.wakeJoinLoop()
+	}
+}
+
+// wakeJoinLoop wakes joinLoop to send newly queued JOINs.
+func (p *IRCPool) wakeJoinLoop() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// joinLoop sends queued JOINs within the join limit until poolCtx is cancelled.
+func (p *IRCPool) joinLoop(poolCtx context.Context) {
+	defer p.wg.Done()
+	for {
+		p.poolMu.Lock()
+		now := time.Now()
+		p.joinsSent = pruneWindow(p.joinsSent, now, p.joinWindow)
+		var client, leaving *IRCClient
+		var channel string
+		wait := time.Duration(-1)
+		if len(p.joinQueue) > 0 {
+			if readyAt := windowReadyAt(p.joinsSent, p.joins, p.joinWindow, now); readyAt.After(now) {
+				wait = readyAt.Sub(now)
+			} else {
+				channel = p.joinQueue[0]
+				p.joinQueue = p.joinQueue[1:]
+				delete(p.queued, channel)
+				// Skip channels parted or moved since they were queued
+				if idx, ok := p.channels[channel]; ok && idx >= 0 && p.conns[idx].alive {
+					client = p.conns[idx].client
+					p.joinsSent = append(p.joinsSent, now)
+					leaving = p.leaving[channel]
+					delete(p.leaving, channel)
+				}
+				wait = 0
+			}
+		}
+		p.poolMu.Unlock()
+
+		if leaving != nil && leaving != client {
+			if err := leaving.Part(channel); err != nil {
+				p.handleError(fmt.Errorf("parting moved channel %s: %w", channel, err))
+			}
+		}
+		if client != nil {
+			if err := client.Join(channel); err != nil {
+				p.handleError(fmt.Errorf("joining %s: %w", channel, err))
+			}
+		}
+		if wait == 0 {
+			continue
+		}
+
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timeout = time.After(wait)
+		}
+		select {
+		case <-poolCtx.Done():
+			return
+		case <-p.wake:
+		case <-timeout:
+		}
+	}
+}
+
+// Close closes all connections.
+func (p *IRCPool) Close() error {
+	p.poolMu.Lock()
+	if p.closed {
+		p.poolMu.Unlock()
+		return nil
+	}
+	p.closed = true
+	if p.cancel != nil {
+		p.cancel()
+	}
+	conns := p.conns
+	for _, conn := range conns {
+		if conn != nil {
+			conn.alive = false
+		}
+	}
+	p.poolMu.Unlock()
+
+	p.wg.Wait()
+	for _, conn := range conns {
+		if conn != nil {
+			_ = conn.client.Close()
+		}
+	}
+	return nil
+}
+
+// IsConnected returns whether any connection is connected.
+func (p *IRCPool) IsConnected() bool {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	for _, conn := range p.conns {
+		if conn != nil && conn.alive {
+			return true
+		}
+	}
+	return false
+}
+
+// Join queues channels to be joined. Channels joined before Connect are joined
+// once the pool is connected.
+func (p *IRCPool) Join(channels ...string) error {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	for _, ch := range channels {
+		ch = normalizeChannel(ch)
+		if _, ok := p.channels[ch]; !ok {
+			p.channels[ch] = -1
+		}
+	}
+	p.assignPending()
+	return nil
+}
+
+// Part leaves channels.
+func (p *IRCPool) Part(channels ...string) error {
+	var firstErr error
+	for _, ch := range channels {
+		ch = normalizeChannel(ch)
+		p.poolMu.Lock()
+		idx, ok := p.channels[ch]
+		delete(p.channels, ch)
+		var client *IRCClient
+		if ok && idx >= 0 {
+			p.conns[idx].load--
+			if p.conns[idx].alive {
+				client = p.conns[idx].client
+			}
+		}
+		// A channel being moved is still joined on its old connection
+		leaving := p.leaving[ch]
+		delete(p.leaving, ch)
+		p.poolMu.Unlock()
+
+		for _, c := range []*IRCClient{client, leaving} {
+			if c == nil {
+				continue
+			}
+			if err := c.Part(ch); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// clientFor returns the connection a channel is assigned to, or the first live
+// connection for channels the pool hasn't joined.
+func (p *IRCPool) clientFor(channel string) (*IRCClient, error) {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	if idx, ok := p.channels[normalizeChannel(channel)]; ok {
+		if idx < 0 || !p.conns[idx].alive {
+			return nil, ErrIRCNotConnected
+		}
+		return p.conns[idx].client, nil
+	}
+	for _, conn := range p.conns {
+		if conn != nil && conn.alive {
+			return conn.client, nil
+		}
+	}
+	return nil, ErrIRCNotConnected
+}
+
+// Say sends a message to a channel on the connection that joined it.
+func (p *IRCPool) Say(channel, message string) error {
+	client, err := p.clientFor(channel)
+	if err != nil {
+		return err
+	}
+	return client.Say(channel, message)
+}
+
+// Reply sends a reply to a specific message.
+func (p *IRCPool) Reply(channel, parentMsgID, message string) error {
+	client, err := p.clientFor(channel)
+	if err != nil {
+		return err
+	}
+	return client.Reply(channel, parentMsgID, message)
+}
+
+// Whisper sends a whisper to a user.
+func (p *IRCPool) Whisper(user, message string) error {
+	client, err := p.clientFor("")
+	if err != nil {
+		return err
+	}
+	return client.Whisper(user, message)
+}
+
+// GetJoinedChannels returns the pool's channels, sorted, including those not
+// joined yet.
+func (p *IRCPool) GetJoinedChannels() []string {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	channels := make([]string, 0, len(p.channels))
+	for ch := range p.channels {
+		channels = append(channels, ch)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// ChannelCounts returns the number of channels assigned to each connection, or -1
+// for a connection that is being replaced.
+func (p *IRCPool) ChannelCounts() []int {
+	p.poolMu.Lock()
+	defer p.poolMu.Unlock()
+	loads := make([]int, len(p.conns))
+	for i, conn := range p.conns {
+		if conn == nil || !conn.alive {
+			loads[i] = -1
+			continue
+		}
+		loads[i] = conn.load
+	}
+	return loads
+}
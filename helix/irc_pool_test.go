@@ -0,0 +1,325 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// poolServer is a mock IRC server that records the lines each connection sends.
+type poolServer struct {
+	*mockIRCServer
+
+	mu    sync.Mutex
+	conns []*websocket.Conn
+	lines [][]string
+}
+
+func newPoolServer(t *testing.T) *poolServer {
+	t.Helper()
+	s := &poolServer{}
+	s.mockIRCServer = newMockIRCServer(func(conn *websocket.Conn) {
+		defer func() { _ = conn.Close() }()
+		s.mu.Lock()
+		id := len(s.conns)
+		s.conns = append(s.conns, conn)
+		s.lines = append(s.lines, nil)
+		s.mu.Unlock()
+
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(":tmi.twitch.tv 001 testuser :Welcome\r\n"))
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\r\n") {
+				s.lines[id] = append(s.lines[id], line)
+			}
+			s.mu.Unlock()
+		}
+	})
+	t.Cleanup(s.Close)
+	return s
+}
+
+// sent returns the lines connection id sent starting with prefix.
+func (s *poolServer) sent(id int, prefix string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []string
+	if id < len(s.lines) {
+		for _, line := range s.lines[id] {
+			if strings.HasPrefix(line, prefix) {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// write sends a raw line to connection id.
+func (s *poolServer) write(id int, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.conns[id].WriteMessage(websocket.TextMessage, []byte(line+"\r\n"))
+}
+
+// waitFor polls cond until it is true or the test times out.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newTestPool(t *testing.T, s *poolServer, opts ...IRCPoolOption) *IRCPool {
+	t.Helper()
+	opts = append([]IRCPoolOption{
+		WithIRCPoolSize(2),
+		WithIRCPoolOptions(WithIRCURL(s.URL())),
+		WithIRCPoolReconnectDelay(10 * time.Millisecond),
+	}, opts...)
+	pool, err := NewIRCPool("testuser", "token", opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = pool.Close() })
+	return pool
+}
+
+func TestNewIRCPool_Invalid(t *testing.T) {
+	if _, err := NewIRCPool("", "token"); !errors.Is(err, ErrIRCInvalidNick) {
+		t.Errorf("expected ErrIRCInvalidNick, got %v", err)
+	}
+	if _, err := NewIRCPool("nick", ""); !errors.Is(err, ErrIRCInvalidToken) {
+		t.Errorf("expected ErrIRCInvalidToken, got %v", err)
+	}
+}
+
+func TestIRCPool_ConnectAfterClose(t *testing.T) {
+	s := newPoolServer(t)
+	pool := newTestPool(t, s)
+
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := pool.Connect(context.Background()); !errors.Is(err, ErrIRCPoolClosed) {
+		t.Errorf("expected ErrIRCPoolClosed, got %v", err)
+	}
+	if pool.IsConnected() {
+		t.Error("expected a closed pool to stay disconnected")
+	}
+}
+
+func TestIRCPool_Join(t *testing.T) {
+	s := newPoolServer(t)
+	pool := newTestPool(t, s, WithIRCPoolJoinLimit(4, 300*time.Millisecond))
+
+	_ = pool.Join("a", "#B", "c", "d", "e", "f")
+	start := time.Now()
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := pool.Connect(context.Background()); !errors.Is(err, ErrIRCAlreadyConnected) {
+		t.Errorf("expected ErrIRCAlreadyConnected, got %v", err)
+	}
+
+	waitFor(t, "6 JOINs", func() bool { return len(s.sent(0, "JOIN"))+len(s.sent(1, "JOIN")) == 6 })
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("expected the join limit to delay the last JOINs, took %v", elapsed)
+	}
+	if counts := pool.ChannelCounts(); counts[0] != 3 || counts[1] != 3 {
+		t.Errorf("expected 3 channels per connection, got %v", counts)
+	}
+	if got := strings.Join(pool.GetJoinedChannels(), ","); got != "a,b,c,d,e,f" {
+		t.Errorf("unexpected channels %s", got)
+	}
+
+	if err := pool.Part("a"); err != nil {
+		t.Fatalf("Part failed: %v", err)
+	}
+	waitFor(t, "PART", func() bool { return len(s.sent(0, "PART #a"))+len(s.sent(1, "PART #a")) == 1 })
+	if counts := pool.ChannelCounts(); counts[0]+counts[1] != 5 {
+		t.Errorf("expected 5 channels, got %v", counts)
+	}
+}
+
+func TestIRCPool_Routing(t *testing.T) {
+	s := newPoolServer(t)
+	pool := newTestPool(t, s)
+
+	messages := make(chan *ChatMessage, 10)
+	whispers := make(chan *Whisper, 10)
+	pool.OnMessage(func(msg *ChatMessage) { messages <- msg })
+	pool.OnWhisper(func(w *Whisper) { whispers <- w })
+
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	_ = pool.Join("a", "b")
+	waitFor(t, "JOINs", func() bool { return len(s.sent(0, "JOIN #a")) == 1 && len(s.sent(1, "JOIN #b")) == 1 })
+
+	if err := pool.Say("b", "hello"); err != nil {
+		t.Fatalf("Say failed: %v", err)
+	}
+	waitFor(t, "PRIVMSG", func() bool { return len(s.sent(1, "PRIVMSG #b :hello")) == 1 })
+	if len(s.sent(0, "PRIVMSG")) != 0 {
+		t.Error("expected the message on the connection that joined the channel")
+	}
+
+	s.write(1, "@id=1 :viewer!viewer@viewer.tmi.twitch.tv PRIVMSG #b :hi")
+	select {
+	case msg := <-messages:
+		if msg.Channel != "b" || msg.Message != "hi" {
+			t.Errorf("unexpected message %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+
+	for id := 0; id < 2; id++ {
+		s.write(id, "@message-id=1 :viewer!viewer@viewer.tmi.twitch.tv WHISPER testuser :psst")
+	}
+	select {
+	case <-whispers:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for whisper")
+	}
+	select {
+	case <-whispers:
+		t.Error("expected the whisper once")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestIRCPool_SharedRateLimit(t *testing.T) {
+	s := newPoolServer(t)
+	pool := newTestPool(t, s, WithIRCPoolOptions(WithIRCRateLimit(IRCRateLimit{
+		Messages:        3,
+		Window:          time.Minute,
+		ChannelInterval: time.Millisecond,
+	})))
+
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	_ = pool.Join("a", "b")
+	waitFor(t, "JOINs", func() bool { return len(s.sent(0, "JOIN #a")) == 1 && len(s.sent(1, "JOIN #b")) == 1 })
+
+	for i := 0; i < 2; i++ {
+		_ = pool.Say("a", "hello")
+		_ = pool.Say("b", "hello")
+	}
+	privmsgs := func() int { return len(s.sent(0, "PRIVMSG")) + len(s.sent(1, "PRIVMSG")) }
+	waitFor(t, "PRIVMSGs", func() bool { return privmsgs() == 3 })
+	time.Sleep(200 * time.Millisecond)
+	if n := privmsgs(); n != 3 {
+		t.Errorf("expected the message limit to count both connections, got %d PRIVMSGs", n)
+	}
+}
+
+func TestIRCPool_Rebalance(t *testing.T) {
+	s := newPoolServer(t)
+	pool := newTestPool(t, s)
+	disconnects := make(chan struct{}, 1)
+	pool.OnDisconnect(func() { disconnects <- struct{}{} })
+
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	_ = pool.Join("a", "b", "c", "d")
+	waitFor(t, "JOINs", func() bool { return len(s.sent(0, "JOIN"))+len(s.sent(1, "JOIN")) == 4 })
+
+	s.mu.Lock()
+	_ = s.conns[0].Close()
+	s.mu.Unlock()
+	select {
+	case <-disconnects:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for disconnect")
+	}
+
+	waitFor(t, "channels to move", func() bool { return len(s.sent(1, "JOIN")) == 4 })
+
+	// The replacement takes back an even share of the channels
+	waitFor(t, "channels to move back", func() bool {
+		return len(s.sent(2, "JOIN")) == 2 && len(s.sent(1, "PART")) == 2
+	})
+	if counts := pool.ChannelCounts(); counts[0] != 2 || counts[1] != 2 {
+		t.Errorf("expected 2 channels per connection, got %v", counts)
+	}
+	if !pool.IsConnected() {
+		t.Error("expected the pool to be connected")
+	}
+
+	// New channels go to the connection with the fewest
+	_ = pool.Join("e")
+	waitFor(t, "JOIN on the replacement", func() bool { return len(s.sent(2, "JOIN #e")) == 1 })
+}
+
+// stallingTransport dials WebSocket connections until stall is closed, after
+// which dials block until their context is done.
+type stallingTransport struct {
+	IRCWebSocketTransport
+	stall chan struct{}
+}
+
+func (t *stallingTransport) Dial(ctx context.Context, addr string) (IRCConn, error) {
+	select {
+	case <-t.stall:
+		<-ctx.Done()
+		return nil, ctx.Err()
+	default:
+		return t.IRCWebSocketTransport.Dial(ctx, addr)
+	}
+}
+
+func TestIRCPool_CloseWhileReplacing(t *testing.T) {
+	s := newPoolServer(t)
+	transport := &stallingTransport{stall: make(chan struct{})}
+	pool := newTestPool(t, s, WithIRCPoolOptions(WithIRCTransport(transport)))
+	errs := make(chan error, 1)
+	pool.OnError(func(err error) {
+		if !strings.Contains(err.Error(), "replacing pool connection") {
+			return
+		}
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	if err := pool.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	close(transport.stall)
+	s.mu.Lock()
+	_ = s.conns[0].Close()
+	s.mu.Unlock()
+	waitFor(t, "disconnect", func() bool { return pool.ChannelCounts()[0] == -1 })
+	time.Sleep(50 * time.Millisecond) // Let the replacement start dialing
+
+	start := time.Now()
+	_ = pool.Close()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected Close to cancel the replacement dial, took %v", elapsed)
+	}
+	select {
+	case err := <-errs:
+		t.Errorf("expected no error for a cancelled replacement, got %v", err)
+	default:
+	}
+}
@@ -0,0 +1,459 @@
+package helix
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default IRC outbound limits, matching Twitch's limits for accounts that are not
+// verified bots.
+const (
+	DefaultIRCMessageLimit           = 20
+	DefaultIRCPrivilegedMessageLimit = 100
+	DefaultIRCMessageWindow          = 30 * time.Second
+	DefaultIRCChannelInterval        = time.Second
+	DefaultIRCJoinLimit              = 20
+	DefaultIRCJoinWindow             = 10 * time.Second
+	DefaultIRCMaxQueue               = 100
+)
+
+// IRCPriority orders queued messages. Higher priority messages are sent before
+// any queued normal priority messages.
+type IRCPriority int
+
+const (
+	IRCPriorityNormal IRCPriority = iota
+	IRCPriorityHigh
+)
+
+// IRCDropPolicy decides what happens when a message is sent to a channel whose
+// queue is full.
+type IRCDropPolicy int
+
+const (
+	// IRCDropNewest rejects the new message with ErrIRCQueueFull.
+	IRCDropNewest IRCDropPolicy = iota
+	// IRCDropOldest drops the channel's oldest queued message, preferring normal
+	// priority ones, and reports it to the error handler as ErrIRCMessageDropped.
+	IRCDropOldest
+)
+
+// IRCRateLimit configures the outbound queue enabled by WithIRCRateLimit. Zero
+// fields use the Default* values, so IRCRateLimit{} applies Twitch's limits.
+type IRCRateLimit struct {
+	// Messages is how many messages may be sent per Window, counting all channels,
+	// before a message to a channel where the user is not privileged is held.
+	Messages int
+	// PrivilegedMessages is the same limit for channels where the user is the
+	// broadcaster, a moderator or a VIP, as reported by USERSTATE badges.
+	PrivilegedMessages int
+	Window             time.Duration
+	// ChannelInterval is the minimum time between messages to a channel where the
+	// user is not privileged.
+	ChannelInterval time.Duration
+	// Joins is how many channels may be joined per JoinWindow.
+	Joins      int
+	JoinWindow time.Duration
+	// MaxQueue is the number of messages each channel may have queued.
+	MaxQueue   int
+	DropPolicy IRCDropPolicy
+}
+
+// withDefaults returns the limits with zero fields set to their defaults.
+func (l IRCRateLimit) withDefaults() IRCRateLimit {
+	if l.Messages <= 0 {
+		l.Messages = DefaultIRCMessageLimit
+	}
+	if l.PrivilegedMessages <= 0 {
+		l.PrivilegedMessages = DefaultIRCPrivilegedMessageLimit
+	}
+	if l.Window <= 0 {
+		l.Window = DefaultIRCMessageWindow
+	}
+	if l.ChannelInterval <= 0 {
+		l.ChannelInterval = DefaultIRCChannelInterval
+	}
+	if l.Joins <= 0 {
+		l.Joins = DefaultIRCJoinLimit
+	}
+	if l.JoinWindow <= 0 {
+		l.JoinWindow = DefaultIRCJoinWindow
+	}
+	if l.MaxQueue <= 0 {
+		l.MaxQueue = DefaultIRCMaxQueue
+	}
+	return l
+}
+
+// WithIRCRateLimit queues outgoing chat messages and JOINs and sends them no faster
+// than Twitch allows, instead of writing them straight to the connection. Say and
+// Reply return once the message is queued; messages wait while disconnected and
+// are sent after reconnecting. Send errors go to the error handler.
+//
+// Passed to an IRCPool with WithIRCPoolOptions, the Messages and
+// PrivilegedMessages limits count messages from all of the pool's connections,
+// as Twitch counts them per account.
+func WithIRCRateLimit(limits IRCRateLimit) IRCOption {
+	return func(c *IRCClient) {
+		c.outbox = newIRCOutbox(limits.withDefaults())
+	}
+}
+
+// withIRCSendWindow makes the client's outbox count messages in w, so that
+// several connections share one message limit.
+func withIRCSendWindow(w *ircSendWindow) IRCOption {
+	return func(c *IRCClient) {
+		if c.outbox != nil {
+			c.outbox.window = w
+		}
+	}
+}
+
+// ircSendWindow records message send times within the message window. It may be
+// shared by the outboxes of several connections.
+type ircSendWindow struct {
+	mu   sync.Mutex
+	sent []time.Time
+}
+
+// ircQueued is a queued outgoing line.
+type ircQueued struct {
+	channel  string
+	line     string // The line to send; for a chat message, the prefix of text
+	text     string // Chat message, written with the duplicate bypass when sent
+	priority IRCPriority
+	join     bool
+	seq      uint64 // Enqueue order
+}
+
+// ircOutbox holds queued messages and JOINs and decides when each may be sent.
+type ircOutbox struct {
+	limits IRCRateLimit
+
+	mu         sync.Mutex
+	channels   map[string][]*ircQueued
+	joins      []*ircQueued
+	privileged map[string]bool
+	window     *ircSendWindow
+	lastSent   map[string]time.Time // Last message send time per channel
+	joinsSent  []time.Time          // JOIN send times within the join window
+	seq        uint64
+
+	wake    chan struct{}
+	running bool
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+func newIRCOutbox(limits IRCRateLimit) *ircOutbox {
+	return &ircOutbox{
+		limits:     limits,
+		channels:   make(map[string][]*ircQueued),
+		privileged: make(map[string]bool),
+		window:     &ircSendWindow{},
+		lastSent:   make(map[string]time.Time),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// push queues a message. It returns the message dropped to make room, if any.
+func (o *ircOutbox) push(item *ircQueued) (*ircQueued, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.seq++
+	item.seq = o.seq
+	if item.join {
+		for _, queued := range o.joins {
+			if queued.channel == item.channel {
+				return nil, nil
+			}
+		}
+		o.joins = append(o.joins, item)
+		o.signal()
+		return nil, nil
+	}
+
+	q := o.channels[item.channel]
+	var dropped *ircQueued
+	if len(q) >= o.limits.MaxQueue {
+		if o.limits.DropPolicy != IRCDropOldest {
+			return nil, ErrIRCQueueFull
+		}
+		i := 0
+		for j, queued := range q {
+			if queued.priority == IRCPriorityNormal {
+				i = j
+				break
+			}
+		}
+		dropped = q[i]
+		q = append(q[:i], q[i+1:]...)
+	}
+
+	// Insert after the last queued message of the same or higher priority
+	i := len(q)
+	for i > 0 && q[i-1].priority < item.priority {
+		i--
+	}
+	q = append(q, nil)
+	copy(q[i+1:], q[i:])
+	q[i] = item
+	o.channels[item.channel] = q
+	o.signal()
+	return dropped, nil
+}
+
+// next removes and returns the line that should be sent now. If none can be sent
+// yet, it returns the time until one can, or -1 if nothing is queued.
+func (o *ircOutbox) next(now time.Time) (*ircQueued, time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.window.mu.Lock()
+	defer o.window.mu.Unlock()
+
+	o.window.sent = pruneWindow(o.window.sent, now, o.limits.Window)
+	o.joinsSent = pruneWindow(o.joinsSent, now, o.limits.JoinWindow)
+
+	var best *ircQueued
+	var earliest time.Time
+	consider := func(item *ircQueued, readyAt time.Time) {
+		if readyAt.After(now) {
+			if earliest.IsZero() || readyAt.Before(earliest) {
+				earliest = readyAt
+			}
+			return
+		}
+		if best == nil || item.priority > best.priority || (item.priority == best.priority && item.seq < best.seq) {
+			best = item
+		}
+	}
+
+	for channel, q := range o.channels {
+		if len(q) > 0 {
+			consider(q[0], o.readyAt(channel, now))
+		}
+	}
+	if len(o.joins) > 0 {
+		consider(o.joins[0], windowReadyAt(o.joinsSent, o.limits.Joins, o.limits.JoinWindow, now))
+	}
+
+	switch {
+	case best == nil && earliest.IsZero():
+		return nil, -1
+	case best == nil:
+		return nil, earliest.Sub(now)
+	case best.join:
+		o.joins = o.joins[1:]
+		o.joinsSent = append(o.joinsSent, now)
+	default:
+		o.channels[best.channel] = o.channels[best.channel][1:]
+		if len(o.channels[best.channel]) == 0 {
+			delete(o.channels, best.channel)
+		}
+		o.window.sent = append(o.window.sent, now)
+		o.lastSent[best.channel] = now
+	}
+	return best, 0
+}
+
+// readyAt returns when the next message to a channel may be sent. The caller must
+// hold o.mu and o.window.mu.
+func (o *ircOutbox) readyAt(channel string, now time.Time) time.Time {
+	if o.privileged[channel] {
+		return windowReadyAt(o.window.sent, o.limits.PrivilegedMessages, o.limits.Window, now)
+	}
+	readyAt := windowReadyAt(o.window.sent, o.limits.Messages, o.limits.Window, now)
+	if last, ok := o.lastSent[channel]; ok && last.Add(o.limits.ChannelInterval).After(readyAt) {
+		readyAt = last.Add(o.limits.ChannelInterval)
+	}
+	return readyAt
+}
+
+// setPrivileged records whether the user is privileged in a channel.
+func (o *ircOutbox) setPrivileged(channel string, privileged bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.privileged[channel] = privileged
+	o.signal()
+}
+
+// forget drops the queued messages and JOIN for a channel that was parted.
+func (o *ircOutbox) forget(channel string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.channels, channel)
+	delete(o.privileged, channel)
+	delete(o.lastSent, channel)
+	for i, queued := range o.joins {
+		if queued.channel == channel {
+			o.joins = append(o.joins[:i], o.joins[i+1:]...)
+			break
+		}
+	}
+}
+
+// depth returns the number of queued messages in a channel, or in all channels
+// plus queued JOINs if channel is empty.
+func (o *ircOutbox) depth(channel string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if channel != "" {
+		return len(o.channels[channel])
+	}
+	n := len(o.joins)
+	for _, q := range o.channels {
+		n += len(q)
+	}
+	return n
+}
+
+// signal wakes the dispatcher. The caller must hold o.mu.
+func (o *ircOutbox) signal() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// start runs the dispatcher for c if it isn't already running.
+func (o *ircOutbox) start(c *IRCClient) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.running {
+		o.signal()
+		return
+	}
+	o.running = true
+	o.quit = make(chan struct{})
+	o.done = make(chan struct{})
+	go o.run(c, o.quit, o.done)
+}
+
+// stop stops the dispatcher and waits for it to exit. Queued lines are kept.
+func (o *ircOutbox) stop() {
+	o.mu.Lock()
+	if !o.running {
+		o.mu.Unlock()
+		return
+	}
+	o.running = false
+	close(o.quit)
+	done := o.done
+	o.mu.Unlock()
+	<-done
+}
+
+// run sends queued lines as the limits allow while c is connected.
+func (o *ircOutbox) run(c *IRCClient, quit, done chan struct{}) {
+	defer close(done)
+	timer := time.NewTimer(0)
+	<-timer.C
+
+	for {
+		wait := time.Duration(-1)
+		if c.IsConnected() {
+			item, d := o.next(time.Now())
+			if item != nil {
+				if err := c.writeQueued(item); err != nil {
+					loggerOrDiscard(c.logger).Warn("sending queued IRC message failed", "channel", item.channel, "error", err)
+					if c.onError != nil {
+						c.onError(fmt.Errorf("sending queued message to #%s: %w", item.channel, err))
+					}
+				}
+				continue
+			}
+			wait = d
+		}
+
+		var timeout <-chan time.Time
+		if wait >= 0 {
+			timer.Reset(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-quit:
+			timer.Stop()
+			return
+		case <-o.wake:
+		case <-timeout:
+		}
+		if timeout != nil && !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+	}
+}
+
+// writeQueued writes a line taken from the outbox.
+func (c *IRCClient) writeQueued(item *ircQueued) error {
+	if item.join {
+		return c.send(item.line)
+	}
+	return c.writeChat(item.channel, item.line, item.text)
+}
+
+// pruneWindow drops the times at or before now minus window.
+func pruneWindow(times []time.Time, now time.Time, window time.Duration) []time.Time {
+	cutoff := now.Add(-window)
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// windowReadyAt returns when fewer than limit of times fall within the window.
+func windowReadyAt(times []time.Time, limit int, window time.Duration, now time.Time) time.Time {
+	if len(times) < limit {
+		return now
+	}
+	return times[len(times)-limit].Add(window)
+}
+
+// isPrivileged reports whether USERSTATE shows the user as the broadcaster, a
+// moderator or a VIP, whose messages Twitch allows at the higher rate.
+func isPrivileged(state *UserState) bool {
+	if state.IsMod {
+		return true
+	}
+	for _, badge := range []string{"broadcaster", "moderator", "vip"} {
+		if _, ok := state.Badges[badge]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue queues a line for a channel, reporting any message dropped to make room.
+func (c *IRCClient) enqueue(item *ircQueued) error {
+	dropped, err := c.outbox.push(item)
+	if dropped != nil {
+		loggerOrDiscard(c.logger).Warn("dropped queued IRC message, queue full", "channel", dropped.channel)
+		if c.onError != nil {
+			c.onError(fmt.Errorf("%w: #%s", ErrIRCMessageDropped, dropped.channel))
+		}
+	}
+	return err
+}
+
+// QueueDepth returns the number of messages and JOINs waiting in the outbound
+// queue. It is always 0 without WithIRCRateLimit.
+func (c *IRCClient) QueueDepth() int {
+	if c.outbox == nil {
+		return 0
+	}
+	return c.outbox.depth("")
+}
+
+// ChannelQueueDepth returns the number of messages waiting to be sent to a channel.
+// It is always 0 without WithIRCRateLimit.
+func (c *IRCClient) ChannelQueueDepth(channel string) int {
+	if c.outbox == nil {
+		return 0
+	}
+	return c.outbox.depth(normalizeChannel(channel))
+}
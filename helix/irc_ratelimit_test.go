@@ -0,0 +1,255 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// drain takes every line the outbox allows at now.
+func drain(o *ircOutbox, now time.Time) []string {
+	var lines []string
+	for {
+		item, _ := o.next(now)
+		if item == nil {
+			return lines
+		}
+		lines = append(lines, item.line)
+	}
+}
+
+func TestIRCOutbox_MessageWindow(t *testing.T) {
+	o := newIRCOutbox(IRCRateLimit{Messages: 2, Window: 30 * time.Second, ChannelInterval: time.Millisecond}.withDefaults())
+	for _, ch := range []string{"a", "b", "c"} {
+		_, _ = o.push(&ircQueued{channel: ch, line: ch})
+	}
+
+	now := time.Now()
+	if got := drain(o, now); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected a and b in order, got %v", got)
+	}
+	if _, wait := o.next(now); wait != 30*time.Second {
+		t.Errorf("expected to wait 30s, got %v", wait)
+	}
+	if got := drain(o, now.Add(30*time.Second)); len(got) != 1 || got[0] != "c" {
+		t.Errorf("expected c after the window, got %v", got)
+	}
+	if _, wait := o.next(now); wait != -1 {
+		t.Errorf("expected -1 for an empty queue, got %v", wait)
+	}
+}
+
+func TestIRCOutbox_Privileged(t *testing.T) {
+	o := newIRCOutbox(IRCRateLimit{Messages: 1, PrivilegedMessages: 3}.withDefaults())
+	o.setPrivileged("mod", true)
+	for i := 0; i < 4; i++ {
+		_, _ = o.push(&ircQueued{channel: "mod", line: "m"})
+	}
+	_, _ = o.push(&ircQueued{channel: "viewer", line: "v"})
+
+	now := time.Now()
+	got := drain(o, now)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 lines sent, got %v", got)
+	}
+	if strings.Contains(strings.Join(got, ""), "v") {
+		t.Errorf("expected the viewer channel to be held at the lower limit, got %v", got)
+	}
+	if o.depth("") != 2 {
+		t.Errorf("expected 2 queued, got %d", o.depth(""))
+	}
+}
+
+func TestIRCOutbox_ChannelInterval(t *testing.T) {
+	o := newIRCOutbox(IRCRateLimit{ChannelInterval: time.Second}.withDefaults())
+	_, _ = o.push(&ircQueued{channel: "a", line: "1"})
+	_, _ = o.push(&ircQueued{channel: "a", line: "2"})
+
+	now := time.Now()
+	if got := drain(o, now); len(got) != 1 {
+		t.Fatalf("expected 1 line sent, got %v", got)
+	}
+	if _, wait := o.next(now); wait != time.Second {
+		t.Errorf("expected to wait 1s, got %v", wait)
+	}
+
+	o.setPrivileged("a", true)
+	if got := drain(o, now); len(got) != 1 {
+		t.Errorf("expected no interval in a privileged channel, got %v", got)
+	}
+}
+
+func TestIRCOutbox_Priority(t *testing.T) {
+	o := newIRCOutbox(IRCRateLimit{}.withDefaults())
+	o.setPrivileged("a", true)
+	o.setPrivileged("b", true)
+	_, _ = o.push(&ircQueued{channel: "a", line: "a1"})
+	_, _ = o.push(&ircQueued{channel: "a", line: "a2"})
+	_, _ = o.push(&ircQueued{channel: "b", line: "b1"})
+	_, _ = o.push(&ircQueued{channel: "a", line: "a3", priority: IRCPriorityHigh})
+	_, _ = o.push(&ircQueued{channel: "a", line: "a4", priority: IRCPriorityHigh})
+
+	got := strings.Join(drain(o, time.Now()), ",")
+	if got != "a3,a4,a1,a2,b1" {
+		t.Errorf("unexpected send order %s", got)
+	}
+}
+
+func TestIRCOutbox_DropPolicy(t *testing.T) {
+	t.Run("newest", func(t *testing.T) {
+		o := newIRCOutbox(IRCRateLimit{MaxQueue: 1}.withDefaults())
+		_, _ = o.push(&ircQueued{channel: "a", line: "1"})
+		if _, err := o.push(&ircQueued{channel: "a", line: "2"}); !errors.Is(err, ErrIRCQueueFull) {
+			t.Errorf("expected ErrIRCQueueFull, got %v", err)
+		}
+		if _, err := o.push(&ircQueued{channel: "b", line: "3"}); err != nil {
+			t.Errorf("expected other channels to have room, got %v", err)
+		}
+	})
+
+	t.Run("oldest", func(t *testing.T) {
+		o := newIRCOutbox(IRCRateLimit{MaxQueue: 2, DropPolicy: IRCDropOldest}.withDefaults())
+		_, _ = o.push(&ircQueued{channel: "a", line: "high", priority: IRCPriorityHigh})
+		_, _ = o.push(&ircQueued{channel: "a", line: "old"})
+		dropped, err := o.push(&ircQueued{channel: "a", line: "new"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dropped == nil || dropped.line != "old" {
+			t.Errorf("expected the oldest normal message to be dropped, got %+v", dropped)
+		}
+		o.setPrivileged("a", true)
+		if got := strings.Join(drain(o, time.Now()), ","); got != "high,new" {
+			t.Errorf("unexpected queue %s", got)
+		}
+	})
+}
+
+func TestIRCOutbox_Joins(t *testing.T) {
+	o := newIRCOutbox(IRCRateLimit{Joins: 2, JoinWindow: 10 * time.Second}.withDefaults())
+	for _, ch := range []string{"a", "b", "a", "c"} {
+		_, _ = o.push(&ircQueued{channel: ch, line: "JOIN #" + ch, join: true})
+	}
+	if o.depth("") != 3 {
+		t.Errorf("expected duplicate JOINs to be merged, got %d queued", o.depth(""))
+	}
+
+	now := time.Now()
+	if got := drain(o, now); len(got) != 2 {
+		t.Errorf("expected 2 JOINs sent, got %v", got)
+	}
+	if _, wait := o.next(now); wait != 10*time.Second {
+		t.Errorf("expected to wait 10s, got %v", wait)
+	}
+
+	o.forget("c")
+	if o.depth("") != 0 {
+		t.Errorf("expected forget to drop the queued JOIN, got %d queued", o.depth(""))
+	}
+}
+
+func TestIsPrivileged(t *testing.T) {
+	tests := []struct {
+		state *UserState
+		want  bool
+	}{
+		{&UserState{}, false},
+		{&UserState{Badges: map[string]string{"subscriber": "12"}}, false},
+		{&UserState{IsMod: true}, true},
+		{&UserState{Badges: map[string]string{"vip": "1"}}, true},
+		{&UserState{Badges: map[string]string{"broadcaster": "1"}}, true},
+	}
+	for _, tt := range tests {
+		if got := isPrivileged(tt.state); got != tt.want {
+			t.Errorf("isPrivileged(%+v) = %v, want %v", tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestIRCClient_RateLimit(t *testing.T) {
+	received := make(chan string, 10)
+	mock := newMockIRCServer(func(conn *websocket.Conn) {
+		defer func() { _ = conn.Close() }()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(":tmi.twitch.tv 001 testuser :Welcome\r\n"))
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\r\n") {
+				if strings.HasPrefix(line, "PRIVMSG") {
+					received <- line
+				}
+			}
+			if strings.Contains(string(data), "JOIN #testchannel") {
+				_ = conn.WriteMessage(websocket.TextMessage, []byte("@badges=moderator/1;mod=1 :tmi.twitch.tv USERSTATE #testchannel\r\n"))
+			}
+		}
+	})
+	defer mock.Close()
+
+	client := NewIRCClient("testuser", "token",
+		WithIRCURL(mock.URL()),
+		WithAutoReconnect(false),
+		WithIRCRateLimit(IRCRateLimit{Messages: 1, PrivilegedMessages: 3, Window: time.Hour}),
+	)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if err := client.Say("otherchannel", "first"); err != nil {
+		t.Fatalf("Say failed: %v", err)
+	}
+	select {
+	case <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for the first message")
+	}
+
+	for _, msg := range []string{"a", "b", "c"} {
+		if err := client.Say("testchannel", msg); err != nil {
+			t.Fatalf("Say failed: %v", err)
+		}
+	}
+	select {
+	case msg := <-received:
+		t.Fatalf("expected messages to be held before USERSTATE, got %s", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if depth := client.ChannelQueueDepth("#testchannel"); depth != 3 {
+		t.Errorf("expected 3 queued messages, got %d", depth)
+	}
+
+	// Joining makes the mock report moderator badges, raising the limit to 3
+	if err := client.Join("testchannel"); err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for message %d", i)
+		}
+	}
+	select {
+	case msg := <-received:
+		t.Errorf("expected the privileged limit to hold the last message, got %s", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if depth := client.QueueDepth(); depth != 1 {
+		t.Errorf("expected 1 queued message, got %d", depth)
+	}
+}
+
+func TestIRCClient_QueueDepth_Disabled(t *testing.T) {
+	client := NewIRCClient("testuser", "token")
+	if client.QueueDepth() != 0 || client.ChannelQueueDepth("a") != 0 {
+		t.Error("expected a queue depth of 0 without rate limiting")
+	}
+}
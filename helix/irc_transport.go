@@ -0,0 +1,135 @@
+package helix
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// IRCConn is a connection carrying IRC lines.
+type IRCConn interface {
+	// ReadMessage returns the next message, which may hold several
+	// CRLF-separated lines.
+	ReadMessage() ([]byte, error)
+	// WriteMessage writes a CRLF-terminated line.
+	WriteMessage(data []byte) error
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
+
+// IRCTransport dials the connections an IRCClient reads and writes IRC lines on.
+// It is set with WithIRCTransport; the default is IRCWebSocketTransport.
+type IRCTransport interface {
+	Dial(ctx context.Context, addr string) (IRCConn, error)
+	// DefaultAddr is the address used when WithIRCURL isn't set.
+	DefaultAddr() string
+}
+
+// WithIRCTransport sets the transport used to connect, so the client can use
+// plain TCP or TLS instead of WebSocket. Unless WithIRCURL is also set, the
+// client connects to the transport's DefaultAddr.
+func WithIRCTransport(transport IRCTransport) IRCOption {
+	return func(c *IRCClient) {
+		c.transport = transport
+	}
+}
+
+// IRCWebSocketTransport connects to Twitch IRC over WebSocket.
+type IRCWebSocketTransport struct {
+	// Dialer dials the WebSocket. websocket.DefaultDialer is used if nil.
+	Dialer *websocket.Dialer
+}
+
+// Dial connects to a WebSocket URL.
+func (t *IRCWebSocketTransport) Dial(ctx context.Context, addr string) (IRCConn, error) {
+	dialer := t.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	conn, _, err := dialer.DialContext(ctx, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	return wsIRCConn{conn}, nil
+}
+
+// DefaultAddr returns TwitchIRCWebSocket.
+func (t *IRCWebSocketTransport) DefaultAddr() string {
+	return TwitchIRCWebSocket
+}
+
+// wsIRCConn is an IRCConn over a WebSocket, with one or more lines per message.
+type wsIRCConn struct {
+	*websocket.Conn
+}
+
+func (c wsIRCConn) ReadMessage() ([]byte, error) {
+	_, data, err := c.Conn.ReadMessage()
+	return data, err
+}
+
+func (c wsIRCConn) WriteMessage(data []byte) error {
+	return c.Conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// IRCTCPTransport connects to Twitch IRC over TCP, with TLS if TLS is set.
+type IRCTCPTransport struct {
+	// TLS enables TLS, configured by TLSConfig if it is set.
+	TLS       bool
+	TLSConfig *tls.Config
+	// Dialer dials the TCP connection. A zero net.Dialer is used if nil.
+	Dialer *net.Dialer
+}
+
+// Dial connects to a host:port address.
+func (t *IRCTCPTransport) Dial(ctx context.Context, addr string) (IRCConn, error) {
+	dialer := t.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	var conn net.Conn
+	var err error
+	if t.TLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: t.TLSConfig}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tcpIRCConn{Conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// DefaultAddr returns TwitchIRCTCP with TLS, and TwitchIRCTCPPlain without.
+func (t *IRCTCPTransport) DefaultAddr() string {
+	if t.TLS {
+		return TwitchIRCTCP
+	}
+	return TwitchIRCTCPPlain
+}
+
+// tcpIRCConn is an IRCConn over a TCP stream, with one line per message.
+type tcpIRCConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *tcpIRCConn) ReadMessage() ([]byte, error) {
+	line, err := c.r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+func (c *tcpIRCConn) WriteMessage(data []byte) error {
+	_, err := c.Conn.Write(data)
+	return err
+}
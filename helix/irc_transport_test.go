@@ -0,0 +1,142 @@
+package helix
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveTCPIRC accepts one connection on l, welcomes it and sends every line it
+// reads to lines.
+func serveTCPIRC(t *testing.T, l net.Listener, lines chan<- string) {
+	t.Helper()
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if strings.HasPrefix(line, "NICK") {
+				// Two lines in one write, as Twitch sends them
+				_, _ = conn.Write([]byte(":tmi.twitch.tv 001 testuser :Welcome\r\n:tmi.twitch.tv 002 testuser :Your host\r\n"))
+			}
+			if strings.HasPrefix(line, "JOIN") {
+				_, _ = conn.Write([]byte("@id=1 :viewer!viewer@viewer.tmi.twitch.tv PRIVMSG #testchannel :hello\r\n"))
+			}
+			lines <- line
+		}
+	}()
+}
+
+// testTCPTransport connects a client with transport to l and checks that it can
+// join, send and receive.
+func testTCPTransport(t *testing.T, l net.Listener, transport IRCTransport) {
+	t.Helper()
+	lines := make(chan string, 20)
+	serveTCPIRC(t, l, lines)
+
+	messages := make(chan *ChatMessage, 1)
+	client := NewIRCClient("testuser", "token",
+		WithIRCTransport(transport),
+		WithIRCURL(l.Addr().String()),
+		WithAutoReconnect(false),
+		WithMessageHandler(func(msg *ChatMessage) { messages <- msg }),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if err := client.Join("testchannel"); err != nil {
+		t.Fatalf("Join failed: %v", err)
+	}
+	select {
+	case msg := <-messages:
+		if msg.Channel != "testchannel" || msg.Message != "hello" {
+			t.Errorf("unexpected message %+v", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+
+	if err := client.Say("testchannel", "hi"); err != nil {
+		t.Fatalf("Say failed: %v", err)
+	}
+	var sent []string
+	for len(sent) == 0 || sent[len(sent)-1] != "PRIVMSG #testchannel :hi" {
+		select {
+		case line := <-lines:
+			sent = append(sent, line)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for PRIVMSG, got %v", sent)
+		}
+	}
+	if !strings.HasPrefix(sent[0], "CAP REQ") || sent[1] != "PASS oauth:token" || sent[2] != "NICK testuser" {
+		t.Errorf("unexpected handshake %v", sent[:3])
+	}
+}
+
+func TestIRCTCPTransport(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	testTCPTransport(t, l, &IRCTCPTransport{})
+}
+
+func TestIRCTCPTransport_TLS(t *testing.T) {
+	// Borrow httptest's self-signed certificate for 127.0.0.1
+	srv := httptest.NewUnstartedServer(nil)
+	srv.StartTLS()
+	cert := srv.TLS.Certificates[0]
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	srv.Close()
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	testTCPTransport(t, l, &IRCTCPTransport{TLS: true, TLSConfig: &tls.Config{RootCAs: roots}})
+}
+
+func TestIRCTransport_DefaultAddr(t *testing.T) {
+	tests := []struct {
+		transport IRCTransport
+		want      string
+	}{
+		{nil, TwitchIRCWebSocket},
+		{&IRCTCPTransport{}, TwitchIRCTCPPlain},
+		{&IRCTCPTransport{TLS: true}, TwitchIRCTCP},
+	}
+	for _, tt := range tests {
+		var opts []IRCOption
+		if tt.transport != nil {
+			opts = append(opts, WithIRCTransport(tt.transport))
+		}
+		if client := NewIRCClient("testuser", "token", opts...); client.url != tt.want {
+			t.Errorf("expected %s, got %s", tt.want, client.url)
+		}
+	}
+
+	client := NewIRCClient("testuser", "token", WithIRCURL("localhost:6667"), WithIRCTransport(&IRCTCPTransport{}))
+	if client.url != "localhost:6667" {
+		t.Errorf("expected WithIRCURL to take precedence, got %s", client.url)
+	}
+}
@@ -0,0 +1,128 @@
+package helix
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces secrets in log output.
+const redacted = "[REDACTED]"
+
+// sensitiveLogKeys are attribute keys whose values are always redacted.
+var sensitiveLogKeys = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"token":         true,
+	"password":      true,
+	"secret":        true,
+	"client_secret": true,
+	"authorization": true,
+}
+
+// secretPattern matches secrets embedded in strings: "oauth:" IRC passwords, bearer
+// tokens, and token fields in query strings, form bodies and JSON.
+var secretPattern = regexp.MustCompile(`(?i)((?:access_token|refresh_token|id_token|client_secret|password)"?\s*[=:]\s*"?|oauth:|bearer\s+)[^\s&",]+`)
+
+// NewRedactingHandler returns a slog.Handler that passes records to h with access
+// tokens, refresh tokens, client secrets and "oauth:" passwords replaced by
+// "[REDACTED]". Attributes with a sensitive key such as "access_token" are redacted
+// entirely; other string and error values have embedded secrets redacted.
+//
+// Loggers passed to this package's clients are wrapped automatically.
+func NewRedactingHandler(h slog.Handler) slog.Handler {
+	if r, ok := h.(*redactingHandler); ok {
+		return r
+	}
+	return &redactingHandler{h: h}
+}
+
+type redactingHandler struct {
+	h slog.Handler
+}
+
+func (r *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return r.h.Enabled(ctx, level)
+}
+
+func (r *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	out := slog.NewRecord(record.Time, record.Level, redactSecrets(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return r.h.Handle(ctx, out)
+}
+
+func (r *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redactedAttrs[i] = redactAttr(a)
+	}
+	return &redactingHandler{h: r.h.WithAttrs(redactedAttrs)}
+}
+
+func (r *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{h: r.h.WithGroup(name)}
+}
+
+// redactAttr redacts an attribute by key, or the secrets embedded in its value.
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if sensitiveLogKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(redactSecrets(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, g := range group {
+			attrs[i] = redactAttr(g)
+		}
+		a.Value = slog.GroupValue(attrs...)
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case *Token, Token, *OIDCToken, OIDCToken:
+			a.Value = slog.StringValue(redacted)
+		case error:
+			a.Value = slog.StringValue(redactSecrets(v.Error()))
+		}
+	}
+	return a
+}
+
+// redactSecrets replaces secrets embedded in s.
+func redactSecrets(s string) string {
+	return secretPattern.ReplaceAllString(s, "${1}"+redacted)
+}
+
+// newLogger wraps logger so its output is redacted. It returns nil for a nil logger.
+func newLogger(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return nil
+	}
+	return slog.New(NewRedactingHandler(logger.Handler()))
+}
+
+// loggerOrDiscard returns logger, or a logger that discards everything if it is nil.
+func loggerOrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return discardLogger
+	}
+	return logger
+}
+
+var discardLogger = slog.New(discardHandler{})
+
+// discardHandler drops all records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
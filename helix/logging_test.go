@@ -0,0 +1,197 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes from background goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestLogger() (*slog.Logger, *syncBuffer) {
+	buf := &syncBuffer{}
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), buf
+}
+
+func TestRedactingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRedactingHandler(slog.NewJSONHandler(&buf, nil)))
+
+	logger.With("refresh_token", "with-secret").Info("sending PASS oauth:irc-secret",
+		"access_token", "key-secret",
+		"Authorization", "Bearer header-secret",
+		"url", "https://id.twitch.tv/oauth2/token?client_secret=query-secret&grant_type=refresh_token",
+		"body", `{"access_token":"json-secret","expires_in":3600}`,
+		"header", "Bearer bearer-secret",
+		"error", errors.New("request failed: password=error-secret"),
+		"token", &Token{AccessToken: "struct-secret"},
+		"oidc", &OIDCToken{Token: Token{AccessToken: "oidc-secret"}, IDToken: "id-secret"},
+		"oidc_value", OIDCToken{Token: Token{RefreshToken: "oidc-value-secret"}},
+		slog.Group("request", "refresh_token", "group-secret"),
+		"user", "someone",
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"with", "irc", "key", "header", "query", "json", "bearer", "error", "struct", "oidc", "id", "oidc-value", "group"} {
+		if strings.Contains(out, secret+"-secret") {
+			t.Errorf("expected %s secret to be redacted: %s", secret, out)
+		}
+	}
+	for _, kept := range []string{"someone", "grant_type=refresh_token", "expires_in", "request failed", "oauth:[REDACTED]"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("expected %q to be kept: %s", kept, out)
+		}
+	}
+}
+
+func TestNewRedactingHandler_NoDoubleWrap(t *testing.T) {
+	h := NewRedactingHandler(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	if NewRedactingHandler(h) != h {
+		t.Error("expected an already redacting handler to be returned as is")
+	}
+}
+
+func TestLoggerOrDiscard(t *testing.T) {
+	if loggerOrDiscard(nil).Enabled(context.Background(), slog.LevelError) {
+		t.Error("expected discard logger to be disabled")
+	}
+	if newLogger(nil) != nil {
+		t.Error("expected nil logger to stay nil")
+	}
+}
+
+func TestClient_LogsRetries(t *testing.T) {
+	var requests int
+	logger, buf := newTestLogger()
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithLogger(logger), WithExponentialBackoff(time.Millisecond))
+	defer server.Close()
+	client.authClient.SetToken(&Token{AccessToken: "secret-access-token"})
+
+	if _, err := client.GetUsers(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "rate limited, waiting to retry") || !strings.Contains(out, "endpoint=/users") {
+		t.Errorf("expected rate limit wait to be logged, got: %s", out)
+	}
+	if strings.Contains(out, "secret-access-token") {
+		t.Errorf("expected no token in logs, got: %s", out)
+	}
+}
+
+func TestIRCClient_Logs(t *testing.T) {
+	mock := newMockIRCServer(func(conn *websocket.Conn) {
+		for i := 0; i < 3; i++ {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(":tmi.twitch.tv 001 testuser :Welcome, GLHF!\r\n"))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("@msg-id=msg_duplicate :tmi.twitch.tv NOTICE #channel :Your message is identical to the previous one.\r\n"))
+		time.Sleep(200 * time.Millisecond)
+	})
+	defer mock.Close()
+
+	logger, buf := newTestLogger()
+	notices := make(chan struct{})
+	client := NewIRCClient("testuser", "oauth:irc-password",
+		WithIRCURL(mock.URL()),
+		WithAutoReconnect(false),
+		WithIRCLogger(logger),
+		WithNoticeHandler(func(*Notice) { close(notices) }),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	select {
+	case <-notices:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for notice")
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "connected to IRC") || !strings.Contains(out, "nick=testuser") {
+		t.Errorf("expected connect to be logged, got: %s", out)
+	}
+	if !strings.Contains(out, "message dropped by Twitch") || !strings.Contains(out, "msg_id=msg_duplicate") {
+		t.Errorf("expected dropped message to be logged, got: %s", out)
+	}
+	if strings.Contains(out, "irc-password") {
+		t.Errorf("expected no password in logs, got: %s", out)
+	}
+}
+
+func TestEventSubWebSocketClient_Logs(t *testing.T) {
+	mock := newMockWSServer(func(conn *websocket.Conn) {
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageID: "1", MessageType: WSMessageTypeWelcome, MessageTimestamp: time.Now()},
+			Payload:  mustMarshal(WebSocketWelcomePayload{Session: WebSocketSession{ID: "session-1", KeepaliveTimeoutSeconds: 10}}),
+		})
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageID: "2", MessageType: WSMessageTypeRevocation, MessageTimestamp: time.Now()},
+			Payload:  mustMarshal(WebSocketNotificationPayload{Subscription: EventSubSubscription{ID: "sub-1", Type: "channel.follow", Status: "authorization_revoked"}}),
+		})
+		time.Sleep(200 * time.Millisecond)
+	})
+	defer mock.Close()
+
+	logger, buf := newTestLogger()
+	client := NewEventSubWebSocketClient(WithWSURL(mock.URL()), WithWSLogger(logger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(buf.String(), "subscription revoked") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "connected to EventSub") || !strings.Contains(out, "session_id=session-1") {
+		t.Errorf("expected connect to be logged, got: %s", out)
+	}
+	if !strings.Contains(out, "reason=authorization_revoked") {
+		t.Errorf("expected revocation to be logged, got: %s", out)
+	}
+}
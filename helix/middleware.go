@@ -46,6 +46,8 @@ func LoggingMiddleware(logger func(format string, args ...interface{})) Middlewa
 
 // RetryMiddleware creates middleware that retries failed requests.
 // This is separate from the built-in rate limit retry and handles other transient errors.
+// WithRetryPolicy covers the same failures with backoff, Retry-After and idempotency
+// handling, and should be preferred.
 func RetryMiddleware(maxRetries int, retryableStatuses ...int) Middleware {
 	statusSet := make(map[int]bool)
 	for _, s := range retryableStatuses {
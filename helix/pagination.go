@@ -0,0 +1,214 @@
+package helix
+
+import (
+	"context"
+)
+
+// PageFetcher fetches a single page of results using the given pagination parameters.
+// It is typically a closure that copies the pagination parameters into the endpoint's
+// params struct and calls the corresponding Client method, so every page goes through
+// Client.Do and benefits from caching, middleware and rate limit handling.
+type PageFetcher[T any] func(ctx context.Context, pagination *PaginationParams) (*Response[T], error)
+
+// PagerOptions configures a Pager.
+type PagerOptions struct {
+	// PageSize sets the "first" parameter for each page (0 = endpoint default)
+	PageSize int
+	// MaxItems caps the total number of items returned (0 = unlimited)
+	MaxItems int
+	// StartCursor resumes pagination from a previously returned cursor
+	StartCursor string
+}
+
+// Pager lazily walks a cursor-paginated Helix endpoint.
+// Pages are only requested when the items already fetched have been consumed.
+// A Pager is not safe for concurrent use.
+type Pager[T any] struct {
+	fetch   PageFetcher[T]
+	opts    PagerOptions
+	page    []T
+	pos     int
+	cursor  string
+	current T
+	count   int
+	done    bool
+	err     error
+}
+
+// NewPager creates a Pager that uses fetch to retrieve each page.
+//
+// Example:
+//
+//	pager := helix.NewPager(func(ctx context.Context, p *helix.PaginationParams) (*helix.Response[helix.Clip], error) {
+//	    return client.GetClips(ctx, &helix.GetClipsParams{BroadcasterID: "12345", PaginationParams: p})
+//	}, &helix.PagerOptions{PageSize: 100, MaxItems: 500})
+//
+//	for pager.Next(ctx) {
+//	    clip := pager.Item()
+//	    // ...
+//	}
+//	if err := pager.Err(); err != nil {
+//	    // handle error
+//	}
+func NewPager[T any](fetch PageFetcher[T], opts *PagerOptions) *Pager[T] {
+	p := &Pager[T]{fetch: fetch}
+	if opts != nil {
+		p.opts = *opts
+	}
+	p.cursor = p.opts.StartCursor
+	return p
+}
+
+// Next advances the pager to the next item, fetching a new page if needed.
+// It returns false when there are no more items, the MaxItems cap has been
+// reached, the context is cancelled, or an error occurred (see Err).
+func (p *Pager[T]) Next(ctx context.Context) bool {
+	if p.err != nil || p.capReached() {
+		return false
+	}
+
+	for p.pos >= len(p.page) {
+		if p.done {
+			return false
+		}
+		if !p.fetchPage(ctx) {
+			return false
+		}
+	}
+
+	p.current = p.page[p.pos]
+	p.pos++
+	p.count++
+	return true
+}
+
+// Item returns the current item. It is only valid after a call to Next returns true.
+func (p *Pager[T]) Item() T {
+	return p.current
+}
+
+// Err returns the first error encountered while fetching pages, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// Cursor returns the cursor for the next page to be fetched.
+// It can be passed as PagerOptions.StartCursor to resume pagination later.
+func (p *Pager[T]) Cursor() string {
+	return p.cursor
+}
+
+// Count returns the number of items returned so far.
+func (p *Pager[T]) Count() int {
+	return p.count
+}
+
+// NextPage returns the next batch of items. Items already buffered from the current
+// page are returned first; otherwise a new page is fetched. It returns a nil slice
+// and nil error when pagination is complete.
+func (p *Pager[T]) NextPage(ctx context.Context) ([]T, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.capReached() {
+		return nil, nil
+	}
+
+	for p.pos >= len(p.page) {
+		if p.done {
+			return nil, nil
+		}
+		if !p.fetchPage(ctx) {
+			return nil, p.err
+		}
+	}
+
+	items := p.page[p.pos:]
+	if p.opts.MaxItems > 0 {
+		if remaining := p.opts.MaxItems - p.count; len(items) > remaining {
+			items = items[:remaining]
+		}
+	}
+
+	p.pos += len(items)
+	p.count += len(items)
+	if len(items) > 0 {
+		p.current = items[len(items)-1]
+	}
+	return items, nil
+}
+
+// All fetches all remaining items, honouring the MaxItems cap.
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for {
+		items, err := p.NextPage(ctx)
+		if err != nil {
+			return all, err
+		}
+		if items == nil {
+			return all, nil
+		}
+		all = append(all, items...)
+	}
+}
+
+// ForEach calls fn for each remaining item. Iteration stops at the first error
+// returned by fn or by a page fetch.
+func (p *Pager[T]) ForEach(ctx context.Context, fn func(T) error) error {
+	for p.Next(ctx) {
+		if err := fn(p.Item()); err != nil {
+			return err
+		}
+	}
+	return p.Err()
+}
+
+// capReached reports whether the MaxItems cap has been reached.
+func (p *Pager[T]) capReached() bool {
+	return p.opts.MaxItems > 0 && p.count >= p.opts.MaxItems
+}
+
+// fetchPage requests the next page. Returns false if an error occurred.
+func (p *Pager[T]) fetchPage(ctx context.Context) bool {
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	params := &PaginationParams{
+		First: p.opts.PageSize,
+		After: p.cursor,
+	}
+	// Avoid fetching more than needed for the final page
+	if p.opts.MaxItems > 0 && params.First > 0 {
+		if remaining := p.opts.MaxItems - p.count; remaining < params.First {
+			params.First = remaining
+		}
+	}
+
+	resp, err := p.fetch(ctx, params)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	var data []T
+	next := ""
+	if resp != nil {
+		data = resp.Data
+		if resp.Pagination != nil {
+			next = resp.Pagination.Cursor
+		}
+	}
+
+	p.page = data
+	p.pos = 0
+
+	// Stop on an empty page or a missing/repeated cursor to avoid looping forever
+	if len(data) == 0 || next == "" || next == p.cursor {
+		p.done = true
+	}
+	p.cursor = next
+	return true
+}
@@ -0,0 +1,263 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pagedFollowersHandler serves total followers split into pages of the requested size.
+func pagedFollowersHandler(total int, requests *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		first := 20
+		if v := r.URL.Query().Get("first"); v != "" {
+			first, _ = strconv.Atoi(v)
+		}
+		start := 0
+		if v := r.URL.Query().Get("after"); v != "" {
+			start, _ = strconv.Atoi(v)
+		}
+
+		resp := Response[ChannelFollower]{}
+		for i := start; i < start+first && i < total; i++ {
+			resp.Data = append(resp.Data, ChannelFollower{UserID: fmt.Sprintf("%d", i)})
+		}
+		if start+first < total {
+			resp.Pagination = &Pagination{Cursor: strconv.Itoa(start + first)}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func followersFetcher(client *Client) PageFetcher[ChannelFollower] {
+	return func(ctx context.Context, p *PaginationParams) (*Response[ChannelFollower], error) {
+		return client.GetChannelFollowers(ctx, &GetChannelFollowersParams{
+			BroadcasterID:    "12345",
+			PaginationParams: p,
+		})
+	}
+}
+
+func TestPager_Next(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(pagedFollowersHandler(25, &requests))
+	defer server.Close()
+
+	pager := NewPager(followersFetcher(client), &PagerOptions{PageSize: 10})
+
+	ctx := context.Background()
+	var ids []string
+	for pager.Next(ctx) {
+		ids = append(ids, pager.Item().UserID)
+	}
+	if err := pager.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 25 {
+		t.Fatalf("expected 25 items, got %d", len(ids))
+	}
+	if ids[0] != "0" || ids[24] != "24" {
+		t.Errorf("unexpected items order: first=%s last=%s", ids[0], ids[24])
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+	if pager.Cursor() != "" {
+		t.Errorf("expected empty cursor at end, got %s", pager.Cursor())
+	}
+}
+
+func TestPager_Lazy(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(pagedFollowersHandler(100, &requests))
+	defer server.Close()
+
+	pager := NewPager(followersFetcher(client), &PagerOptions{PageSize: 10})
+
+	for i := 0; i < 10; i++ {
+		if !pager.Next(context.Background()) {
+			t.Fatal("expected item")
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request after consuming one page, got %d", requests)
+	}
+	if pager.Cursor() != "10" {
+		t.Errorf("expected cursor 10, got %s", pager.Cursor())
+	}
+}
+
+func TestPager_MaxItems(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(pagedFollowersHandler(100, &requests))
+	defer server.Close()
+
+	pager := NewPager(followersFetcher(client), &PagerOptions{PageSize: 10, MaxItems: 15})
+
+	all, err := pager.All(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 15 {
+		t.Fatalf("expected 15 items, got %d", len(all))
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+	if pager.Next(context.Background()) {
+		t.Error("expected Next to return false after cap reached")
+	}
+}
+
+func TestPager_NextPage(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(pagedFollowersHandler(25, &requests))
+	defer server.Close()
+
+	pager := NewPager(followersFetcher(client), &PagerOptions{PageSize: 10})
+	ctx := context.Background()
+
+	// Consume part of the first page via Next, then the remainder via NextPage
+	pager.Next(ctx)
+	page, err := pager.NextPage(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 9 {
+		t.Errorf("expected remaining 9 items from first page, got %d", len(page))
+	}
+
+	sizes := []int{}
+	for {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if page == nil {
+			break
+		}
+		sizes = append(sizes, len(page))
+	}
+	if len(sizes) != 2 || sizes[0] != 10 || sizes[1] != 5 {
+		t.Errorf("unexpected page sizes: %v", sizes)
+	}
+	if pager.Count() != 25 {
+		t.Errorf("expected count 25, got %d", pager.Count())
+	}
+}
+
+func TestPager_StartCursor(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(pagedFollowersHandler(25, &requests))
+	defer server.Close()
+
+	pager := NewPager(followersFetcher(client), &PagerOptions{PageSize: 10, StartCursor: "20"})
+	all, err := pager.All(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 5 || all[0].UserID != "20" {
+		t.Errorf("expected to resume at item 20, got %d items", len(all))
+	}
+}
+
+func TestPager_ContextCancelled(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(pagedFollowersHandler(25, &requests))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pager := NewPager(followersFetcher(client), &PagerOptions{PageSize: 10})
+
+	for i := 0; i < 10; i++ {
+		pager.Next(ctx)
+	}
+	cancel()
+
+	if pager.Next(ctx) {
+		t.Error("expected Next to return false after cancellation")
+	}
+	if !errors.Is(pager.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", pager.Err())
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestPager_Error(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"Bad Request","status":400,"message":"invalid"}`))
+	})
+	defer server.Close()
+
+	pager := NewPager(followersFetcher(client), nil)
+	err := pager.ForEach(context.Background(), func(ChannelFollower) error { return nil })
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 APIError, got %v", err)
+	}
+}
+
+func TestPager_RepeatedCursorStops(t *testing.T) {
+	calls := 0
+	pager := NewPager(func(ctx context.Context, p *PaginationParams) (*Response[int], error) {
+		calls++
+		return &Response[int]{Data: []int{calls}, Pagination: &Pagination{Cursor: "same"}}, nil
+	}, nil)
+
+	all, err := pager.All(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || len(all) != 2 {
+		t.Errorf("expected pagination to stop on repeated cursor, got %d calls", calls)
+	}
+}
+
+func TestPager_ForEachStopsOnCallbackError(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(pagedFollowersHandler(25, &requests))
+	defer server.Close()
+
+	stop := errors.New("stop")
+	seen := 0
+	err := NewPager(followersFetcher(client), &PagerOptions{PageSize: 10}).ForEach(context.Background(), func(ChannelFollower) error {
+		seen++
+		if seen == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("expected callback error, got %v", err)
+	}
+	if seen != 3 {
+		t.Errorf("expected 3 items seen, got %d", seen)
+	}
+}
+
+func TestPager_UsesCache(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(pagedFollowersHandler(5, &requests), WithCache(NewMemoryCache(10), time.Minute))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := NewPager(followersFetcher(client), nil).All(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected second walk to be served from cache, got %d requests", requests)
+	}
+}
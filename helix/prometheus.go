@@ -0,0 +1,283 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PrometheusOptions configures a PrometheusInstrumentation.
+type PrometheusOptions struct {
+	// Namespace is prepended to metric names, e.g. "myapp" gives "myapp_helix_requests_total".
+	Namespace string
+	// Labels lists the attribute keys exported as labels. Other attributes are dropped
+	// to keep cardinality bounded (default: method, status, endpoint, retry reason,
+	// auth operation, IRC command and direction, EventSub transport, message type and type).
+	Labels []string
+	// Buckets are the span duration histogram buckets in seconds
+	// (default: .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10).
+	Buckets []float64
+}
+
+var defaultPrometheusLabels = []string{
+	AttrMethod,
+	AttrStatus,
+	AttrEndpoint,
+	AttrRetryReason,
+	AttrAuthOperation,
+	AttrIRCCommand,
+	AttrIRCDirection,
+	AttrEventSubTransport,
+	AttrEventSubMessage,
+	AttrEventSubType,
+}
+
+var defaultPrometheusBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusInstrumentation is an Instrumentation that aggregates counters and span
+// durations in memory and serves them in the Prometheus text exposition format.
+// Counters are exported as "<name>_total" and spans as "<name>_duration_seconds"
+// histograms with an "error" label, with dots in names and label keys replaced by
+// underscores.
+//
+// Example:
+//
+//	metrics := helix.NewPrometheusInstrumentation(nil)
+//	client := helix.NewClient(clientID, authClient, helix.WithInstrumentation(metrics))
+//	http.Handle("/metrics", metrics)
+type PrometheusInstrumentation struct {
+	namespace string
+	labels    map[string]string // Attribute key to label name
+	buckets   []float64
+
+	mu         sync.Mutex
+	counters   map[string]map[string]float64        // Metric name to label set to value
+	histograms map[string]map[string]*promHistogram // Metric name to label set to histogram
+}
+
+// promHistogram is one series of a histogram.
+type promHistogram struct {
+	counts []uint64 // Per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheusInstrumentation creates a PrometheusInstrumentation. Zero options take their defaults.
+func NewPrometheusInstrumentation(opts *PrometheusOptions) *PrometheusInstrumentation {
+	var o PrometheusOptions
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.Labels) == 0 {
+		o.Labels = defaultPrometheusLabels
+	}
+	if len(o.Buckets) == 0 {
+		o.Buckets = defaultPrometheusBuckets
+	}
+
+	p := &PrometheusInstrumentation{
+		labels:     make(map[string]string, len(o.Labels)),
+		buckets:    append([]float64(nil), o.Buckets...),
+		counters:   make(map[string]map[string]float64),
+		histograms: make(map[string]map[string]*promHistogram),
+	}
+	if o.Namespace != "" {
+		p.namespace = promName(o.Namespace) + "_"
+	}
+	for _, key := range o.Labels {
+		p.labels[key] = promName(key)
+	}
+	sort.Float64s(p.buckets)
+	return p
+}
+
+// StartSpan starts a span whose duration is observed when it ends.
+func (p *PrometheusInstrumentation) StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, &promSpan{p: p, name: name, start: time.Now(), attrs: append([]Attribute(nil), attrs...)}
+}
+
+// AddCounter adds value to a counter.
+func (p *PrometheusInstrumentation) AddCounter(_ context.Context, name string, value int64, attrs ...Attribute) {
+	metric := p.namespace + promName(name) + "_total"
+	labels := p.labelSet(attrs, nil)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	series, ok := p.counters[metric]
+	if !ok {
+		series = make(map[string]float64)
+		p.counters[metric] = series
+	}
+	series[labels] += float64(value)
+}
+
+// observe records a span duration.
+func (p *PrometheusInstrumentation) observe(name string, d time.Duration, attrs []Attribute, failed bool) {
+	metric := p.namespace + promName(name) + "_duration_seconds"
+	labels := p.labelSet(attrs, &Attribute{Key: "error", Value: failed})
+	seconds := d.Seconds()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	series, ok := p.histograms[metric]
+	if !ok {
+		series = make(map[string]*promHistogram)
+		p.histograms[metric] = series
+	}
+	h, ok := series[labels]
+	if !ok {
+		h = &promHistogram{counts: make([]uint64, len(p.buckets))}
+		series[labels] = h
+	}
+	for i, bound := range p.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// labelSet renders the exported attributes as sorted, comma-separated label pairs.
+// Later attributes override earlier ones with the same key. extra is always included.
+func (p *PrometheusInstrumentation) labelSet(attrs []Attribute, extra *Attribute) string {
+	values := make(map[string]string, len(attrs)+1)
+	for _, attr := range attrs {
+		if label, ok := p.labels[attr.Key]; ok {
+			values[label] = fmt.Sprint(attr.Value)
+		}
+	}
+	if extra != nil {
+		values[extra.Key] = fmt.Sprint(extra.Value)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(promEscape(values[name]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+// ServeHTTP writes all metrics in the Prometheus text exposition format.
+func (p *PrometheusInstrumentation) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (p *PrometheusInstrumentation) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	p.mu.Lock()
+	for _, metric := range sortedKeys(p.counters) {
+		fmt.Fprintf(&buf, "# TYPE %s counter\n", metric)
+		series := p.counters[metric]
+		for _, labels := range sortedKeys(series) {
+			fmt.Fprintf(&buf, "%s%s %s\n", metric, promLabels(labels, ""), promFloat(series[labels]))
+		}
+	}
+	for _, metric := range sortedKeys(p.histograms) {
+		fmt.Fprintf(&buf, "# TYPE %s histogram\n", metric)
+		series := p.histograms[metric]
+		for _, labels := range sortedKeys(series) {
+			h := series[labels]
+			var cumulative uint64
+			for i, bound := range p.buckets {
+				cumulative += h.counts[i]
+				fmt.Fprintf(&buf, "%s_bucket%s %d\n", metric, promLabels(labels, `le="`+promFloat(bound)+`"`), cumulative)
+			}
+			fmt.Fprintf(&buf, "%s_bucket%s %d\n", metric, promLabels(labels, `le="+Inf"`), h.count)
+			fmt.Fprintf(&buf, "%s_sum%s %s\n", metric, promLabels(labels, ""), promFloat(h.sum))
+			fmt.Fprintf(&buf, "%s_count%s %d\n", metric, promLabels(labels, ""), h.count)
+		}
+	}
+	p.mu.Unlock()
+
+	return buf.WriteTo(w)
+}
+
+// promSpan times a span for PrometheusInstrumentation.
+type promSpan struct {
+	p      *PrometheusInstrumentation
+	name   string
+	start  time.Time
+	attrs  []Attribute
+	failed bool
+}
+
+func (s *promSpan) SetAttributes(attrs ...Attribute) {
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *promSpan) RecordError(err error) {
+	if err != nil {
+		s.failed = true
+	}
+}
+
+func (s *promSpan) End() {
+	s.p.observe(s.name, time.Since(s.start), s.attrs, s.failed)
+}
+
+// promName converts a name to a valid Prometheus metric or label name.
+func promName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= '0' && c <= '9' && i > 0) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// promEscape escapes a label value.
+func promEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// promLabels joins a rendered label set with an extra label pair into braces.
+func promLabels(labels, extra string) string {
+	switch {
+	case labels == "" && extra == "":
+		return ""
+	case labels == "":
+		return "{" + extra + "}"
+	case extra == "":
+		return "{" + labels + "}"
+	default:
+		return "{" + labels + "," + extra + "}"
+	}
+}
+
+func promFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
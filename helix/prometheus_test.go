@@ -0,0 +1,115 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusInstrumentation_Counters(t *testing.T) {
+	p := NewPrometheusInstrumentation(&PrometheusOptions{Namespace: "app"})
+	ctx := context.Background()
+
+	p.AddCounter(ctx, CounterHelixRequests, 1, StringAttr(AttrEndpoint, "/users"), IntAttr(AttrStatus, 200))
+	p.AddCounter(ctx, CounterHelixRequests, 2, IntAttr(AttrStatus, 200), StringAttr(AttrEndpoint, "/users"))
+	p.AddCounter(ctx, CounterHelixRequests, 1, StringAttr(AttrEndpoint, "/streams"), IntAttr(AttrStatus, 500),
+		IntAttr(AttrRateLimitRemaining, 10)) // Not an exported label
+
+	var out strings.Builder
+	if _, err := p.WriteTo(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `# TYPE app_helix_requests_total counter
+app_helix_requests_total{helix_endpoint="/streams",http_status_code="500"} 1
+app_helix_requests_total{helix_endpoint="/users",http_status_code="200"} 3
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPrometheusInstrumentation_Spans(t *testing.T) {
+	p := NewPrometheusInstrumentation(&PrometheusOptions{Buckets: []float64{1, 0.001}})
+
+	_, span := p.StartSpan(context.Background(), SpanAuthRequest, StringAttr(AttrAuthOperation, "token"))
+	span.SetAttributes(IntAttr(AttrStatus, 400))
+	span.RecordError(errors.New("failed"))
+	span.End()
+
+	var out strings.Builder
+	_, _ = p.WriteTo(&out)
+	labels := `auth_operation="token",error="true",http_status_code="400"`
+	for _, line := range []string{
+		"# TYPE auth_request_duration_seconds histogram",
+		`auth_request_duration_seconds_bucket{` + labels + `,le="0.001"} `,
+		`auth_request_duration_seconds_bucket{` + labels + `,le="1"} 1`,
+		`auth_request_duration_seconds_bucket{` + labels + `,le="+Inf"} 1`,
+		`auth_request_duration_seconds_count{` + labels + `} 1`,
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected output to contain %q, got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestPrometheusInstrumentation_EscapesLabels(t *testing.T) {
+	p := NewPrometheusInstrumentation(&PrometheusOptions{Labels: []string{"custom.key"}})
+	p.AddCounter(context.Background(), "my-counter", 1, StringAttr("custom.key", "a\"b\\c\nd"))
+
+	var out strings.Builder
+	_, _ = p.WriteTo(&out)
+	if want := `my_counter_total{custom_key="a\"b\\c\nd"} 1`; !strings.Contains(out.String(), want) {
+		t.Errorf("expected %q in output, got:\n%s", want, out.String())
+	}
+}
+
+func TestPrometheusInstrumentation_Handler(t *testing.T) {
+	p := NewPrometheusInstrumentation(nil)
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithInstrumentation(p))
+	defer server.Close()
+
+	if _, err := client.GetUsers(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	metrics := httptest.NewServer(p)
+	defer metrics.Close()
+	resp, err := http.Get(metrics.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	for _, want := range []string{
+		`helix_requests_total{helix_endpoint="/users",http_method="GET",http_status_code="200"} 1`,
+		`helix_request_duration_seconds_count{error="false",helix_endpoint="/users",http_method="GET",http_status_code="200"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected %q in output, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestPromName(t *testing.T) {
+	tests := map[string]string{
+		"helix.requests": "helix_requests",
+		"9lives":         "_lives",
+		"a-b c":          "a_b_c",
+		"ok_9":           "ok_9",
+	}
+	for in, want := range tests {
+		if got := promName(in); got != want {
+			t.Errorf("promName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
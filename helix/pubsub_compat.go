@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"regexp"
 	"sync"
 	"time"
@@ -182,6 +183,8 @@ type PubSubClient struct {
 	onConnect   func()
 	onReconnect func()
 
+	logger *slog.Logger
+
 	mu sync.RWMutex
 	wg sync.WaitGroup // tracks background goroutines (e.g., reconnect)
 }
@@ -224,6 +227,14 @@ func WithPubSubWSURL(url string) PubSubOption {
 	}
 }
 
+// WithPubSubLogger sets the logger for connects, reconnects, revocations and dropped messages.
+// It is also used by the underlying EventSub WebSocket connection.
+func WithPubSubLogger(logger *slog.Logger) PubSubOption {
+	return func(c *PubSubClient) {
+		c.logger = newLogger(logger)
+	}
+}
+
 // NewPubSubClient creates a new PubSub compatibility client.
 // The client uses EventSub WebSocket internally but exposes a PubSub-style API.
 // Returns nil if helixClient is nil.
@@ -265,6 +276,9 @@ func (c *PubSubClient) Connect(ctx context.Context) error {
 	if c.wsURL != "" {
 		wsOpts = append(wsOpts, WithWSURL(c.wsURL))
 	}
+	if c.logger != nil {
+		wsOpts = append(wsOpts, WithWSLogger(c.logger))
+	}
 
 	c.ws = NewEventSubWebSocketClient(wsOpts...)
 
@@ -445,6 +459,8 @@ func (c *PubSubClient) handleNotification(sub *EventSubSubscription, event json.
 	c.mu.RUnlock()
 
 	if !exists || handler == nil {
+		loggerOrDiscard(c.logger).Debug("dropping PubSub message", "subscription_id", sub.ID,
+			"subscription_type", sub.Type, "known_subscription", exists, "has_handler", handler != nil)
 		return
 	}
 
@@ -456,6 +472,7 @@ func (c *PubSubClient) handleNotification(sub *EventSubSubscription, event json.
 
 	msgJSON, err := json.Marshal(message)
 	if err != nil {
+		loggerOrDiscard(c.logger).Warn("dropping PubSub message", "topic", topic, "error", err)
 		if c.onError != nil {
 			c.onError(fmt.Errorf("marshaling message: %w", err))
 		}
@@ -486,6 +503,7 @@ func (c *PubSubClient) handleRevocation(sub *EventSubSubscription) {
 	}
 	c.mu.Unlock()
 
+	loggerOrDiscard(c.logger).Warn("PubSub topic subscription revoked", "topic", topic, "subscription_id", sub.ID, "reason", sub.Status)
 	if c.onError != nil {
 		c.onError(fmt.Errorf("subscription revoked: %s (topic: %s, reason: %s)",
 			sub.ID, topic, sub.Status))
@@ -524,6 +542,7 @@ func (c *PubSubClient) handleReconnect(reconnectURL string) {
 		c.mu.Lock()
 		c.sessionID = newSessionID
 		c.mu.Unlock()
+		loggerOrDiscard(c.logger).Info("PubSub session reconnected", "session_id", newSessionID)
 
 		if c.onReconnect != nil {
 			c.onReconnect()
@@ -0,0 +1,170 @@
+package helix
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter proactively paces requests before they are sent, using the
+// Ratelimit-Limit, Ratelimit-Remaining and Ratelimit-Reset headers returned by Twitch.
+//
+// Twitch rate limits each token separately (the app access token and each user
+// access token have their own bucket), so the limiter keeps one token bucket per
+// client ID and token. A single RateLimiter can be shared between several Clients
+// that talk to Twitch for the same client ID so that they draw from the same buckets.
+type RateLimiter struct {
+	mu           sync.Mutex
+	buckets      map[string]*rateBucket
+	defaultLimit int
+}
+
+// rateBucket is a token bucket that refills continuously at limit points per minute.
+type rateBucket struct {
+	limit   int       // Bucket size and points added per minute
+	tokens  float64   // Points currently available (negative when requests are queued)
+	waiting int       // Number of requests queued for a point
+	last    time.Time // Last time tokens were refilled
+	resetAt time.Time // When Twitch reports the bucket will be full again
+}
+
+// NewRateLimiter creates a rate limiter. limit is the assumed bucket size (points per minute)
+// for buckets that have not yet seen a Ratelimit-Limit header; 0 uses DefaultRateLimit.
+func NewRateLimiter(limit int) *RateLimiter {
+	if limit <= 0 {
+		limit = DefaultRateLimit
+	}
+	return &RateLimiter{
+		buckets:      make(map[string]*rateBucket),
+		defaultLimit: limit,
+	}
+}
+
+// WithRateLimiter makes the client wait for the limiter before sending each request.
+// The same limiter may be passed to several clients sharing a client ID.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// Wait blocks until a point is available in the bucket for key, or the context is done.
+// Requests queue in FIFO order; a cancelled wait returns its reserved point to the bucket.
+func (l *RateLimiter) Wait(ctx context.Context, key string) error {
+	l.mu.Lock()
+	b := l.bucket(key)
+	now := time.Now()
+	b.refill(now)
+	b.tokens--
+
+	if b.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	wait := time.Duration(-b.tokens * float64(b.interval()))
+	b.waiting++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		b.tokens++
+		b.waiting--
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		l.mu.Lock()
+		b.waiting--
+		l.mu.Unlock()
+		return nil
+	}
+}
+
+// Update adjusts the bucket for key from Twitch rate limit response headers.
+// Headers that are missing or invalid are ignored. The reported remaining points
+// only ever lower the local estimate, since requests already sent by this or other
+// clients may not yet be reflected in the response.
+func (l *RateLimiter) Update(key string, header http.Header) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key)
+	now := time.Now()
+	b.refill(now)
+
+	if limit, err := strconv.Atoi(header.Get("Ratelimit-Limit")); err == nil && limit > 0 {
+		b.limit = limit
+	}
+
+	if remaining, err := strconv.Atoi(header.Get("Ratelimit-Remaining")); err == nil && remaining >= 0 {
+		if available := float64(remaining - b.waiting); available < b.tokens {
+			b.tokens = available
+		}
+	}
+
+	if reset, err := strconv.ParseInt(header.Get("Ratelimit-Reset"), 10, 64); err == nil && reset > 0 {
+		b.resetAt = time.Unix(reset, 0)
+	}
+}
+
+// Info returns the current state of the bucket for key.
+func (l *RateLimiter) Info(key string) RateLimitInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key)
+	b.refill(time.Now())
+
+	remaining := int(b.tokens)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return RateLimitInfo{
+		Limit:     b.limit,
+		Remaining: remaining,
+		ResetAt:   b.resetAt,
+	}
+}
+
+// bucket returns the bucket for key, creating a full one if needed (must be called with lock held).
+func (l *RateLimiter) bucket(key string) *rateBucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &rateBucket{
+			limit:  l.defaultLimit,
+			tokens: float64(l.defaultLimit),
+			last:   time.Now(),
+		}
+		l.buckets[key] = b
+	}
+	return b
+}
+
+// interval returns the time it takes to refill a single point.
+func (b *rateBucket) interval() time.Duration {
+	return time.Minute / time.Duration(b.limit)
+}
+
+// refill adds the points accumulated since the last refill.
+func (b *rateBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens += float64(elapsed) / float64(b.interval())
+	if full := float64(b.limit); b.tokens > full {
+		b.tokens = full
+	}
+	b.last = now
+}
+
+// RateLimitKey returns the bucket key used by the limiter for a client ID and access token.
+func RateLimitKey(clientID, accessToken string) string {
+	return clientID + "|" + TokenHash(accessToken)
+}
@@ -0,0 +1,203 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func exhaustedHeader(limit string) http.Header {
+	h := http.Header{}
+	h.Set("Ratelimit-Limit", limit)
+	h.Set("Ratelimit-Remaining", "0")
+	h.Set("Ratelimit-Reset", "1700000000")
+	return h
+}
+
+func TestNewRateLimiter_Defaults(t *testing.T) {
+	l := NewRateLimiter(0)
+	info := l.Info("key")
+	if info.Limit != DefaultRateLimit {
+		t.Errorf("expected limit %d, got %d", DefaultRateLimit, info.Limit)
+	}
+	if info.Remaining != DefaultRateLimit {
+		t.Errorf("expected remaining %d, got %d", DefaultRateLimit, info.Remaining)
+	}
+}
+
+func TestRateLimiter_WaitConsumesPoints(t *testing.T) {
+	l := NewRateLimiter(5)
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background(), "key"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if info := l.Info("key"); info.Remaining != 0 {
+		t.Errorf("expected 0 remaining, got %d", info.Remaining)
+	}
+}
+
+func TestRateLimiter_WaitBlocksWhenExhausted(t *testing.T) {
+	l := NewRateLimiter(600) // 1 point per 100ms
+	l.Update("key", exhaustedHeader("600"))
+
+	start := time.Now()
+	if err := l.Wait(context.Background(), "key"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("expected to wait ~100ms, waited %v", elapsed)
+	}
+}
+
+func TestRateLimiter_QueuedRequestsArePaced(t *testing.T) {
+	l := NewRateLimiter(1200) // 1 point per 50ms
+	l.Update("key", exhaustedHeader("1200"))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = l.Wait(context.Background(), "key")
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 130*time.Millisecond {
+		t.Errorf("expected 3 queued requests to take ~150ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimiter_WaitContextCancelled(t *testing.T) {
+	l := NewRateLimiter(60) // 1 point per second
+	l.Update("key", exhaustedHeader("60"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := l.Wait(ctx, "key")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	l.mu.Lock()
+	waiting := l.buckets["key"].waiting
+	tokens := l.buckets["key"].tokens
+	l.mu.Unlock()
+	if waiting != 0 {
+		t.Errorf("expected no waiters, got %d", waiting)
+	}
+	if tokens < 0 {
+		t.Errorf("expected reserved point to be returned, tokens=%f", tokens)
+	}
+}
+
+func TestRateLimiter_SeparateBuckets(t *testing.T) {
+	l := NewRateLimiter(60)
+	l.Update(RateLimitKey("client", "user-token"), exhaustedHeader("60"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx, RateLimitKey("client", "app-token")); err != nil {
+		t.Errorf("expected app token bucket to be independent, got %v", err)
+	}
+}
+
+func TestRateLimiter_UpdateOnlyLowersRemaining(t *testing.T) {
+	l := NewRateLimiter(100)
+	_ = l.Wait(context.Background(), "key")
+	_ = l.Wait(context.Background(), "key")
+
+	h := http.Header{}
+	h.Set("Ratelimit-Remaining", "100")
+	l.Update("key", h)
+	if info := l.Info("key"); info.Remaining != 98 {
+		t.Errorf("expected remaining 98, got %d", info.Remaining)
+	}
+
+	h.Set("Ratelimit-Remaining", "10")
+	h.Set("Ratelimit-Limit", "50")
+	l.Update("key", h)
+	info := l.Info("key")
+	if info.Remaining != 10 {
+		t.Errorf("expected remaining 10, got %d", info.Remaining)
+	}
+	if info.Limit != 50 {
+		t.Errorf("expected limit 50, got %d", info.Limit)
+	}
+}
+
+func TestRateLimiter_UpdateIgnoresInvalidHeaders(t *testing.T) {
+	l := NewRateLimiter(100)
+	h := http.Header{}
+	h.Set("Ratelimit-Limit", "abc")
+	h.Set("Ratelimit-Remaining", "-1")
+	h.Set("Ratelimit-Reset", "nope")
+	l.Update("key", h)
+
+	info := l.Info("key")
+	if info.Limit != 100 || info.Remaining != 100 || !info.ResetAt.IsZero() {
+		t.Errorf("expected bucket unchanged, got %+v", info)
+	}
+}
+
+func TestClient_WithRateLimiter_SharedAcrossClients(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Ratelimit-Limit", "600")
+		w.Header().Set("Ratelimit-Remaining", "0")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}
+
+	limiter := NewRateLimiter(600)
+	client1, server1 := newTestClient(handler, WithRateLimiter(limiter))
+	defer server1.Close()
+	client2, server2 := newTestClient(handler, WithRateLimiter(limiter))
+	defer server2.Close()
+
+	// First request drains the shared bucket via the response headers
+	if _, err := client1.GetUsers(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client2.GetUsers(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("expected second client to wait for shared bucket, waited %v", elapsed)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestClient_WithRateLimiter_ContextCancelled(t *testing.T) {
+	var requests int32
+	limiter := NewRateLimiter(60)
+	limiter.Update(RateLimitKey("test-client-id", "test-access-token"), exhaustedHeader("60"))
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithRateLimiter(limiter))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetUsers(ctx, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected request not to be sent, got %d", requests)
+	}
+}
@@ -0,0 +1,319 @@
+package helix
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNoInteraction is returned by a Replayer for a request that was never recorded.
+var ErrNoInteraction = errors.New("no recorded interaction matches request")
+
+// sensitiveParams are query and form parameters scrubbed from recordings, in addition
+// to sensitiveLogKeys.
+var sensitiveParams = map[string]bool{
+	"code":        true,
+	"device_code": true,
+}
+
+// recordedHeaders are the response headers kept in recordings.
+var recordedHeaders = []string{
+	"Content-Type",
+	"Ratelimit-Limit",
+	"Ratelimit-Remaining",
+	"Ratelimit-Reset",
+	"Retry-After",
+}
+
+// Fixture is a set of recorded HTTP interactions, stored as JSON.
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and the response it received.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request with tokens and secrets scrubbed. Request headers
+// are not recorded.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Host   string `json:"host"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"` // Encoded with keys sorted
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response with tokens and secrets scrubbed.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// LoadFixture reads a fixture written by Fixture.Save or Recorder.Save.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing fixture %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Save writes the fixture to path as indented JSON.
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Recorder is an http.RoundTripper that sends requests through another transport
+// and records each interaction, with access tokens, refresh tokens, client secrets
+// and authorization codes scrubbed. Use it with WithHTTPClient and
+// AuthClient.SetHTTPClient to capture Helix, auth and ingest traffic:
+//
+//	recorder := helix.NewRecorder(nil)
+//	httpClient := &http.Client{Transport: recorder}
+//	authClient.SetHTTPClient(httpClient)
+//	client := helix.NewClient(clientID, authClient, helix.WithHTTPClient(httpClient))
+//	// ... make requests ...
+//	err := recorder.Save("testdata/users.json")
+type Recorder struct {
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder creates a Recorder sending requests through transport, or
+// http.DefaultTransport if it is nil.
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{transport: transport}
+}
+
+// RoundTrip sends the request and records the interaction.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := http.Header{}
+	for _, key := range recordedHeaders {
+		if v := resp.Header.Values(key); len(v) > 0 {
+			header[key] = v
+		}
+	}
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Host:   req.URL.Host,
+			Path:   req.URL.Path,
+			Query:  scrubQuery(req.URL.RawQuery),
+			Body:   scrubBody(reqBody, req.Header.Get("Content-Type")),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       scrubBody(respBody, resp.Header.Get("Content-Type")),
+		},
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Fixture returns the interactions recorded so far.
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Fixture{Interactions: append([]Interaction(nil), r.interactions...)}
+}
+
+// Save writes the interactions recorded so far to path.
+func (r *Recorder) Save(path string) error {
+	return r.Fixture().Save(path)
+}
+
+// Replayer is an http.RoundTripper that serves recorded interactions instead of
+// sending requests. Requests match an interaction on method, path and query;
+// scrubbed parameters match any value and the host is ignored, so a fixture
+// recorded against Twitch can be replayed against any base URL.
+//
+// Identical requests are served their recorded responses in order, and the last
+// one is repeated once they run out. A request that matches nothing fails with
+// ErrNoInteraction.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	served       map[string]int
+}
+
+// NewReplayer creates a Replayer serving the fixture's interactions.
+func NewReplayer(f *Fixture) *Replayer {
+	r := &Replayer{
+		interactions: make(map[string][]Interaction),
+		served:       make(map[string]int),
+	}
+	for _, i := range f.Interactions {
+		key := replayKey(i.Request.Method, i.Request.Path, i.Request.Query)
+		r.interactions[key] = append(r.interactions[key], i)
+	}
+	return r
+}
+
+// LoadReplayer creates a Replayer serving the fixture at path.
+func LoadReplayer(path string) (*Replayer, error) {
+	f, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(f), nil
+}
+
+// RoundTrip serves the recorded response for the request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	key := replayKey(req.Method, req.URL.Path, scrubQuery(req.URL.RawQuery))
+	r.mu.Lock()
+	candidates := r.interactions[key]
+	if len(candidates) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNoInteraction, key)
+	}
+	n := min(r.served[key], len(candidates)-1)
+	r.served[key]++
+	recorded := candidates[n].Response
+	r.mu.Unlock()
+
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// replayKey identifies requests that match the same recorded interactions.
+func replayKey(method, path, query string) string {
+	return method + " " + path + "?" + query
+}
+
+// isSensitiveParam reports whether a query or form parameter is scrubbed.
+func isSensitiveParam(key string) bool {
+	key = strings.ToLower(key)
+	return sensitiveLogKeys[key] || sensitiveParams[key]
+}
+
+// scrubQuery replaces sensitive parameter values and encodes the query with its
+// keys sorted. Unparsable queries have embedded secrets redacted instead.
+func scrubQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redactSecrets(rawQuery)
+	}
+	for key, vals := range values {
+		if isSensitiveParam(key) {
+			for i := range vals {
+				vals[i] = redacted
+			}
+		}
+	}
+	return values.Encode()
+}
+
+// scrubBody redacts tokens and secrets in a form or JSON body.
+func scrubBody(body []byte, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		return scrubQuery(string(body))
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err == nil && !dec.More() {
+		if scrubbed, err := json.Marshal(scrubJSON(v)); err == nil {
+			return string(scrubbed)
+		}
+	}
+	return redactSecrets(string(body))
+}
+
+// scrubJSON replaces the values of sensitive keys in decoded JSON.
+func scrubJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if isSensitiveParam(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = scrubJSON(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = scrubJSON(val)
+		}
+	case string:
+		return redactSecrets(v)
+	}
+	return v
+}
@@ -0,0 +1,144 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newRecordingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"live-access-token","expires_in":3600,"token_type":"bearer"}`))
+		case "/helix/users":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Ratelimit-Remaining", "799")
+			w.Header().Set("Set-Cookie", "session=live-cookie")
+			_ = json.NewEncoder(w).Encode(Response[User]{Data: []User{{ID: r.URL.Query().Get("id"), Login: "user" + r.URL.Query().Get("id")}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestRecorder_ScrubsSecretsAndReplays(t *testing.T) {
+	server := newRecordingServer(t)
+	defer server.Close()
+
+	recorder := NewRecorder(nil)
+	httpClient := &http.Client{Transport: recorder}
+	authClient := NewAuthClient(AuthConfig{ClientID: "client-id", ClientSecret: "live-client-secret"})
+	authClient.SetHTTPClient(httpClient)
+	authClient.SetEndpoints(server.URL+"/oauth2/token", "", "", "", "", "", "")
+	client := NewClient("client-id", authClient, WithHTTPClient(httpClient), WithBaseURL(server.URL+"/helix"))
+
+	ctx := context.Background()
+	if _, err := authClient.GetAppAccessToken(ctx); err != nil {
+		t.Fatalf("GetAppAccessToken failed: %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if _, err := client.GetUsers(ctx, &GetUsersParams{IDs: []string{id}}); err != nil {
+			t.Fatalf("GetUsers failed: %v", err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"live-access-token", "live-client-secret", "live-cookie"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("expected %s to be scrubbed from fixture: %s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), "Ratelimit-Remaining") {
+		t.Errorf("expected rate limit headers to be recorded: %s", data)
+	}
+
+	// Replay against a different base URL with a different secret
+	replayer, err := LoadReplayer(path)
+	if err != nil {
+		t.Fatalf("LoadReplayer failed: %v", err)
+	}
+	replayClient := &http.Client{Transport: replayer}
+	replayAuth := NewAuthClient(AuthConfig{ClientID: "client-id", ClientSecret: "other-secret"})
+	replayAuth.SetHTTPClient(replayClient)
+	replayAuth.SetEndpoints("https://id.example/oauth2/token", "", "", "", "", "", "")
+	client = NewClient("client-id", replayAuth, WithHTTPClient(replayClient), WithBaseURL("https://api.example/helix"))
+
+	token, err := replayAuth.GetAppAccessToken(ctx)
+	if err != nil {
+		t.Fatalf("replayed GetAppAccessToken failed: %v", err)
+	}
+	if token.AccessToken != redacted {
+		t.Errorf("expected scrubbed access token, got %q", token.AccessToken)
+	}
+	resp, err := client.GetUsers(ctx, &GetUsersParams{IDs: []string{"2"}})
+	if err != nil {
+		t.Fatalf("replayed GetUsers failed: %v", err)
+	}
+	if resp.Data[0].Login != "user2" {
+		t.Errorf("expected user2, got %+v", resp.Data)
+	}
+	if client.GetRateLimitInfo().Remaining != 799 {
+		t.Errorf("expected replayed rate limit headers, got %+v", client.GetRateLimitInfo())
+	}
+
+	_, err = client.GetUsers(ctx, &GetUsersParams{IDs: []string{"3"}})
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction, got %v", err)
+	}
+}
+
+func TestReplayer_ServesIdenticalRequestsInOrder(t *testing.T) {
+	fixture := &Fixture{Interactions: []Interaction{
+		{Request: RecordedRequest{Method: "GET", Path: "/helix/streams", Query: "user_id=1"}, Response: RecordedResponse{StatusCode: 200, Body: `{"data":[]}`}},
+		{Request: RecordedRequest{Method: "GET", Path: "/helix/streams", Query: "user_id=1"}, Response: RecordedResponse{StatusCode: 200, Body: `{"data":[{"id":"live"}]}`}},
+	}}
+	client := NewClient("client-id", nil,
+		WithHTTPClient(&http.Client{Transport: NewReplayer(fixture)}),
+		WithBaseURL("https://api.example/helix"),
+	)
+
+	var ids []int
+	for i := 0; i < 3; i++ {
+		resp, err := client.GetStreams(context.Background(), &GetStreamsParams{UserIDs: []string{"1"}})
+		if err != nil {
+			t.Fatalf("GetStreams failed: %v", err)
+		}
+		ids = append(ids, len(resp.Data))
+	}
+	if ids[0] != 0 || ids[1] != 1 || ids[2] != 1 {
+		t.Errorf("expected responses in order with the last repeated, got %v", ids)
+	}
+}
+
+func TestScrubBody(t *testing.T) {
+	form := scrubBody([]byte("client_id=abc&client_secret=s3cret&grant_type=refresh_token&refresh_token=r3fresh"), "application/x-www-form-urlencoded")
+	if strings.Contains(form, "s3cret") || strings.Contains(form, "r3fresh") || !strings.Contains(form, "client_id=abc") {
+		t.Errorf("unexpected scrubbed form: %s", form)
+	}
+
+	body := scrubBody([]byte(`{"data":[{"id":"1","total":12345678901234567890,"nested":{"refresh_token":"r3fresh"}}]}`), "application/json")
+	if strings.Contains(body, "r3fresh") || !strings.Contains(body, "12345678901234567890") {
+		t.Errorf("unexpected scrubbed JSON: %s", body)
+	}
+
+	text := scrubBody([]byte("PASS oauth:s3cret"), "text/plain")
+	if strings.Contains(text, "s3cret") {
+		t.Errorf("unexpected scrubbed text: %s", text)
+	}
+}
@@ -0,0 +1,321 @@
+package helix
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedisCacheOptions configures a RedisCache.
+type RedisCacheOptions struct {
+	Username string        // ACL username for AUTH (optional, Redis 6+)
+	Password string        // Password for AUTH (optional)
+	DB       int           // Database selected with SELECT (default: 0)
+	Prefix   string        // Prefix for all keys (default: "helix:")
+	PoolSize int           // Maximum idle connections kept open (default: 4)
+	Timeout  time.Duration // Dial and command timeout when ctx has no deadline (default: 5s)
+	OnError  func(error)   // Called when a command fails; failed reads are treated as misses
+}
+
+// RedisCache is a Cache backed by a Redis server, spoken to with the RESP protocol
+// over plain TCP. It lets several processes share cached responses.
+type RedisCache struct {
+	addr string
+	opts RedisCacheOptions
+	pool chan *redisConn
+}
+
+// redisConn is a connection to a Redis server.
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// redisError is an error reply from the server.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// NewRedisCache creates a cache that connects to the Redis server at addr (host:port).
+// Connections are opened on first use.
+func NewRedisCache(addr string, opts *RedisCacheOptions) *RedisCache {
+	c := &RedisCache{addr: addr}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.Prefix == "" {
+		c.opts.Prefix = "helix:"
+	}
+	if c.opts.PoolSize <= 0 {
+		c.opts.PoolSize = 4
+	}
+	if c.opts.Timeout <= 0 {
+		c.opts.Timeout = 5 * time.Second
+	}
+	c.pool = make(chan *redisConn, c.opts.PoolSize)
+	return c
+}
+
+// Get retrieves a cached response. Returns nil if not found, expired or on error.
+func (c *RedisCache) Get(ctx context.Context, key string) []byte {
+	reply, err := c.do(ctx, "GET", c.opts.Prefix+key)
+	if err != nil {
+		c.reportError(err)
+		return nil
+	}
+	value, _ := reply.([]byte)
+	return value
+}
+
+// Set stores a response in the cache with the given TTL.
+// Responses with a TTL below one millisecond are not stored.
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	ms := ttl.Milliseconds()
+	if ms <= 0 {
+		return
+	}
+	if _, err := c.do(ctx, "SET", c.opts.Prefix+key, value, "PX", strconv.FormatInt(ms, 10)); err != nil {
+		c.reportError(err)
+	}
+}
+
+// Delete removes a cached response.
+func (c *RedisCache) Delete(ctx context.Context, key string) {
+	if _, err := c.do(ctx, "DEL", c.opts.Prefix+key); err != nil {
+		c.reportError(err)
+	}
+}
+
+// Clear removes all cached responses with the cache's prefix.
+// Keys belonging to other applications on the same server are left alone.
+func (c *RedisCache) Clear(ctx context.Context) {
+	pattern := redisEscapePattern(c.opts.Prefix) + "*"
+	cursor := "0"
+	for {
+		reply, err := c.do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", "500")
+		if err != nil {
+			c.reportError(err)
+			return
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			c.reportError(errors.New("redis: unexpected SCAN reply"))
+			return
+		}
+		next, _ := parts[0].([]byte)
+		keys, _ := parts[1].([]interface{})
+
+		if len(keys) > 0 {
+			args := make([]interface{}, 0, len(keys)+1)
+			args = append(args, "DEL")
+			args = append(args, keys...)
+			if _, err := c.do(ctx, args...); err != nil {
+				c.reportError(err)
+				return
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return
+		}
+	}
+}
+
+// Ping checks that the server is reachable.
+func (c *RedisCache) Ping(ctx context.Context) error {
+	_, err := c.do(ctx, "PING")
+	return err
+}
+
+// Close closes idle connections. The cache can still be used afterwards.
+func (c *RedisCache) Close() error {
+	for {
+		select {
+		case rc := <-c.pool:
+			_ = rc.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends a command and returns its reply. Arguments are strings or byte slices.
+// Replies are []byte for bulk strings, string for simple strings, int64 for integers,
+// []interface{} for arrays and nil for null replies.
+func (c *RedisCache) do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	rc, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := rc.do(c.deadline(ctx), args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection may be in an unknown state
+		_ = rc.conn.Close()
+		return nil, err
+	}
+	c.putConn(rc)
+	return reply, err
+}
+
+// getConn returns an idle connection or dials a new one.
+func (c *RedisCache) getConn(ctx context.Context) (*redisConn, error) {
+	select {
+	case rc := <-c.pool:
+		return rc, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: c.opts.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: dialing %s: %w", c.addr, err)
+	}
+	rc := &redisConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+
+	deadline := c.deadline(ctx)
+	if c.opts.Password != "" {
+		args := []interface{}{"AUTH", c.opts.Password}
+		if c.opts.Username != "" {
+			args = []interface{}{"AUTH", c.opts.Username, c.opts.Password}
+		}
+		if _, err := rc.do(deadline, args...); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	if c.opts.DB != 0 {
+		if _, err := rc.do(deadline, "SELECT", strconv.Itoa(c.opts.DB)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+// putConn returns a connection to the pool, closing it if the pool is full.
+func (c *RedisCache) putConn(rc *redisConn) {
+	select {
+	case c.pool <- rc:
+	default:
+		_ = rc.conn.Close()
+	}
+}
+
+// deadline returns the I/O deadline for a command.
+func (c *RedisCache) deadline(ctx context.Context) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline
+	}
+	return time.Now().Add(c.opts.Timeout)
+}
+
+func (c *RedisCache) reportError(err error) {
+	if c.opts.OnError != nil {
+		c.opts.OnError(err)
+	}
+}
+
+// do writes a command as a RESP array of bulk strings and reads the reply.
+func (rc *redisConn) do(deadline time.Time, args ...interface{}) (interface{}, error) {
+	if err := rc.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(rc.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		default:
+			return nil, fmt.Errorf("redis: unsupported argument type %T", arg)
+		}
+		fmt.Fprintf(rc.w, "$%d\r\n", len(b))
+		_, _ = rc.w.Write(b)
+		_, _ = rc.w.WriteString("\r\n")
+	}
+	if err := rc.w.Flush(); err != nil {
+		return nil, fmt.Errorf("redis: writing command: %w", err)
+	}
+
+	return readRESP(rc.r)
+}
+
+// readRESP reads a single RESP reply.
+func readRESP(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: reading reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid integer reply: %w", err)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length: %w", err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("redis: reading reply: %w", err)
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length: %w", err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readRESP(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+	}
+}
+
+// redisEscapePattern escapes glob characters so s matches literally in a MATCH pattern.
+func redisEscapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,283 @@
+package helix
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-process server speaking enough RESP for RedisCache.
+type fakeRedis struct {
+	listener    net.Listener
+	password    string
+	mu          sync.Mutex
+	data        map[string]fakeRedisValue
+	connections int32
+}
+
+type fakeRedisValue struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	f := &fakeRedis{listener: l, password: password, data: make(map[string]fakeRedisValue)}
+	go f.serve()
+	t.Cleanup(func() { _ = l.Close() })
+	return f
+}
+
+func (f *fakeRedis) addr() string { return f.listener.Addr().String() }
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		atomic.AddInt32(&f.connections, 1)
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+
+	for {
+		reply, err := readRESP(r)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			b, _ := item.([]byte)
+			args[i] = string(b)
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		cmd := strings.ToUpper(args[0])
+		if !authed && cmd != "AUTH" {
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		fmt.Fprint(conn, f.exec(cmd, args[1:], &authed))
+	}
+}
+
+func (f *fakeRedis) exec(cmd string, args []string, authed *bool) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch cmd {
+	case "AUTH":
+		if args[len(args)-1] != f.password {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		*authed = true
+		return "+OK\r\n"
+	case "PING", "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		v, ok := f.data[args[0]]
+		if !ok || time.Now().After(v.expiresAt) {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v.value), v.value)
+	case "SET":
+		ms, _ := strconv.Atoi(args[3])
+		f.data[args[0]] = fakeRedisValue{value: []byte(args[1]), expiresAt: time.Now().Add(time.Duration(ms) * time.Millisecond)}
+		return "+OK\r\n"
+	case "DEL":
+		n := 0
+		for _, key := range args {
+			if _, ok := f.data[key]; ok {
+				delete(f.data, key)
+				n++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "SCAN":
+		prefix := strings.ReplaceAll(strings.TrimSuffix(args[2], "*"), `\`, "")
+		var keys []string
+		for key := range f.data {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		out := fmt.Sprintf("*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+		for _, key := range keys {
+			out += fmt.Sprintf("$%d\r\n%s\r\n", len(key), key)
+		}
+		return out
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func TestRedisCache_SetGetDelete(t *testing.T) {
+	server := newFakeRedis(t, "")
+	cache := NewRedisCache(server.addr(), nil)
+	defer func() { _ = cache.Close() }()
+	ctx := context.Background()
+
+	value := []byte("binary\r\nvalue")
+	cache.Set(ctx, "key", value, time.Minute)
+	if got := cache.Get(ctx, "key"); string(got) != string(value) {
+		t.Errorf("expected %q, got %q", value, got)
+	}
+	if _, ok := server.data["helix:key"]; !ok {
+		t.Error("expected key to be stored with the default prefix")
+	}
+	if got := cache.Get(ctx, "missing"); got != nil {
+		t.Errorf("expected nil for missing key, got %q", got)
+	}
+
+	cache.Delete(ctx, "key")
+	if got := cache.Get(ctx, "key"); got != nil {
+		t.Errorf("expected nil after delete, got %q", got)
+	}
+
+	if n := atomic.LoadInt32(&server.connections); n != 1 {
+		t.Errorf("expected the connection to be reused, got %d connections", n)
+	}
+}
+
+func TestRedisCache_Expiration(t *testing.T) {
+	server := newFakeRedis(t, "")
+	cache := NewRedisCache(server.addr(), nil)
+	ctx := context.Background()
+
+	cache.Set(ctx, "key", []byte("value"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if got := cache.Get(ctx, "key"); got != nil {
+		t.Errorf("expected expired key to miss, got %q", got)
+	}
+}
+
+func TestRedisCache_ClearOnlyPrefix(t *testing.T) {
+	server := newFakeRedis(t, "")
+	cache := NewRedisCache(server.addr(), &RedisCacheOptions{Prefix: "app[1]:"})
+	ctx := context.Background()
+
+	cache.Set(ctx, "a", []byte("1"), time.Minute)
+	cache.Set(ctx, "b", []byte("2"), time.Minute)
+	server.data["other:key"] = fakeRedisValue{value: []byte("x"), expiresAt: time.Now().Add(time.Minute)}
+
+	cache.Clear(ctx)
+	if len(server.data) != 1 {
+		t.Errorf("expected only other keys to remain, got %d keys", len(server.data))
+	}
+}
+
+func TestRedisCache_Auth(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	ctx := context.Background()
+
+	cache := NewRedisCache(server.addr(), &RedisCacheOptions{Password: "secret", DB: 2})
+	if err := cache.Ping(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var reported error
+	bad := NewRedisCache(server.addr(), &RedisCacheOptions{
+		Password: "wrong",
+		OnError:  func(err error) { reported = err },
+	})
+	if got := bad.Get(ctx, "key"); got != nil {
+		t.Errorf("expected miss, got %q", got)
+	}
+	var replyErr redisError
+	if !errors.As(reported, &replyErr) || !strings.Contains(reported.Error(), "WRONGPASS") {
+		t.Errorf("expected WRONGPASS error, got %v", reported)
+	}
+}
+
+func TestRedisCache_Unreachable(t *testing.T) {
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	var reported int32
+	cache := NewRedisCache(addr, &RedisCacheOptions{
+		Timeout: 100 * time.Millisecond,
+		OnError: func(error) { atomic.AddInt32(&reported, 1) },
+	})
+	cache.Set(context.Background(), "key", []byte("value"), time.Minute)
+	if got := cache.Get(context.Background(), "key"); got != nil {
+		t.Errorf("expected miss, got %q", got)
+	}
+	if reported != 2 {
+		t.Errorf("expected 2 reported errors, got %d", reported)
+	}
+}
+
+func TestRedisCache_SharedBetweenClients(t *testing.T) {
+	server := newFakeRedis(t, "")
+
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"data":[{"id":"1"}]}`))
+	}
+	client1, server1 := newTestClient(handler)
+	defer server1.Close()
+	client2, _ := newTestClient(handler)
+	client2.baseURL = client1.baseURL
+
+	WithCache(NewRedisCache(server.addr(), nil), time.Minute)(client1)
+	WithCache(NewRedisCache(server.addr(), nil), time.Minute)(client2)
+
+	if _, err := client1.GetUsers(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := client2.GetUsers(context.Background(), nil)
+	if err != nil || resp.Data[0].ID != "1" {
+		t.Fatalf("unexpected result: %v %v", resp, err)
+	}
+	if requests != 1 {
+		t.Errorf("expected the second client to use the shared cache, got %d requests", requests)
+	}
+}
+
+func TestReadRESP(t *testing.T) {
+	input := "+OK\r\n:42\r\n$-1\r\n*2\r\n$1\r\na\r\n:1\r\n-ERR bad\r\n?\r\n"
+	r := bufio.NewReader(strings.NewReader(input))
+
+	if v, _ := readRESP(r); v != "OK" {
+		t.Errorf("expected simple string, got %v", v)
+	}
+	if v, _ := readRESP(r); v != int64(42) {
+		t.Errorf("expected integer, got %v", v)
+	}
+	if v, err := readRESP(r); v != nil || err != nil {
+		t.Errorf("expected null bulk string, got %v %v", v, err)
+	}
+	if v, _ := readRESP(r); len(v.([]interface{})) != 2 {
+		t.Errorf("expected array, got %v", v)
+	}
+	if _, err := readRESP(r); err == nil || err.Error() != "redis: ERR bad" {
+		t.Errorf("expected error reply, got %v", err)
+	}
+	if _, err := readRESP(r); err == nil {
+		t.Error("expected error for unknown reply type")
+	}
+}
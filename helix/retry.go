@@ -0,0 +1,213 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls retries of transient failures: 5xx responses, timeouts,
+// connection resets and dial errors. Rate limit (429) retries are configured
+// separately with WithRetry.
+//
+// Only idempotent requests (GET, PUT, DELETE) are retried after a response or timeout,
+// since a POST such as StartCommercial may have taken effect even though it failed.
+// Requests that could not connect are always retried because they were never sent.
+type RetryPolicy struct {
+	MaxRetries         int           // Maximum retries per request (default: 3)
+	BaseDelay          time.Duration // Backoff cap for the first retry, doubling each attempt (default: 500ms)
+	MaxDelay           time.Duration // Maximum backoff, and maximum Retry-After honored (default: 30s)
+	Statuses           []int         // Retryable status codes (default: 500, 502, 503, 504)
+	RetryNonIdempotent bool          // Also retry POST and PATCH requests (default: false)
+
+	// The retry budget limits retries across all requests made by the client so that
+	// an outage does not multiply traffic. Each request earns BudgetRatio retries,
+	// up to BudgetBurst banked retries.
+	BudgetRatio float64 // Retries earned per request (default: 0.1)
+	BudgetBurst int     // Maximum banked retries (default: 10)
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with default settings.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:  3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Statuses:    []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		BudgetRatio: 0.1,
+		BudgetBurst: 10,
+	}
+}
+
+// WithRetryPolicy enables retries of transient failures according to policy.
+// Zero fields take their defaults. A nil policy disables these retries (the default).
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Client) {
+		if policy == nil {
+			c.retryPolicy = nil
+			c.retryBudget = nil
+			return
+		}
+
+		p := *DefaultRetryPolicy()
+		if policy.MaxRetries > 0 {
+			p.MaxRetries = policy.MaxRetries
+		}
+		if policy.BaseDelay > 0 {
+			p.BaseDelay = policy.BaseDelay
+		}
+		if policy.MaxDelay > 0 {
+			p.MaxDelay = policy.MaxDelay
+		}
+		if len(policy.Statuses) > 0 {
+			p.Statuses = append([]int(nil), policy.Statuses...)
+		}
+		if policy.BudgetRatio > 0 {
+			p.BudgetRatio = policy.BudgetRatio
+		}
+		if policy.BudgetBurst > 0 {
+			p.BudgetBurst = policy.BudgetBurst
+		}
+		p.RetryNonIdempotent = policy.RetryNonIdempotent
+
+		c.retryPolicy = &p
+		c.retryBudget = newRetryBudget(p.BudgetRatio, p.BudgetBurst)
+	}
+}
+
+// IdempotentContext returns a context marking a request as safe to retry under
+// the client's RetryPolicy even if its method is POST or PATCH.
+func IdempotentContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+type idempotentKey struct{}
+
+// isIdempotent reports whether req may be retried after it reached the server.
+func (p *RetryPolicy) isIdempotent(ctx context.Context, req *Request) bool {
+	if v, ok := ctx.Value(idempotentKey{}).(bool); ok && v {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+// shouldRetry reports whether a failed request should be retried.
+func (p *RetryPolicy) shouldRetry(ctx context.Context, req *Request, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		for _, status := range p.Statuses {
+			if apiErr.StatusCode == status {
+				return p.isIdempotent(ctx, req)
+			}
+		}
+		return false
+	}
+
+	// The request was never sent
+	if isDialError(err) {
+		return true
+	}
+	if isTimeoutError(err) || isConnectionReset(err) {
+		return p.isIdempotent(ctx, req)
+	}
+	return false
+}
+
+// delay returns how long to wait before the given retry (0-based), using full jitter
+// exponential backoff or the server's Retry-After header. ok is false when the server
+// asks to wait longer than MaxDelay.
+func (p *RetryPolicy) delay(attempt int, resp *MiddlewareResponse) (d time.Duration, ok bool) {
+	if resp != nil {
+		if after, found := parseRetryAfter(resp.Headers.Get("Retry-After")); found {
+			return after, after <= p.MaxDelay
+		}
+	}
+
+	ceiling := p.MaxDelay
+	if attempt < 30 {
+		if backoff := p.BaseDelay << uint(attempt); backoff > 0 && backoff < ceiling {
+			ceiling = backoff
+		}
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1)), true
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or HTTP date form.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// isDialError reports whether err occurred while connecting, before the request was sent.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isTimeoutError reports whether err is a network or client timeout.
+func isTimeoutError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isConnectionReset reports whether the connection was closed while the request was in flight.
+func isConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// retryBudget limits retries to a fraction of requests.
+type retryBudget struct {
+	mu     sync.Mutex
+	tokens float64
+	ratio  float64
+	burst  float64
+}
+
+func newRetryBudget(ratio float64, burst int) *retryBudget {
+	return &retryBudget{tokens: float64(burst), ratio: ratio, burst: float64(burst)}
+}
+
+// deposit credits the budget for a new request.
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	b.tokens = min(b.burst, b.tokens+b.ratio)
+	b.mu.Unlock()
+}
+
+// withdraw spends one retry, reporting false if the budget is exhausted.
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
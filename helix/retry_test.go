@@ -0,0 +1,249 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_RetriesServerErrors(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"1"}]}`))
+	}, WithRetryPolicy(&RetryPolicy{BaseDelay: time.Millisecond}))
+	defer server.Close()
+
+	resp, err := client.GetUsers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Data[0].ID != "1" || requests != 3 {
+		t.Errorf("expected success after 3 requests, got %d", requests)
+	}
+}
+
+func TestRetryPolicy_MaxRetries(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}, WithRetryPolicy(&RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+	defer server.Close()
+
+	_, err := client.GetUsers(context.Background(), nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected 502 error, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+}
+
+func TestRetryPolicy_NonRetryableStatus(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}, WithRetryPolicy(&RetryPolicy{BaseDelay: time.Millisecond}))
+	defer server.Close()
+
+	if _, err := client.GetUsers(context.Background(), nil); err == nil {
+		t.Error("expected error")
+	}
+	if requests != 1 {
+		t.Errorf("expected no retries, got %d requests", requests)
+	}
+}
+
+func TestRetryPolicy_Idempotency(t *testing.T) {
+	t.Run("POST not retried", func(t *testing.T) {
+		var requests int32
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[{"id":"1"}]}`))
+		}, WithRetryPolicy(&RetryPolicy{BaseDelay: time.Millisecond}))
+		defer server.Close()
+
+		if _, err := client.StartCommercial(context.Background(), &StartCommercialParams{BroadcasterID: "1", Length: 30}); err == nil {
+			t.Error("expected error")
+		}
+		if requests != 1 {
+			t.Errorf("expected no retries, got %d requests", requests)
+		}
+	})
+
+	t.Run("POST with RetryNonIdempotent", func(t *testing.T) {
+		var requests int32
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[{"id":"1"}]}`))
+		}, WithRetryPolicy(&RetryPolicy{RetryNonIdempotent: true, BaseDelay: time.Millisecond}))
+		defer server.Close()
+
+		if _, err := client.StartCommercial(context.Background(), &StartCommercialParams{BroadcasterID: "1", Length: 30}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if requests != 2 {
+			t.Errorf("expected 1 retry, got %d requests", requests)
+		}
+	})
+
+	t.Run("POST with IdempotentContext", func(t *testing.T) {
+		var requests int32
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[{"id":"1"}]}`))
+		}, WithRetryPolicy(&RetryPolicy{BaseDelay: time.Millisecond}))
+		defer server.Close()
+
+		ctx := IdempotentContext(context.Background())
+		if _, err := client.StartCommercial(ctx, &StartCommercialParams{BroadcasterID: "1", Length: 30}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if requests != 2 {
+			t.Errorf("expected 1 retry, got %d requests", requests)
+		}
+	})
+}
+
+func TestRetryPolicy_RetryAfter(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithRetryPolicy(&RetryPolicy{MaxDelay: time.Second}))
+	defer server.Close()
+
+	// The server asks to wait longer than MaxDelay, so the error is returned
+	if _, err := client.GetUsers(context.Background(), nil); err == nil {
+		t.Error("expected error")
+	}
+	if requests != 1 {
+		t.Errorf("expected no retry, got %d requests", requests)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestRetryPolicy_DialErrors(t *testing.T) {
+	var attempts int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {},
+		WithHTTPClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&attempts, 1)
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		})}),
+		WithRetryPolicy(&RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}),
+	)
+	defer server.Close()
+
+	// POSTs are retried because the request was never sent
+	if _, err := client.StartCommercial(context.Background(), &StartCommercialParams{BroadcasterID: "1"}); err == nil {
+		t.Error("expected error")
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryPolicy_Timeouts(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithHTTPClient(&http.Client{Timeout: 30 * time.Millisecond}), WithRetryPolicy(&RetryPolicy{BaseDelay: time.Millisecond}))
+	defer server.Close()
+
+	if _, err := client.GetUsers(context.Background(), nil); err != nil {
+		t.Errorf("expected retry after timeout to succeed, got %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestRetryPolicy_Budget(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, WithRetryPolicy(&RetryPolicy{
+		MaxRetries:  5,
+		BudgetRatio: 0.01,
+		BudgetBurst: 2,
+		BaseDelay:   time.Millisecond,
+	}))
+	defer server.Close()
+
+	_, _ = client.GetUsers(context.Background(), nil)
+	if requests != 3 {
+		t.Errorf("expected the burst of 2 retries, got %d requests", requests)
+	}
+
+	_, _ = client.GetUsers(context.Background(), nil)
+	if requests != 4 {
+		t.Errorf("expected no retries with an exhausted budget, got %d requests", requests)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = 100 * time.Millisecond
+	p.MaxDelay = time.Second
+
+	for attempt := 0; attempt < 40; attempt++ {
+		d, ok := p.delay(attempt, nil)
+		ceiling := min(p.MaxDelay, p.BaseDelay<<uint(min(attempt, 10)))
+		if !ok || d < 0 || d > ceiling {
+			t.Errorf("attempt %d: delay %v outside [0, %v]", attempt, d, ceiling)
+		}
+	}
+
+	resp := &MiddlewareResponse{Headers: http.Header{"Retry-After": []string{"2"}}}
+	p.MaxDelay = 5 * time.Second
+	if d, ok := p.delay(0, resp); !ok || d != 2*time.Second {
+		t.Errorf("expected Retry-After of 2s, got %v %v", d, ok)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("5"); !ok || d != 5*time.Second {
+		t.Errorf("unexpected seconds result: %v %v", d, ok)
+	}
+	date := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(date); !ok || d <= 0 || d > 10*time.Second {
+		t.Errorf("unexpected date result: %v %v", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Error("expected invalid value to be rejected")
+	}
+	if _, ok := parseRetryAfter(""); ok {
+		t.Error("expected empty value to be rejected")
+	}
+}
@@ -0,0 +1,309 @@
+package helix
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ScopeRequirement describes the OAuth scopes a Helix endpoint needs when called
+// with a user access token.
+type ScopeRequirement struct {
+	Method     string   // Client method name, e.g. "BanUser"
+	HTTPMethod string   // HTTP method, e.g. "POST"
+	Endpoint   string   // Endpoint path, e.g. "/moderation/bans"
+	AnyOf      []string // The token must have at least one of these scopes
+}
+
+// Missing returns the scopes lacking from granted, or nil if the requirement is met.
+// Since any one of AnyOf is sufficient, either none or all of them are returned.
+func (r ScopeRequirement) Missing(granted []string) []string {
+	for _, want := range r.AnyOf {
+		for _, have := range granted {
+			if have == want {
+				return nil
+			}
+		}
+	}
+	return r.AnyOf
+}
+
+// scopeRequirements lists the scopes required by each Helix method.
+// Endpoints that need no scope, or that accept app access tokens, are omitted.
+var scopeRequirements = []ScopeRequirement{
+	// Ads
+	{"StartCommercial", http.MethodPost, "/channels/commercial", []string{ScopeChannelEditCommercial}},
+	{"GetAdSchedule", http.MethodGet, "/channels/ads", []string{ScopeChannelReadAds}},
+	{"SnoozeNextAd", http.MethodPost, "/channels/ads/schedule/snooze", []string{ScopeChannelManageAds}},
+
+	// Analytics
+	{"GetExtensionAnalytics", http.MethodGet, "/analytics/extensions", []string{ScopeAnalyticsReadExtensions}},
+	{"GetGameAnalytics", http.MethodGet, "/analytics/games", []string{ScopeAnalyticsReadGames}},
+
+	// Bits
+	{"GetBitsLeaderboard", http.MethodGet, "/bits/leaderboard", []string{ScopeBitsRead}},
+
+	// Channel points
+	{"GetCustomRewards", http.MethodGet, "/channel_points/custom_rewards", []string{ScopeChannelReadRedemptions, ScopeChannelManageRedemptions}},
+	{"CreateCustomReward", http.MethodPost, "/channel_points/custom_rewards", []string{ScopeChannelManageRedemptions}},
+	{"UpdateCustomReward", http.MethodPatch, "/channel_points/custom_rewards", []string{ScopeChannelManageRedemptions}},
+	{"DeleteCustomReward", http.MethodDelete, "/channel_points/custom_rewards", []string{ScopeChannelManageRedemptions}},
+	{"GetCustomRewardRedemptions", http.MethodGet, "/channel_points/custom_rewards/redemptions", []string{ScopeChannelReadRedemptions, ScopeChannelManageRedemptions}},
+	{"UpdateRedemptionStatus", http.MethodPatch, "/channel_points/custom_rewards/redemptions", []string{ScopeChannelManageRedemptions}},
+
+	// Channels
+	{"ModifyChannelInformation", http.MethodPatch, "/channels", []string{ScopeChannelManageBroadcast}},
+	{"GetChannelEditors", http.MethodGet, "/channels/editors", []string{ScopeChannelReadEditors}},
+	{"GetFollowedChannels", http.MethodGet, "/channels/followed", []string{ScopeUserReadFollows}},
+	{"GetVIPs", http.MethodGet, "/channels/vips", []string{ScopeChannelReadVIPs, ScopeChannelManageVIPs}},
+	{"AddChannelVIP", http.MethodPost, "/channels/vips", []string{ScopeChannelManageVIPs}},
+	{"RemoveChannelVIP", http.MethodDelete, "/channels/vips", []string{ScopeChannelManageVIPs}},
+
+	// Charity
+	{"GetCharityCampaign", http.MethodGet, "/charity/campaigns", []string{ScopeChannelReadCharity}},
+	{"GetCharityDonations", http.MethodGet, "/charity/donations", []string{ScopeChannelReadCharity}},
+
+	// Chat
+	{"GetChatters", http.MethodGet, "/chat/chatters", []string{ScopeModeratorReadChatters}},
+	{"UpdateChatSettings", http.MethodPatch, "/chat/settings", []string{ScopeModeratorManageChatSettings}},
+	{"SendChatAnnouncement", http.MethodPost, "/chat/announcements", []string{ScopeModeratorManageAnnouncements}},
+	{"SendShoutout", http.MethodPost, "/chat/shoutouts", []string{ScopeModeratorManageShoutouts}},
+	{"UpdateUserChatColor", http.MethodPut, "/chat/color", []string{ScopeUserManageChatColor}},
+	{"SendChatMessage", http.MethodPost, "/chat/messages", []string{ScopeUserWriteChat}},
+	{"GetUserEmotes", http.MethodGet, "/chat/emotes/user", []string{ScopeUserReadEmotes}},
+
+	// Clips
+	{"CreateClip", http.MethodPost, "/clips", []string{ScopeClipsEdit}},
+	{"CreateClipFromVOD", http.MethodPost, "/videos/clips", []string{ScopeEditorManageClips, ScopeChannelManageClips}},
+
+	// Goals
+	{"GetCreatorGoals", http.MethodGet, "/goals", []string{ScopeChannelReadGoals}},
+
+	// Guest Star
+	{"GetChannelGuestStarSettings", http.MethodGet, "/channels/guest_star_settings", []string{ScopeChannelReadGuestStar, ScopeChannelManageGuestStar, ScopeModeratorReadGuestStar}},
+	{"UpdateChannelGuestStarSettings", http.MethodPut, "/channels/guest_star_settings", []string{ScopeChannelManageGuestStar}},
+	{"GetGuestStarSession", http.MethodGet, "/guest_star/session", []string{ScopeChannelReadGuestStar, ScopeChannelManageGuestStar, ScopeModeratorReadGuestStar}},
+	{"CreateGuestStarSession", http.MethodPost, "/guest_star/session", []string{ScopeChannelManageGuestStar}},
+	{"EndGuestStarSession", http.MethodDelete, "/guest_star/session", []string{ScopeChannelManageGuestStar}},
+	{"GetGuestStarInvites", http.MethodGet, "/guest_star/invites", []string{ScopeChannelReadGuestStar, ScopeChannelManageGuestStar, ScopeModeratorReadGuestStar}},
+	{"SendGuestStarInvite", http.MethodPost, "/guest_star/invites", []string{ScopeChannelManageGuestStar, ScopeModeratorManageGuestStar}},
+	{"DeleteGuestStarInvite", http.MethodDelete, "/guest_star/invites", []string{ScopeChannelManageGuestStar, ScopeModeratorManageGuestStar}},
+	{"AssignGuestStarSlot", http.MethodPost, "/guest_star/slot", []string{ScopeChannelManageGuestStar, ScopeModeratorManageGuestStar}},
+	{"UpdateGuestStarSlot", http.MethodPatch, "/guest_star/slot", []string{ScopeChannelManageGuestStar, ScopeModeratorManageGuestStar}},
+	{"DeleteGuestStarSlot", http.MethodDelete, "/guest_star/slot", []string{ScopeChannelManageGuestStar, ScopeModeratorManageGuestStar}},
+	{"UpdateGuestStarSlotSettings", http.MethodPatch, "/guest_star/slot_settings", []string{ScopeChannelManageGuestStar, ScopeModeratorManageGuestStar}},
+
+	// Hype Train
+	{"GetHypeTrainEvents", http.MethodGet, "/hypetrain/events", []string{ScopeChannelReadHypeTrain}},
+	{"GetHypeTrainStatus", http.MethodGet, "/hypetrain/status", []string{ScopeChannelReadHypeTrain}},
+
+	// Moderation
+	{"GetBannedUsers", http.MethodGet, "/moderation/banned", []string{ScopeModerationRead, ScopeModeratorManageBannedUsers}},
+	{"BanUser", http.MethodPost, "/moderation/bans", []string{ScopeModeratorManageBannedUsers}},
+	{"UnbanUser", http.MethodDelete, "/moderation/bans", []string{ScopeModeratorManageBannedUsers}},
+	{"GetModerators", http.MethodGet, "/moderation/moderators", []string{ScopeModerationRead, ScopeChannelManageModerators}},
+	{"AddChannelModerator", http.MethodPost, "/moderation/moderators", []string{ScopeChannelManageModerators}},
+	{"RemoveChannelModerator", http.MethodDelete, "/moderation/moderators", []string{ScopeChannelManageModerators}},
+	{"DeleteChatMessages", http.MethodDelete, "/moderation/chat", []string{ScopeModeratorManageChatMessages}},
+	{"GetBlockedTerms", http.MethodGet, "/moderation/blocked_terms", []string{ScopeModeratorReadBlockedTerms, ScopeModeratorManageBlockedTerms}},
+	{"AddBlockedTerm", http.MethodPost, "/moderation/blocked_terms", []string{ScopeModeratorManageBlockedTerms}},
+	{"RemoveBlockedTerm", http.MethodDelete, "/moderation/blocked_terms", []string{ScopeModeratorManageBlockedTerms}},
+	{"GetShieldModeStatus", http.MethodGet, "/moderation/shield_mode", []string{ScopeModeratorReadShieldMode, ScopeModeratorManageShieldMode}},
+	{"UpdateShieldModeStatus", http.MethodPut, "/moderation/shield_mode", []string{ScopeModeratorManageShieldMode}},
+	{"WarnChatUser", http.MethodPost, "/moderation/warnings", []string{ScopeModeratorManageWarnings}},
+	{"CheckAutoModStatus", http.MethodPost, "/moderation/enforcements/status", []string{ScopeModerationRead}},
+	{"ManageHeldAutoModMessages", http.MethodPost, "/moderation/automod/message", []string{ScopeModeratorManageAutomod}},
+	{"GetAutoModSettings", http.MethodGet, "/moderation/automod/settings", []string{ScopeModeratorReadAutomodSettings, ScopeModeratorManageAutomodSettings}},
+	{"UpdateAutoModSettings", http.MethodPut, "/moderation/automod/settings", []string{ScopeModeratorManageAutomodSettings}},
+	{"GetUnbanRequests", http.MethodGet, "/moderation/unban_requests", []string{ScopeModeratorReadUnbanRequests, ScopeModeratorManageUnbanRequests}},
+	{"ResolveUnbanRequest", http.MethodPatch, "/moderation/unban_requests", []string{ScopeModeratorManageUnbanRequests}},
+	{"GetModeratedChannels", http.MethodGet, "/moderation/channels", []string{ScopeUserReadModeratedChannels}},
+	{"AddSuspiciousUserStatus", http.MethodPost, "/moderation/suspicious_users", []string{ScopeModeratorManageSuspiciousUsers}},
+	{"RemoveSuspiciousUserStatus", http.MethodDelete, "/moderation/suspicious_users", []string{ScopeModeratorManageSuspiciousUsers}},
+
+	// Polls
+	{"GetPolls", http.MethodGet, "/polls", []string{ScopeChannelReadPolls, ScopeChannelManagePolls}},
+	{"CreatePoll", http.MethodPost, "/polls", []string{ScopeChannelManagePolls}},
+	{"EndPoll", http.MethodPatch, "/polls", []string{ScopeChannelManagePolls}},
+
+	// Predictions
+	{"GetPredictions", http.MethodGet, "/predictions", []string{ScopeChannelReadPredictions, ScopeChannelManagePredictions}},
+	{"CreatePrediction", http.MethodPost, "/predictions", []string{ScopeChannelManagePredictions}},
+	{"EndPrediction", http.MethodPatch, "/predictions", []string{ScopeChannelManagePredictions}},
+
+	// Raids
+	{"StartRaid", http.MethodPost, "/raids", []string{ScopeChannelManageRaids}},
+	{"CancelRaid", http.MethodDelete, "/raids", []string{ScopeChannelManageRaids}},
+
+	// Schedule
+	{"UpdateChannelStreamSchedule", http.MethodPatch, "/schedule/settings", []string{ScopeChannelManageSchedule}},
+	{"CreateChannelStreamScheduleSegment", http.MethodPost, "/schedule/segment", []string{ScopeChannelManageSchedule}},
+	{"UpdateChannelStreamScheduleSegment", http.MethodPatch, "/schedule/segment", []string{ScopeChannelManageSchedule}},
+	{"DeleteChannelStreamScheduleSegment", http.MethodDelete, "/schedule/segment", []string{ScopeChannelManageSchedule}},
+
+	// Streams
+	{"GetFollowedStreams", http.MethodGet, "/streams/followed", []string{ScopeUserReadFollows}},
+	{"GetStreamKey", http.MethodGet, "/streams/key", []string{ScopeChannelReadStreamKey}},
+	{"CreateStreamMarker", http.MethodPost, "/streams/markers", []string{ScopeChannelManageBroadcast}},
+	{"GetStreamMarkers", http.MethodGet, "/streams/markers", []string{ScopeUserReadBroadcast, ScopeChannelManageBroadcast}},
+
+	// Subscriptions
+	{"GetBroadcasterSubscriptions", http.MethodGet, "/subscriptions", []string{ScopeChannelReadSubscriptions}},
+	{"CheckUserSubscription", http.MethodGet, "/subscriptions/user", []string{ScopeUserReadSubscriptions}},
+
+	// Users
+	{"UpdateUser", http.MethodPut, "/users", []string{ScopeUserEdit}},
+	{"GetUserBlockList", http.MethodGet, "/users/blocks", []string{ScopeUserReadBlockedUsers}},
+	{"BlockUser", http.MethodPut, "/users/blocks", []string{ScopeUserManageBlockedUsers}},
+	{"UnblockUser", http.MethodDelete, "/users/blocks", []string{ScopeUserManageBlockedUsers}},
+	{"GetUserExtensions", http.MethodGet, "/users/extensions/list", []string{ScopeUserReadBroadcast, ScopeUserEditBroadcast}},
+	{"UpdateUserExtensions", http.MethodPut, "/users/extensions", []string{ScopeUserEditBroadcast}},
+
+	// Videos
+	{"DeleteVideos", http.MethodDelete, "/videos", []string{ScopeChannelManageVideos}},
+
+	// Whispers
+	{"SendWhisper", http.MethodPost, "/whispers", []string{ScopeUserManageWhispers}},
+}
+
+var (
+	scopesByMethod   = make(map[string]ScopeRequirement, len(scopeRequirements))
+	scopesByEndpoint = make(map[string]ScopeRequirement, len(scopeRequirements))
+)
+
+func init() {
+	for _, r := range scopeRequirements {
+		scopesByMethod[r.Method] = r
+		scopesByEndpoint[r.HTTPMethod+" "+r.Endpoint] = r
+	}
+}
+
+// RequiredScopes returns the scopes accepted by a Client method (any one is sufficient),
+// or nil if the method needs no scope. For example, RequiredScopes("BanUser")
+// returns ["moderator:manage:banned_users"].
+func RequiredScopes(method string) []string {
+	return scopesByMethod[method].AnyOf
+}
+
+// ScopeRequirementFor returns the scope requirement for an HTTP method and endpoint.
+func ScopeRequirementFor(httpMethod, endpoint string) (ScopeRequirement, bool) {
+	r, ok := scopesByEndpoint[httpMethod+" "+endpoint]
+	return r, ok
+}
+
+// ScopeRequirements returns the full table of scope requirements.
+func ScopeRequirements() []ScopeRequirement {
+	out := make([]ScopeRequirement, len(scopeRequirements))
+	copy(out, scopeRequirements)
+	return out
+}
+
+// MissingScopeError is returned by a Client with scope validation enabled when the
+// current token lacks the scopes required by the endpoint. The request is not sent.
+type MissingScopeError struct {
+	Method   string   // Client method name, e.g. "BanUser"
+	Endpoint string   // Endpoint path, e.g. "/moderation/bans"
+	Missing  []string // Scopes lacking from the token (any one is sufficient)
+	Granted  []string // Scopes the token has
+}
+
+func (e *MissingScopeError) Error() string {
+	if len(e.Missing) == 1 {
+		return fmt.Sprintf("missing scope for %s (%s): %s", e.Method, e.Endpoint, e.Missing[0])
+	}
+	return fmt.Sprintf("missing scope for %s (%s): one of %s", e.Method, e.Endpoint, strings.Join(e.Missing, ", "))
+}
+
+// maxScopeCacheSize bounds the number of tokens whose validated scopes are cached.
+const maxScopeCacheSize = 1000
+
+// tokenScopes is the cached result of validating a token.
+type tokenScopes struct {
+	scopes []string
+	isUser bool // App access tokens have no user ID and no scopes
+}
+
+// WithScopeValidation enables checking the current token's scopes against
+// the endpoint's requirements before sending a request. Scopes are looked up
+// once per token with ValidateToken, falling back to Token.Scope when the client
+// has no AuthClient. Requests that would fail return a *MissingScopeError.
+func WithScopeValidation(enabled bool) Option {
+	return func(c *Client) {
+		c.scopeValidation = enabled
+	}
+}
+
+// checkScopes returns a *MissingScopeError if the token for ctx lacks the scopes req needs.
+// If the token's scopes cannot be determined, the request is allowed through.
+func (c *Client) checkScopes(ctx context.Context, req *Request) error {
+	requirement, ok := ScopeRequirementFor(req.Method, req.Endpoint)
+	if !ok {
+		return nil
+	}
+
+	token := c.currentToken(ctx)
+	if token == nil || token.AccessToken == "" {
+		return nil
+	}
+
+	scopes, ok := c.tokenScopes(ctx, token)
+	if !ok || !scopes.isUser {
+		return nil
+	}
+
+	if missing := requirement.Missing(scopes.scopes); missing != nil {
+		return &MissingScopeError{
+			Method:   requirement.Method,
+			Endpoint: requirement.Endpoint,
+			Missing:  missing,
+			Granted:  scopes.scopes,
+		}
+	}
+	return nil
+}
+
+// tokenScopes returns the scopes granted to token, validating it on first use.
+func (c *Client) tokenScopes(ctx context.Context, token *Token) (*tokenScopes, bool) {
+	key := TokenHash(token.AccessToken)
+
+	c.scopeMu.Lock()
+	cached, ok := c.scopeCache[key]
+	c.scopeMu.Unlock()
+	if ok {
+		return cached, true
+	}
+
+	var result *tokenScopes
+	if validator := c.scopeValidator(); validator != nil {
+		resp, err := validator.ValidateToken(ctx, token.AccessToken)
+		if err != nil {
+			return nil, false
+		}
+		result = &tokenScopes{scopes: resp.Scopes, isUser: resp.UserID != ""}
+	} else if len(token.Scope) > 0 {
+		result = &tokenScopes{scopes: token.Scope, isUser: true}
+	} else {
+		return nil, false
+	}
+
+	c.scopeMu.Lock()
+	if c.scopeCache == nil || len(c.scopeCache) >= maxScopeCacheSize {
+		c.scopeCache = make(map[string]*tokenScopes)
+	}
+	c.scopeCache[key] = result
+	c.scopeMu.Unlock()
+
+	return result, true
+}
+
+// scopeValidator returns the AuthClient used to validate tokens, if any.
+func (c *Client) scopeValidator() *AuthClient {
+	if c.authClient != nil {
+		return c.authClient
+	}
+	if m, ok := c.tokenProvider.(*TokenManager); ok {
+		return m.auth
+	}
+	return nil
+}
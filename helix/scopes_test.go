@@ -0,0 +1,232 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestScopeRequirements_Table(t *testing.T) {
+	methods := make(map[string]bool)
+	endpoints := make(map[string]bool)
+	for _, r := range ScopeRequirements() {
+		if r.Method == "" || r.HTTPMethod == "" || !strings.HasPrefix(r.Endpoint, "/") {
+			t.Errorf("incomplete requirement: %+v", r)
+		}
+		if len(r.AnyOf) == 0 {
+			t.Errorf("%s has no scopes", r.Method)
+		}
+		if methods[r.Method] {
+			t.Errorf("duplicate method %s", r.Method)
+		}
+		methods[r.Method] = true
+		key := r.HTTPMethod + " " + r.Endpoint
+		if endpoints[key] {
+			t.Errorf("duplicate endpoint %s", key)
+		}
+		endpoints[key] = true
+	}
+}
+
+func TestRequiredScopes(t *testing.T) {
+	scopes := RequiredScopes("BanUser")
+	if len(scopes) != 1 || scopes[0] != ScopeModeratorManageBannedUsers {
+		t.Errorf("unexpected scopes for BanUser: %v", scopes)
+	}
+	if scopes := RequiredScopes("GetUsers"); scopes != nil {
+		t.Errorf("expected no scopes for GetUsers, got %v", scopes)
+	}
+
+	r, ok := ScopeRequirementFor(http.MethodGet, "/moderation/banned")
+	if !ok || r.Method != "GetBannedUsers" {
+		t.Errorf("unexpected requirement: %+v", r)
+	}
+}
+
+func TestScopeRequirement_Missing(t *testing.T) {
+	r := ScopeRequirement{AnyOf: []string{ScopeChannelReadVIPs, ScopeChannelManageVIPs}}
+
+	if missing := r.Missing([]string{ScopeChannelManageVIPs}); missing != nil {
+		t.Errorf("expected requirement met, got missing %v", missing)
+	}
+	if missing := r.Missing([]string{ScopeChatRead}); len(missing) != 2 {
+		t.Errorf("expected both alternatives missing, got %v", missing)
+	}
+}
+
+func TestMissingScopeError_Error(t *testing.T) {
+	err := &MissingScopeError{Method: "BanUser", Endpoint: "/moderation/bans", Missing: []string{"moderator:manage:banned_users"}}
+	if got := err.Error(); got != "missing scope for BanUser (/moderation/bans): moderator:manage:banned_users" {
+		t.Errorf("unexpected message: %s", got)
+	}
+
+	err.Missing = []string{"a", "b"}
+	if got := err.Error(); !strings.HasSuffix(got, "one of a, b") {
+		t.Errorf("unexpected message: %s", got)
+	}
+}
+
+// serveTokenValidation points client's AuthClient at a validation server that
+// answers with userID and scopes, counting calls in validations.
+func serveTokenValidation(t *testing.T, client *Client, userID string, scopes []string, validations *int32) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(validations, 1)
+		_ = json.NewEncoder(w).Encode(ValidationResponse{UserID: userID, Scopes: scopes})
+	}))
+	t.Cleanup(server.Close)
+	client.authClient.SetEndpoints("", server.URL, "", "", "", "", "")
+}
+
+func TestClient_ScopeValidation_Missing(t *testing.T) {
+	var apiCalls, validations int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&apiCalls, 1)
+		_, _ = w.Write([]byte(`{"data":[{}]}`))
+	}, WithScopeValidation(true))
+	defer server.Close()
+	serveTokenValidation(t, client, "123", []string{ScopeChatRead}, &validations)
+
+	_, err := client.BanUser(context.Background(), &BanUserParams{
+		BroadcasterID: "1",
+		ModeratorID:   "123",
+		Data:          BanUserData{UserID: "2"},
+	})
+
+	var scopeErr *MissingScopeError
+	if !errors.As(err, &scopeErr) {
+		t.Fatalf("expected *MissingScopeError, got %v", err)
+	}
+	if scopeErr.Method != "BanUser" || len(scopeErr.Missing) != 1 || scopeErr.Missing[0] != ScopeModeratorManageBannedUsers {
+		t.Errorf("unexpected error: %+v", scopeErr)
+	}
+	if apiCalls != 0 {
+		t.Errorf("expected request not to be sent, got %d calls", apiCalls)
+	}
+}
+
+func TestClient_ScopeValidation_Granted(t *testing.T) {
+	var apiCalls, validations int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&apiCalls, 1)
+		_, _ = w.Write([]byte(`{"data":[{}]}`))
+	}, WithScopeValidation(true))
+	defer server.Close()
+	serveTokenValidation(t, client, "123", []string{ScopeModeratorManageBannedUsers}, &validations)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.BanUser(context.Background(), &BanUserParams{
+			BroadcasterID: "1",
+			ModeratorID:   "123",
+			Data:          BanUserData{UserID: "2"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if apiCalls != 3 {
+		t.Errorf("expected 3 API calls, got %d", apiCalls)
+	}
+	if validations != 1 {
+		t.Errorf("expected token to be validated once, got %d", validations)
+	}
+}
+
+func TestClient_ScopeValidation_SkippedCases(t *testing.T) {
+	t.Run("app token", func(t *testing.T) {
+		var apiCalls, validations int32
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&apiCalls, 1)
+			_, _ = w.Write([]byte(`{"data":[{}]}`))
+		}, WithScopeValidation(true))
+		defer server.Close()
+		serveTokenValidation(t, client, "", nil, &validations)
+
+		if _, err := client.GetBroadcasterSubscriptions(context.Background(), &GetBroadcasterSubscriptionsParams{BroadcasterID: "1"}); err != nil {
+			t.Errorf("expected app token to skip preflight, got %v", err)
+		}
+	})
+
+	t.Run("endpoint without requirement", func(t *testing.T) {
+		var apiCalls, validations int32
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&apiCalls, 1)
+			_, _ = w.Write([]byte(`{"data":[{}]}`))
+		}, WithScopeValidation(true))
+		defer server.Close()
+		serveTokenValidation(t, client, "123", nil, &validations)
+
+		if _, err := client.GetUsers(context.Background(), nil); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if validations != 0 {
+			t.Errorf("expected no validation, got %d", validations)
+		}
+	})
+
+	t.Run("validation fails", func(t *testing.T) {
+		var apiCalls int32
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&apiCalls, 1)
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		}, WithScopeValidation(true))
+		defer server.Close()
+		validateServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer validateServer.Close()
+		client.authClient.SetEndpoints("", validateServer.URL, "", "", "", "", "")
+
+		if _, err := client.GetPolls(context.Background(), &GetPollsParams{BroadcasterID: "1"}); err != nil {
+			t.Errorf("expected request to go through, got %v", err)
+		}
+		if apiCalls != 1 {
+			t.Errorf("expected 1 API call, got %d", apiCalls)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var apiCalls, validations int32
+		client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&apiCalls, 1)
+			_, _ = w.Write([]byte(`{"data":[{}]}`))
+		}, WithScopeValidation(false))
+		defer server.Close()
+		serveTokenValidation(t, client, "123", nil, &validations)
+
+		if _, err := client.GetPolls(context.Background(), &GetPollsParams{BroadcasterID: "1"}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if validations != 0 {
+			t.Errorf("expected no validation, got %d", validations)
+		}
+	})
+}
+
+func TestClient_ScopeValidation_TokenScopeFallback(t *testing.T) {
+	var apiCalls int32
+	m := NewTokenManager(nil)
+	_ = m.SetUserToken(context.Background(), "1", &Token{AccessToken: "token", Scope: []string{ScopeChannelReadPolls}})
+	m.SetDefaultUser("1")
+
+	provider := struct{ ContextTokenProvider }{m}
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&apiCalls, 1)
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithTokenProvider(provider), WithScopeValidation(true))
+	defer server.Close()
+
+	if _, err := client.GetPolls(context.Background(), &GetPollsParams{BroadcasterID: "1"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, err := client.CreatePoll(context.Background(), &CreatePollParams{BroadcasterID: "1"})
+	var scopeErr *MissingScopeError
+	if !errors.As(err, &scopeErr) {
+		t.Errorf("expected *MissingScopeError from Token.Scope, got %v", err)
+	}
+}
@@ -26,27 +26,28 @@ type Stream struct {
 
 // GetStreamsParams contains parameters for GetStreams.
 type GetStreamsParams struct {
-	UserIDs    []string // Filter by user IDs (max 100)
-	UserLogins []string // Filter by user logins (max 100)
-	GameIDs    []string // Filter by game IDs (max 100)
+	UserIDs    []string // Filter by user IDs
+	UserLogins []string // Filter by user logins
+	GameIDs    []string // Filter by game IDs
 	Type       string   // "all" or "live"
 	Language   []string // Filter by language
 	*PaginationParams
 }
 
 // GetStreams gets active streams.
+// More than 100 user IDs, user logins and game IDs combined are split across
+// concurrent requests whose pages are fetched, with First as the page size, until
+// First streams are collected, or as many streams as there are user IDs, logins
+// and game IDs if First is unset. Streams matched by several requests appear once. The merged
+// response has no cursor, and After or Before return ErrChunkedCursor.
 func (c *Client) GetStreams(ctx context.Context, params *GetStreamsParams) (*Response[Stream], error) {
 	q := url.Values{}
+	var values []queryValue
+	limit := 0
 	if params != nil {
-		for _, id := range params.UserIDs {
-			q.Add("user_id", id)
-		}
-		for _, login := range params.UserLogins {
-			q.Add("user_login", login)
-		}
-		for _, gameID := range params.GameIDs {
-			q.Add("game_id", gameID)
-		}
+		values = append(values, queryValues("user_id", params.UserIDs)...)
+		values = append(values, queryValues("user_login", params.UserLogins)...)
+		values = append(values, queryValues("game_id", params.GameIDs)...)
 		for _, lang := range params.Language {
 			q.Add("language", lang)
 		}
@@ -54,13 +55,14 @@ func (c *Client) GetStreams(ctx context.Context, params *GetStreamsParams) (*Res
 			q.Set("type", params.Type)
 		}
 		addPaginationParams(q, params.PaginationParams)
+		if params.PaginationParams != nil {
+			limit = params.First
+		}
 	}
-
-	var resp Response[Stream]
-	if err := c.get(ctx, "/streams", q, &resp); err != nil {
-		return nil, err
+	if limit <= 0 {
+		limit = len(values)
 	}
-	return &resp, nil
+	return getChunkedPages(ctx, c, "/streams", q, values, maxIDsPerRequest, limit, func(s Stream) string { return s.ID })
 }
 
 // GetFollowedStreamsParams contains parameters for GetFollowedStreams.
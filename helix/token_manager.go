@@ -0,0 +1,283 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownUser is returned when a TokenManager has no token for the requested user.
+var ErrUnknownUser = errors.New("no token for user")
+
+// userContextKey is the context key for the user a request is made on behalf of.
+type userContextKey struct{}
+
+// WithUserContext returns a context that makes a Client backed by a TokenManager
+// send the request with the token of the given Twitch user ID.
+func WithUserContext(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, userID)
+}
+
+// UserFromContext returns the user ID set by WithUserContext, if any.
+func UserFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userContextKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+// TokenManager holds user access tokens for many Twitch users, keyed by user ID.
+// It implements ContextTokenProvider so that a single Client can act on behalf of
+// any managed user by passing a context created with WithUserContext.
+// Each user's token is refreshed independently using the AuthClient's credentials.
+type TokenManager struct {
+	auth *AuthClient
+
+	mu          sync.RWMutex
+	tokens      map[string]*Token
+	refreshing  map[string]*refreshCall
+	defaultUser string
+	store       TokenStore
+}
+
+// NewTokenManager creates a token manager that refreshes tokens with the client ID
+// and secret of auth. The AuthClient's own current token is not modified.
+func NewTokenManager(auth *AuthClient) *TokenManager {
+	return &TokenManager{
+		auth:       auth,
+		tokens:     make(map[string]*Token),
+		refreshing: make(map[string]*refreshCall),
+	}
+}
+
+// SetTokenStore configures persistent storage for managed tokens, keyed by user ID.
+// Tokens added with SetUserToken or obtained by refreshing are saved automatically.
+func (m *TokenManager) SetTokenStore(store TokenStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+}
+
+// SetDefaultUser sets the user whose token is used when the request context has no user.
+func (m *TokenManager) SetDefaultUser(userID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaultUser = userID
+}
+
+// SetUserToken adds or replaces the token for a user and persists it if a store is configured.
+func (m *TokenManager) SetUserToken(ctx context.Context, userID string, token *Token) error {
+	m.mu.Lock()
+	m.tokens[userID] = token
+	store := m.store
+	m.mu.Unlock()
+
+	if store != nil {
+		if err := store.Save(ctx, userID, token); err != nil {
+			return fmt.Errorf("persisting token: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadUser loads a user's token from the configured TokenStore.
+func (m *TokenManager) LoadUser(ctx context.Context, userID string) (*Token, error) {
+	m.mu.RLock()
+	store := m.store
+	m.mu.RUnlock()
+
+	if store == nil {
+		return nil, ErrNoTokenStore
+	}
+
+	token, err := store.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.tokens[userID] = token
+	m.mu.Unlock()
+	return token, nil
+}
+
+// RemoveUser removes a user's token from the manager and from the TokenStore, if configured.
+func (m *TokenManager) RemoveUser(ctx context.Context, userID string) error {
+	m.mu.Lock()
+	delete(m.tokens, userID)
+	store := m.store
+	m.mu.Unlock()
+
+	if store != nil {
+		return store.Delete(ctx, userID)
+	}
+	return nil
+}
+
+// UserToken returns the token for a user, or nil if the user is not managed.
+func (m *TokenManager) UserToken(userID string) *Token {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tokens[userID]
+}
+
+// Users returns the IDs of all managed users, sorted.
+func (m *TokenManager) Users() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]string, 0, len(m.tokens))
+	for userID := range m.tokens {
+		users = append(users, userID)
+	}
+	sort.Strings(users)
+	return users
+}
+
+// GetToken returns the default user's token. It implements TokenProvider.
+func (m *TokenManager) GetToken() *Token {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tokens[m.defaultUser]
+}
+
+// GetTokenForContext returns the token of the user set with WithUserContext, falling
+// back to the default user when the context has none. Returns nil for unknown users
+// rather than acting as someone else. It implements ContextTokenProvider.
+func (m *TokenManager) GetTokenForContext(ctx context.Context) *Token {
+	return m.UserToken(m.userFor(ctx))
+}
+
+// RefreshUserToken refreshes a user's token. Concurrent refreshes for the same user
+// share a single request; different users are refreshed independently.
+func (m *TokenManager) RefreshUserToken(ctx context.Context, userID string) (*Token, error) {
+	m.mu.Lock()
+	token := m.tokens[userID]
+	if token == nil {
+		m.mu.Unlock()
+		return nil, ErrUnknownUser
+	}
+	return m.refreshLocked(ctx, userID, token.AccessToken)
+}
+
+// AutoRefresh starts a goroutine that refreshes every managed token that has a refresh
+// token and expires within 5 minutes. Tokens are checked once a minute, and a failed
+// refresh for one user does not affect the others.
+//
+// IMPORTANT: The caller MUST call the returned cancel function to stop the goroutine
+// when it's no longer needed, or ensure the parent context is eventually cancelled.
+func (m *TokenManager) AutoRefresh(ctx context.Context) (cancel func()) {
+	ctx, cancelFunc := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			m.refreshExpiring(ctx, 5*time.Minute)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return cancelFunc
+}
+
+// refreshExpiring refreshes tokens that expire within the given window.
+func (m *TokenManager) refreshExpiring(ctx context.Context, within time.Duration) {
+	deadline := time.Now().Add(within)
+
+	m.mu.RLock()
+	var due []string
+	for userID, token := range m.tokens {
+		if token.RefreshToken != "" && !token.ExpiresAt.IsZero() && token.ExpiresAt.Before(deadline) {
+			due = append(due, userID)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, userID := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		_, _ = m.RefreshUserToken(ctx, userID)
+	}
+}
+
+// refreshExpiredToken refreshes the token of the user in ctx after a request made with
+// staleAccessToken was rejected. It is used by Client to refresh on 401.
+func (m *TokenManager) refreshExpiredToken(ctx context.Context, staleAccessToken string) (*Token, error) {
+	userID := m.userFor(ctx)
+
+	m.mu.Lock()
+	token := m.tokens[userID]
+	if token == nil {
+		m.mu.Unlock()
+		return nil, ErrUnknownUser
+	}
+	if token.AccessToken != staleAccessToken {
+		// Already refreshed by another request
+		m.mu.Unlock()
+		return token, nil
+	}
+	return m.refreshLocked(ctx, userID, staleAccessToken)
+}
+
+// refreshLocked refreshes userID's token, joining an in-flight refresh if there is one.
+// Must be called with m.mu held; the lock is released before returning.
+func (m *TokenManager) refreshLocked(ctx context.Context, userID, staleAccessToken string) (*Token, error) {
+	call := m.refreshing[userID]
+	if call == nil {
+		call = &refreshCall{done: make(chan struct{})}
+		m.refreshing[userID] = call
+		refreshToken := m.tokens[userID].RefreshToken
+		m.mu.Unlock()
+
+		// Detach from the caller's cancellation so other waiters are not failed by it
+		call.token, call.err = m.auth.exchangeRefreshToken(context.WithoutCancel(ctx), refreshToken)
+
+		m.mu.Lock()
+		delete(m.refreshing, userID)
+		// Only replace the token if it was not changed or removed while refreshing
+		replaced := false
+		if call.err == nil {
+			if current := m.tokens[userID]; current != nil && current.AccessToken == staleAccessToken {
+				m.tokens[userID] = call.token
+				replaced = true
+			}
+		}
+		store := m.store
+		m.mu.Unlock()
+
+		if replaced && store != nil {
+			if err := store.Save(context.WithoutCancel(ctx), userID, call.token); err != nil {
+				call.err = fmt.Errorf("%w: %w", ErrTokenNotPersisted, err)
+			}
+		}
+		close(call.done)
+	} else {
+		m.mu.Unlock()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-call.done:
+		return call.token, call.err
+	}
+}
+
+// userFor returns the user a request with ctx is made on behalf of.
+func (m *TokenManager) userFor(ctx context.Context) string {
+	if userID, ok := UserFromContext(ctx); ok {
+		return userID
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.defaultUser
+}
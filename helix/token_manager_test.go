@@ -0,0 +1,226 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTokenManagerTestServer returns a token endpoint that issues "<refresh>-new" access tokens.
+func newTokenManagerTestServer(refreshes *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(refreshes, 1)
+		_ = r.ParseForm()
+		refresh := r.PostForm.Get("refresh_token")
+		time.Sleep(10 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(Token{
+			AccessToken:  refresh + "-new",
+			RefreshToken: refresh,
+			ExpiresIn:    3600,
+		})
+	}))
+}
+
+func newTestTokenManager(tokenURL string) (*TokenManager, *AuthClient) {
+	auth := NewAuthClient(AuthConfig{ClientID: "test-client-id", ClientSecret: "secret"})
+	auth.SetEndpoints(tokenURL, "", "", "", "", "", "")
+	auth.SetToken(&Token{AccessToken: "app-token"})
+	return NewTokenManager(auth), auth
+}
+
+func TestUserContext(t *testing.T) {
+	if _, ok := UserFromContext(context.Background()); ok {
+		t.Error("expected no user in background context")
+	}
+	userID, ok := UserFromContext(WithUserContext(context.Background(), "123"))
+	if !ok || userID != "123" {
+		t.Errorf("expected user 123, got %q", userID)
+	}
+}
+
+func TestTokenManager_TokenSelection(t *testing.T) {
+	m, _ := newTestTokenManager("")
+	ctx := context.Background()
+	_ = m.SetUserToken(ctx, "1", &Token{AccessToken: "token-1"})
+	_ = m.SetUserToken(ctx, "2", &Token{AccessToken: "token-2"})
+
+	if m.GetToken() != nil {
+		t.Error("expected nil token without default user")
+	}
+	m.SetDefaultUser("1")
+	if m.GetToken().AccessToken != "token-1" {
+		t.Errorf("expected default user token, got %v", m.GetToken())
+	}
+	if tok := m.GetTokenForContext(WithUserContext(ctx, "2")); tok == nil || tok.AccessToken != "token-2" {
+		t.Errorf("expected token-2, got %v", tok)
+	}
+	if tok := m.GetTokenForContext(ctx); tok == nil || tok.AccessToken != "token-1" {
+		t.Errorf("expected default token-1, got %v", tok)
+	}
+	if tok := m.GetTokenForContext(WithUserContext(ctx, "unknown")); tok != nil {
+		t.Errorf("expected nil token for unknown user, got %v", tok)
+	}
+
+	users := m.Users()
+	if len(users) != 2 || users[0] != "1" || users[1] != "2" {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	_ = m.RemoveUser(ctx, "2")
+	if m.UserToken("2") != nil {
+		t.Error("expected user 2 to be removed")
+	}
+}
+
+func TestTokenManager_ClientUsesContextUser(t *testing.T) {
+	var gotAuth []string
+	var mu sync.Mutex
+	m, _ := newTestTokenManager("")
+	_ = m.SetUserToken(context.Background(), "1", &Token{AccessToken: "token-1"})
+	_ = m.SetUserToken(context.Background(), "2", &Token{AccessToken: "token-2"})
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithTokenProvider(m))
+	defer server.Close()
+
+	_, _ = client.GetUsers(WithUserContext(context.Background(), "1"), nil)
+	_, _ = client.GetUsers(WithUserContext(context.Background(), "2"), nil)
+
+	if len(gotAuth) != 2 || gotAuth[0] != "Bearer token-1" || gotAuth[1] != "Bearer token-2" {
+		t.Errorf("unexpected authorization headers: %v", gotAuth)
+	}
+}
+
+func TestTokenManager_RefreshUserToken(t *testing.T) {
+	var refreshes int32
+	server := newTokenManagerTestServer(&refreshes)
+	defer server.Close()
+
+	m, auth := newTestTokenManager(server.URL)
+	ctx := context.Background()
+	_ = m.SetUserToken(ctx, "1", &Token{AccessToken: "old-1", RefreshToken: "r1"})
+	_ = m.SetUserToken(ctx, "2", &Token{AccessToken: "old-2", RefreshToken: "r2"})
+
+	token, err := m.RefreshUserToken(ctx, "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.AccessToken != "r1-new" || m.UserToken("1").AccessToken != "r1-new" {
+		t.Errorf("expected user 1 token to be refreshed, got %s", token.AccessToken)
+	}
+	if m.UserToken("2").AccessToken != "old-2" {
+		t.Error("expected user 2 token to be untouched")
+	}
+	if auth.GetToken().AccessToken != "app-token" {
+		t.Errorf("expected AuthClient token to be untouched, got %s", auth.GetToken().AccessToken)
+	}
+
+	if _, err := m.RefreshUserToken(ctx, "missing"); !errors.Is(err, ErrUnknownUser) {
+		t.Errorf("expected ErrUnknownUser, got %v", err)
+	}
+}
+
+func TestTokenManager_RefreshOn401PerUser(t *testing.T) {
+	var refreshes int32
+	tokenServer := newTokenManagerTestServer(&refreshes)
+	defer tokenServer.Close()
+
+	m, _ := newTestTokenManager(tokenServer.URL)
+	_ = m.SetUserToken(context.Background(), "1", &Token{AccessToken: "old-1", RefreshToken: "r1"})
+	_ = m.SetUserToken(context.Background(), "2", &Token{AccessToken: "old-2", RefreshToken: "r2"})
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.Header.Get("Authorization"), "-new") {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"Unauthorized","status":401,"message":"Invalid OAuth token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}, WithTokenProvider(m))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		userID := "1"
+		if i%2 == 1 {
+			userID = "2"
+		}
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			if _, err := client.GetUsers(WithUserContext(context.Background(), userID), nil); err != nil {
+				t.Errorf("unexpected error for user %s: %v", userID, err)
+			}
+		}(userID)
+	}
+	wg.Wait()
+
+	if refreshes != 2 {
+		t.Errorf("expected one refresh per user, got %d", refreshes)
+	}
+	if m.UserToken("1").AccessToken != "r1-new" || m.UserToken("2").AccessToken != "r2-new" {
+		t.Error("expected both users to be refreshed")
+	}
+}
+
+func TestTokenManager_TokenStore(t *testing.T) {
+	var refreshes int32
+	server := newTokenManagerTestServer(&refreshes)
+	defer server.Close()
+
+	store, _ := NewFileTokenStore(t.TempDir(), testStoreKey)
+	ctx := context.Background()
+
+	m, _ := newTestTokenManager(server.URL)
+	m.SetTokenStore(store)
+	_ = m.SetUserToken(ctx, "1", &Token{AccessToken: "old-1", RefreshToken: "r1"})
+	_, _ = m.RefreshUserToken(ctx, "1")
+
+	restarted, _ := newTestTokenManager(server.URL)
+	restarted.SetTokenStore(store)
+	token, err := restarted.LoadUser(ctx, "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.AccessToken != "r1-new" {
+		t.Errorf("expected refreshed token to be persisted, got %s", token.AccessToken)
+	}
+
+	_ = restarted.RemoveUser(ctx, "1")
+	if _, err := store.Load(ctx, "1"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected token removed from store, got %v", err)
+	}
+}
+
+func TestTokenManager_RefreshExpiring(t *testing.T) {
+	var refreshes int32
+	server := newTokenManagerTestServer(&refreshes)
+	defer server.Close()
+
+	m, _ := newTestTokenManager(server.URL)
+	ctx := context.Background()
+	_ = m.SetUserToken(ctx, "soon", &Token{AccessToken: "a", RefreshToken: "soon", ExpiresAt: time.Now().Add(time.Minute)})
+	_ = m.SetUserToken(ctx, "later", &Token{AccessToken: "b", RefreshToken: "later", ExpiresAt: time.Now().Add(time.Hour)})
+	_ = m.SetUserToken(ctx, "norefresh", &Token{AccessToken: "c", ExpiresAt: time.Now().Add(time.Minute)})
+
+	m.refreshExpiring(ctx, 5*time.Minute)
+
+	if refreshes != 1 {
+		t.Errorf("expected 1 refresh, got %d", refreshes)
+	}
+	if m.UserToken("soon").AccessToken != "soon-new" {
+		t.Error("expected expiring token to be refreshed")
+	}
+	if m.UserToken("later").AccessToken != "b" {
+		t.Error("expected non-expiring token to be untouched")
+	}
+}
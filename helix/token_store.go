@@ -0,0 +1,159 @@
+package helix
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrTokenNotFound is returned by a TokenStore when no token is stored for a key.
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenStore persists tokens so they survive process restarts.
+// Keys are typically a Twitch user ID for user tokens or the client ID for app tokens.
+type TokenStore interface {
+	// Load returns the token stored for key, or ErrTokenNotFound.
+	Load(ctx context.Context, key string) (*Token, error)
+	// Save stores the token for key, replacing any existing token.
+	Save(ctx context.Context, key string, token *Token) error
+	// Delete removes the token for key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// storedToken is the serialized form of a Token, including its absolute expiry
+// (Token.ExpiresAt is not part of the Twitch JSON representation).
+type storedToken struct {
+	Token     *Token    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// marshalToken serializes a token for storage.
+func marshalToken(token *Token) ([]byte, error) {
+	return json.Marshal(storedToken{Token: token, ExpiresAt: token.ExpiresAt})
+}
+
+// unmarshalToken deserializes a token produced by marshalToken.
+func unmarshalToken(data []byte) (*Token, error) {
+	var stored storedToken
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	if stored.Token == nil {
+		return nil, errors.New("missing token")
+	}
+	stored.Token.ExpiresAt = stored.ExpiresAt
+	return stored.Token, nil
+}
+
+// FileTokenStore is a TokenStore that keeps each token in its own file,
+// encrypted at rest with AES-GCM using a caller-provided key.
+type FileTokenStore struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+// NewFileTokenStore creates a file-backed token store in dir.
+// The encryption key must be 16, 24 or 32 bytes (AES-128, AES-192 or AES-256).
+// The directory is created with 0700 permissions if it does not exist.
+func NewFileTokenStore(dir string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating token directory: %w", err)
+	}
+	return &FileTokenStore{dir: dir, aead: aead}, nil
+}
+
+// Load reads and decrypts the token stored for key.
+func (s *FileTokenStore) Load(ctx context.Context, key string) (*Token, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading token file: %w", err)
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("decrypting token: file too short")
+	}
+	// The key is used as additional data so a file cannot be swapped for another key's file
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("decrypting token: %w", err)
+	}
+
+	token, err := unmarshalToken(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("parsing token: %w", err)
+	}
+	return token, nil
+}
+
+// Save encrypts and writes the token for key. The file is replaced atomically.
+func (s *FileTokenStore) Save(ctx context.Context, key string, token *Token) error {
+	if token == nil {
+		return s.Delete(ctx, key)
+	}
+
+	plaintext, err := marshalToken(token)
+	if err != nil {
+		return fmt.Errorf("marshaling token: %w", err)
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+	data := s.aead.Seal(nonce, nonce, plaintext, []byte(key))
+
+	tmp, err := os.CreateTemp(s.dir, ".token-*")
+	if err != nil {
+		return fmt.Errorf("creating token file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing token file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing token file: %w", err)
+	}
+	if err := os.Rename(tmpName, s.path(key)); err != nil {
+		return fmt.Errorf("writing token file: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the token file for key.
+func (s *FileTokenStore) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("deleting token file: %w", err)
+	}
+	return nil
+}
+
+// path returns the file path for key. Keys are hashed so arbitrary IDs are safe file names.
+func (s *FileTokenStore) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(hash[:])+".token")
+}
@@ -0,0 +1,269 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testStoreKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestNewFileTokenStore_InvalidKey(t *testing.T) {
+	_, err := NewFileTokenStore(t.TempDir(), []byte("short"))
+	if err == nil {
+		t.Fatal("expected error for invalid key length")
+	}
+}
+
+func TestFileTokenStore_RoundTrip(t *testing.T) {
+	store, err := NewFileTokenStore(t.TempDir(), testStoreKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	token := &Token{
+		AccessToken:  "access-123",
+		RefreshToken: "refresh-456",
+		TokenType:    "bearer",
+		Scope:        []string{"chat:read"},
+		ExpiresAt:    expiresAt,
+	}
+	if err := store.Save(ctx, "user-1", token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := store.Load(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.AccessToken != "access-123" || loaded.RefreshToken != "refresh-456" {
+		t.Errorf("unexpected token: %+v", loaded)
+	}
+	if !loaded.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("expected ExpiresAt %v, got %v", expiresAt, loaded.ExpiresAt)
+	}
+	if len(loaded.Scope) != 1 || loaded.Scope[0] != "chat:read" {
+		t.Errorf("unexpected scopes: %v", loaded.Scope)
+	}
+}
+
+func TestFileTokenStore_EncryptedAtRest(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewFileTokenStore(dir, testStoreKey)
+	_ = store.Save(context.Background(), "user-1", &Token{AccessToken: "secret-access", RefreshToken: "secret-refresh"})
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(entries))
+	}
+
+	path := filepath.Join(dir, entries[0].Name())
+	data, _ := os.ReadFile(path)
+	if bytes.Contains(data, []byte("secret-access")) || bytes.Contains(data, []byte("secret-refresh")) {
+		t.Error("token stored in plaintext")
+	}
+
+	info, _ := os.Stat(path)
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected file permissions 0600, got %o", perm)
+	}
+}
+
+func TestFileTokenStore_WrongKey(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewFileTokenStore(dir, testStoreKey)
+	_ = store.Save(context.Background(), "user-1", &Token{AccessToken: "access"})
+
+	other, _ := NewFileTokenStore(dir, []byte("fedcba9876543210fedcba9876543210"))
+	if _, err := other.Load(context.Background(), "user-1"); err == nil {
+		t.Error("expected decryption error with wrong key")
+	}
+}
+
+func TestFileTokenStore_NotFoundAndDelete(t *testing.T) {
+	store, _ := NewFileTokenStore(t.TempDir(), testStoreKey)
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "missing"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound, got %v", err)
+	}
+	if err := store.Delete(ctx, "missing"); err != nil {
+		t.Errorf("expected deleting missing key to succeed, got %v", err)
+	}
+
+	_ = store.Save(ctx, "user-1", &Token{AccessToken: "access"})
+	if err := store.Delete(ctx, "user-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Load(ctx, "user-1"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound after delete, got %v", err)
+	}
+}
+
+func TestAuthClient_TokenStore_PersistsRefresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Token{
+			AccessToken:  "refreshed-access",
+			RefreshToken: "refreshed-refresh",
+			ExpiresIn:    3600,
+		})
+	}))
+	defer server.Close()
+
+	store, _ := NewFileTokenStore(t.TempDir(), testStoreKey)
+	ctx := context.Background()
+
+	auth := NewAuthClient(AuthConfig{ClientID: "client-id", ClientSecret: "secret"})
+	auth.SetEndpoints(server.URL, "", "", "", "", "", "")
+	auth.SetTokenStore(store, "user-1")
+	auth.SetToken(&Token{AccessToken: "old", RefreshToken: "old-refresh"})
+
+	if _, err := auth.RefreshCurrentToken(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A fresh client (e.g. after a restart) picks up the refreshed token
+	restarted := NewAuthClient(AuthConfig{ClientID: "client-id", ClientSecret: "secret"})
+	restarted.SetTokenStore(store, "user-1")
+	token, err := restarted.LoadToken(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.AccessToken != "refreshed-access" {
+		t.Errorf("expected refreshed-access, got %s", token.AccessToken)
+	}
+	if token.ExpiresAt.IsZero() {
+		t.Error("expected ExpiresAt to be persisted")
+	}
+	if restarted.GetToken() != token {
+		t.Error("expected loaded token to become current token")
+	}
+}
+
+func TestAuthClient_TokenStore_PersistsOIDCExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(OIDCToken{
+			Token:   Token{AccessToken: "oidc-access", RefreshToken: "oidc-refresh", ExpiresIn: 3600},
+			IDToken: "id-token",
+		})
+	}))
+	defer server.Close()
+
+	store, _ := NewFileTokenStore(t.TempDir(), testStoreKey)
+	ctx := context.Background()
+
+	auth := NewAuthClient(AuthConfig{ClientID: "client-id", ClientSecret: "secret"})
+	auth.SetEndpoints(server.URL, "", "", "", "", "", "")
+	auth.SetTokenStore(store, "user-1")
+
+	if _, err := auth.ExchangeCodeForOIDCToken(ctx, "code"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token, err := store.Load(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("expected exchanged token to be persisted: %v", err)
+	}
+	if token.AccessToken != "oidc-access" || token.RefreshToken != "oidc-refresh" {
+		t.Errorf("unexpected stored token %+v", token)
+	}
+}
+
+// failingTokenStore is a TokenStore whose Save always fails.
+type failingTokenStore struct{}
+
+func (failingTokenStore) Load(context.Context, string) (*Token, error) { return nil, ErrTokenNotFound }
+func (failingTokenStore) Save(context.Context, string, *Token) error   { return errors.New("disk full") }
+func (failingTokenStore) Delete(context.Context, string) error         { return nil }
+
+func TestAuthClient_TokenStore_SaveFailsDuringReplay(t *testing.T) {
+	var refreshes int32
+	client, cleanup := newRefreshTestClient(t, &refreshes)
+	defer cleanup()
+	client.authClient.SetTokenStore(failingTokenStore{}, "user-1")
+
+	// The refreshed token is used for the replay even though it was not saved
+	resp, err := client.GetUsers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Data) != 1 || refreshes != 1 {
+		t.Errorf("expected one refresh and a replayed request, got %d refreshes and %+v", refreshes, resp.Data)
+	}
+
+	client.authClient.SetToken(&Token{AccessToken: "old-token", RefreshToken: "old-refresh"})
+	token, err := client.authClient.RefreshCurrentToken(context.Background())
+	if !errors.Is(err, ErrTokenNotPersisted) {
+		t.Errorf("expected ErrTokenNotPersisted, got %v", err)
+	}
+	if token == nil || token.RefreshToken != "new-refresh" {
+		t.Errorf("expected the new token to be returned, got %+v", token)
+	}
+	if current := client.authClient.GetToken(); current != token {
+		t.Errorf("expected the new token to be current, got %+v", current)
+	}
+}
+
+func TestAuthClient_TokenStore_DefaultKeyAndSave(t *testing.T) {
+	store, _ := NewFileTokenStore(t.TempDir(), testStoreKey)
+	ctx := context.Background()
+
+	auth := NewAuthClient(AuthConfig{ClientID: "client-id"})
+	auth.SetTokenStore(store, "")
+	auth.SetToken(&Token{AccessToken: "manual"})
+
+	if err := auth.SaveToken(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	token, err := store.Load(ctx, "client-id")
+	if err != nil {
+		t.Fatalf("expected token saved under client ID: %v", err)
+	}
+	if token.AccessToken != "manual" {
+		t.Errorf("expected manual, got %s", token.AccessToken)
+	}
+}
+
+func TestAuthClient_TokenStore_RevokeDeletes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store, _ := NewFileTokenStore(t.TempDir(), testStoreKey)
+	ctx := context.Background()
+
+	auth := NewAuthClient(AuthConfig{ClientID: "client-id"})
+	auth.SetEndpoints("", "", server.URL, "", "", "", "")
+	auth.SetTokenStore(store, "user-1")
+	auth.SetToken(&Token{AccessToken: "access"})
+	_ = auth.SaveToken(ctx)
+
+	if err := auth.RevokeCurrentToken(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Load(ctx, "user-1"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected stored token to be deleted, got %v", err)
+	}
+}
+
+func TestAuthClient_NoTokenStore(t *testing.T) {
+	auth := NewAuthClient(AuthConfig{ClientID: "client-id"})
+	if _, err := auth.LoadToken(context.Background()); !errors.Is(err, ErrNoTokenStore) {
+		t.Errorf("expected ErrNoTokenStore, got %v", err)
+	}
+	if err := auth.SaveToken(context.Background()); !errors.Is(err, ErrNoTokenStore) {
+		t.Errorf("expected ErrNoTokenStore, got %v", err)
+	}
+}
@@ -24,28 +24,19 @@ type User struct {
 
 // GetUsersParams contains parameters for GetUsers.
 type GetUsersParams struct {
-	IDs    []string // User IDs (max 100)
-	Logins []string // User login names (max 100)
+	IDs    []string // User IDs
+	Logins []string // User login names
 }
 
 // GetUsers gets information about one or more Twitch users.
+// More than 100 IDs and logins combined are split across concurrent requests.
 // Requires: No scope for public data, user:read:email for email.
 func (c *Client) GetUsers(ctx context.Context, params *GetUsersParams) (*Response[User], error) {
-	q := url.Values{}
+	var values []queryValue
 	if params != nil {
-		for _, id := range params.IDs {
-			q.Add("id", id)
-		}
-		for _, login := range params.Logins {
-			q.Add("login", login)
-		}
-	}
-
-	var resp Response[User]
-	if err := c.get(ctx, "/users", q, &resp); err != nil {
-		return nil, err
+		values = append(queryValues("id", params.IDs), queryValues("login", params.Logins)...)
 	}
-	return &resp, nil
+	return getChunked[User](ctx, c, "/users", url.Values{}, values, maxIDsPerRequest)
 }
 
 // GetCurrentUser gets information about the authenticated user.
@@ -2,6 +2,7 @@ package helix
 
 import (
 	"context"
+	"net/http"
 	"net/url"
 	"time"
 )
@@ -82,17 +83,12 @@ func (c *Client) GetVideos(ctx context.Context, params *GetVideosParams) (*Respo
 type DeleteVideosResponse []string
 
 // DeleteVideos deletes one or more videos.
+// More than 5 video IDs are split across concurrent requests. If one of them fails,
+// videos deleted by the others stay deleted.
 // Requires: channel:manage:videos scope.
 func (c *Client) DeleteVideos(ctx context.Context, videoIDs []string) ([]string, error) {
-	q := url.Values{}
-	for _, id := range videoIDs {
-		q.Add("id", id)
-	}
-
-	var resp struct {
-		Data []string `json:"data"`
-	}
-	if err := c.delete(ctx, "/videos", q, &resp); err != nil {
+	resp, err := doChunked[string](ctx, c, http.MethodDelete, "/videos", url.Values{}, queryValues("id", videoIDs), maxDeleteVideoIDs)
+	if err != nil {
 		return nil, err
 	}
 	return resp.Data, nil